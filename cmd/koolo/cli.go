@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/server/api"
+	"github.com/hectorgimenez/koolo/internal/utils/winproc"
+)
+
+// command is a subcommand run from the command line instead of opening the UI, ex: koolo config validate. The
+// arguments after the command name are passed to run.
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(args []string) int
+}
+
+var commands = []command{
+	{name: "config validate", description: "Check the configuration files and list the invalid fields", run: validateConfig},
+	{name: "start", usage: "<supervisor>...", description: "Start the supervisors in the running Koolo instance, waiting for their games to launch", run: startSupervisors},
+	{name: "stop", usage: "<supervisor>...", description: "Stop the supervisors in the running Koolo instance", run: stopSupervisors},
+	{name: "status", description: "Show the state of every supervisor and its next scheduled start or stop", run: showStatus},
+	{name: "stats", description: "Show the games, drops, deaths, chickens and errors of every supervisor", run: showStats},
+}
+
+// apiURL is the address of the running instance used by the commands talking to it, set with -url
+var apiURL string
+
+// runCommand runs the subcommand matching the arguments and returns the exit code
+func runCommand(args []string) int {
+	for _, c := range commands {
+		name := strings.Fields(c.name)
+		if len(args) >= len(name) && slices.Equal(args[:len(name)], name) {
+			return c.run(args[len(name):])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", strings.Join(args, " "))
+	usage()
+
+	return 2
+}
+
+// usage prints the flags and the commands, it's used as flag.Usage
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: koolo [flags] [command]\n\nWithout a command koolo opens the UI, or runs in the background with -headless.\n\nFlags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  koolo %-28s %s\n", strings.TrimSpace(c.name+" "+c.usage), c.description)
+	}
+}
+
+func validateConfig(args []string) int {
+	if len(args) > 0 {
+		return usageError("koolo config validate doesn't take arguments")
+	}
+	if err := config.Load(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := config.CharacterErrors(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	fmt.Printf("Configuration is valid, %d characters checked\n", len(config.Characters()))
+
+	return 0
+}
+
+// attachConsole sends the output to the console koolo was launched from, it's built as a GUI app without its own
+func attachConsole() {
+	if r, _, _ := winproc.AttachConsole.Call(winproc.ATTACH_PARENT_PROCESS); r == 0 {
+		return
+	}
+
+	if out, err := os.OpenFile("CONOUT$", os.O_WRONLY, 0); err == nil {
+		os.Stdout = out
+		os.Stderr = out
+	}
+}
+
+func startSupervisors(args []string) int {
+	return forEachSupervisor(args, "Started", api.NewClient(apiURL).Start)
+}
+
+func stopSupervisors(args []string) int {
+	return forEachSupervisor(args, "Stopped", api.NewClient(apiURL).Stop)
+}
+
+// forEachSupervisor calls fn for every supervisor, all of them are tried even if some fail and the exit code is 1
+func forEachSupervisor(supervisors []string, action string, fn func(supervisor string) error) int {
+	if len(supervisors) == 0 {
+		return usageError("at least one supervisor is required")
+	}
+
+	code := 0
+	for _, supervisor := range supervisors {
+		if err := fn(supervisor); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", supervisor, err)
+			code = 1
+			continue
+		}
+		fmt.Printf("%s %s\n", action, supervisor)
+	}
+
+	return code
+}
+
+func showStatus(args []string) int {
+	if len(args) > 0 {
+		return usageError("koolo status doesn't take arguments")
+	}
+
+	status, err := api.NewClient(apiURL).Status()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Koolo %s\n\n", status.Version)
+
+	return exitCode(api.WriteStatus(os.Stdout, status))
+}
+
+func showStats(args []string) int {
+	if len(args) > 0 {
+		return usageError("koolo stats doesn't take arguments")
+	}
+
+	stats, err := api.NewClient(apiURL).Stats()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return exitCode(api.WriteStats(os.Stdout, stats, time.Now()))
+}
+
+func usageError(msg string) int {
+	fmt.Fprintf(os.Stderr, "%s, run koolo -h to list the commands\n", msg)
+
+	return 2
+}
+
+func exitCode(err error) int {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
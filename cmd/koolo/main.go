@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"log/slog"
+	"maps"
 	_ "net/http/pprof"
+	"os"
+	"os/signal"
 	"runtime/debug"
+	"slices"
+	"syscall"
 
 	sloggger "github.com/hectorgimenez/koolo/cmd/koolo/log"
 	"github.com/hectorgimenez/koolo/internal/bot"
@@ -15,6 +21,7 @@ import (
 	"github.com/hectorgimenez/koolo/internal/remote/discord"
 	"github.com/hectorgimenez/koolo/internal/remote/telegram"
 	"github.com/hectorgimenez/koolo/internal/server"
+	"github.com/hectorgimenez/koolo/internal/server/api"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
 	"github.com/inkeliz/gowebview"
@@ -46,12 +53,30 @@ func main() {
 	_ = buildID
 	_ = buildTime
 
+	headlessFlag := flag.Bool("headless", false, "Run without the window, only the HTTP server, the scheduler and the remote bots. Stop it with Ctrl+C")
+	flag.StringVar(&apiURL, "url", api.DefaultURL, "Address of the running Koolo instance used by the commands")
+	flag.Usage = usage
+	if len(os.Args) > 1 {
+		attachConsole()
+	}
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(flag.Args()))
+	}
+
 	err := config.Load()
 	if err != nil {
-		utils.ShowDialog("Error loading configuration", err.Error())
+		if !*headlessFlag {
+			utils.ShowDialog("Error loading configuration", err.Error())
+		}
 		log.Fatalf("Error loading configuration: %s", err.Error())
 		return
 	}
+	headless := *headlessFlag || config.Koolo.Headless
+	if headless && len(os.Args) == 1 {
+		attachConsole()
+	}
 
 	logger, err := sloggger.NewLogger(config.Koolo.Debug.Log, config.Koolo.LogSaveDirectory, "")
 	if err != nil {
@@ -59,17 +84,31 @@ func main() {
 	}
 	defer sloggger.FlushAndClose()
 
+	// Invalid characters can't be started, they are listed in the dashboard to be fixed from the UI
+	invalid := config.InvalidCharacters()
+	for _, name := range slices.Sorted(maps.Keys(invalid)) {
+		logger.Warn("Character config can't be loaded", slog.String("supervisor", name), slog.Any("error", invalid[name].Err))
+	}
+
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("fatal error detected, Koolo will close with the following error: %v\n Stacktrace: %s", r, debug.Stack())
 			logger.Error(err.Error())
 			sloggger.FlushAndClose()
+			if headless {
+				return
+			}
 			utils.ShowDialog("Koolo error :(", fmt.Sprintf("Koolo will close due to an expected error, please check the latest log file for more info!\n %s", err.Error()))
 		}
 	}()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	if headless {
+		// Without a window to close Koolo is stopped from the console or by the service manager
+		ctx, cancel = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+	}
 
 	g, ctx := errgroup.WithContext(ctx)
 
@@ -79,41 +118,49 @@ func main() {
 	manager := bot.NewSupervisorManager(logger, eventListener)
 	scheduler := bot.NewScheduler(manager, logger)
 	go scheduler.Start()
-	srv, err := server.New(logger, manager)
+	srv, err := server.New(logger, manager, scheduler)
 	if err != nil {
 		log.Fatalf("Error starting local server: %s", err.Error())
 	}
 
 	// Use wrapWithRecover for all goroutines to handle panics
-	g.Go(wrapWithRecover(logger, func() error {
-		defer cancel()
-		displayScale := config.GetCurrentDisplayScale()
-		w, err := gowebview.New(&gowebview.Config{URL: "http://localhost:8087", WindowConfig: &gowebview.WindowConfig{
-			Title: "Koolo",
-			Size: &gowebview.Point{
+	if headless {
+		logger.Info("Koolo running in headless mode, the UI is available at " + api.DefaultURL)
+	} else {
+		g.Go(wrapWithRecover(logger, func() error {
+			defer cancel()
+			displayScale := config.GetCurrentDisplayScale()
+			w, err := gowebview.New(&gowebview.Config{URL: api.DefaultURL, WindowConfig: &gowebview.WindowConfig{
+				Title: "Koolo",
+				Size: &gowebview.Point{
+					X: int64(1280 * displayScale),
+					Y: int64(720 * displayScale),
+				},
+			}})
+			if err != nil {
+				w.Destroy()
+				return fmt.Errorf("error creating webview: %w", err)
+			}
+
+			w.SetSize(&gowebview.Point{
 				X: int64(1280 * displayScale),
 				Y: int64(720 * displayScale),
-			},
-		}})
-		if err != nil {
-			w.Destroy()
-			return fmt.Errorf("error creating webview: %w", err)
-		}
+			}, gowebview.HintFixed)
 
-		w.SetSize(&gowebview.Point{
-			X: int64(1280 * displayScale),
-			Y: int64(720 * displayScale),
-		}, gowebview.HintFixed)
+			defer w.Destroy()
+			w.Run()
 
-		defer w.Destroy()
-		w.Run()
-
-		return nil
-	}))
+			return nil
+		}))
+	}
 
 	// Discord Bot initialization
 	if config.Koolo.Discord.Enabled {
-		discordBot, err := discord.NewBot(config.Koolo.Discord.Token, config.Koolo.Discord.ChannelID, manager)
+		var discordBot *discord.Bot
+		token, err := config.ResolveSecret(config.Koolo.Discord.Token)
+		if err == nil {
+			discordBot, err = discord.NewBot(token, config.Koolo.Discord.ChannelID, manager)
+		}
 		if err != nil {
 			logger.Error("Discord could not been initialized", slog.Any("error", err))
 			return
@@ -127,7 +174,11 @@ func main() {
 
 	// Telegram Bot initialization
 	if config.Koolo.Telegram.Enabled {
-		telegramBot, err := telegram.NewBot(config.Koolo.Telegram.Token, config.Koolo.Telegram.ChatID, logger)
+		var telegramBot *telegram.Bot
+		token, err := config.ResolveSecret(config.Koolo.Telegram.Token)
+		if err == nil {
+			telegramBot, err = telegram.NewBot(token, config.Koolo.Telegram.ChatID, logger)
+		}
 		if err != nil {
 			logger.Error("Telegram could not been initialized", slog.Any("error", err))
 			return
@@ -0,0 +1,77 @@
+package action
+
+import (
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/belt"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/ui"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+// RefillBeltFromOverflow moves the potions stored in locked inventory slots to the belt
+func RefillBeltFromOverflow() error {
+	ctx := context.Get()
+	ctx.SetLastAction("RefillBeltFromOverflow")
+
+	potions := ctx.BeltManager.Plan().BeltRefill()
+	if len(potions) == 0 {
+		return nil
+	}
+
+	ctx.Logger.Debug("Refilling belt from inventory potions", slog.Int("potions", len(potions)))
+
+	step.CloseAllMenus()
+	for !ctx.Data.OpenMenus.Inventory {
+		ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.Inventory)
+		utils.Sleep(500)
+	}
+
+	// Shift + click moves the potion to the first free belt slot
+	for _, p := range potions {
+		screenPos := ui.GetScreenCoordsForItem(p)
+		ctx.HID.ClickWithModifier(game.LeftButton, screenPos.X, screenPos.Y, game.ShiftKey)
+		utils.Sleep(300)
+	}
+
+	return step.CloseAllMenus()
+}
+
+// storeOverflowPotions moves the potions bought when the belt is full to the free locked inventory slots, so they
+// are not sold or consumed. Inventory should be already visible, usually from the vendor window.
+func storeOverflowPotions() {
+	ctx := context.Get()
+	ctx.SetLastStep("storeOverflowPotions")
+
+	ctx.RefreshGameData()
+	plan := ctx.BeltManager.Plan()
+	missing := make(map[data.PotionType]int)
+	for _, pt := range []data.PotionType{data.HealingPotion, data.ManaPotion, data.RejuvenationPotion} {
+		missing[pt] = plan.OverflowMissing(pt)
+	}
+
+	slots := plan.FreeOverflowSlots
+	for _, p := range plan.Misplaced {
+		pt := belt.PotionType(p.Name)
+		if missing[pt] == 0 || len(slots) == 0 {
+			continue
+		}
+
+		from := ui.GetScreenCoordsForItem(p)
+		to := ui.GetScreenCoordsForItem(data.Item{
+			Location: item.Location{LocationType: item.LocationInventory},
+			Position: slots[0],
+		})
+		ctx.HID.Click(game.LeftButton, from.X, from.Y)
+		utils.Sleep(300)
+		ctx.HID.Click(game.LeftButton, to.X, to.Y)
+		utils.Sleep(300)
+
+		slots = slots[1:]
+		missing[pt]--
+	}
+}
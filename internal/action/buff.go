@@ -4,12 +4,11 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
 	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
-	"github.com/hectorgimenez/d2go/pkg/data/state"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/buff"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/utils"
@@ -43,7 +42,7 @@ func Buff() {
 	ctx := context.Get()
 	ctx.SetLastAction("Buff")
 
-	if ctx.Data.PlayerUnit.Area.IsTown() || time.Since(ctx.LastBuffAt) < time.Second*30 {
+	if ctx.Data.PlayerUnit.Area.IsTown() {
 		return
 	}
 
@@ -56,86 +55,69 @@ func Buff() {
 		utils.Sleep(500)
 	}
 
-	preKeys := make([]data.KeyBinding, 0)
-	for _, buff := range ctx.Char.PreCTABuffSkills() {
-		kb, found := ctx.Data.KeyBindings.KeyBindingForSkill(buff)
-		if !found {
-			ctx.Logger.Info("Key binding not found, skipping buff", slog.String("skill", buff.Desc().Name))
-		} else {
-			preKeys = append(preKeys, kb)
-		}
+	plan := buffPlan()
+	if plan.Empty() {
+		return
 	}
 
-	if len(preKeys) > 0 {
+	if len(plan.PreCTA) > 0 {
 		ctx.Logger.Debug("PRE CTA Buffing...")
-		for _, kb := range preKeys {
-			utils.Sleep(100)
-			ctx.HID.PressKeyBinding(kb)
-			utils.Sleep(180)
-			ctx.HID.Click(game.RightButton, 640, 340)
-			utils.Sleep(100)
-		}
+		castBuffs(plan.PreCTA)
 	}
 
-	buffCTA()
-
-	postKeys := make([]data.KeyBinding, 0)
-	for _, buff := range ctx.Char.BuffSkills() {
-		kb, found := ctx.Data.KeyBindings.KeyBindingForSkill(buff)
-		if !found {
-			ctx.Logger.Info("Key binding not found, skipping buff", slog.String("skill", buff.Desc().Name))
-		} else {
-			postKeys = append(postKeys, kb)
-		}
+	if plan.CTA {
+		buffCTA()
 	}
 
-	if len(postKeys) > 0 {
+	if len(plan.Post) > 0 {
 		ctx.Logger.Debug("Post CTA Buffing...")
-
-		for _, kb := range postKeys {
-			utils.Sleep(100)
-			ctx.HID.PressKeyBinding(kb)
-			utils.Sleep(180)
-			ctx.HID.Click(game.RightButton, 640, 340)
-			utils.Sleep(100)
-		}
-		ctx.LastBuffAt = time.Now()
+		castBuffs(plan.Post)
 	}
 }
 
+// IsRebuffRequired returns true if any buff is missing or about to expire, see buff.Scheduler
 func IsRebuffRequired() bool {
 	ctx := context.Get()
 	ctx.SetLastAction("IsRebuffRequired")
 
-	// Don't buff if we are in town, or we did it recently (it prevents double buffing because of network lag)
-	if ctx.Data.PlayerUnit.Area.IsTown() || time.Since(ctx.LastBuffAt) < time.Second*30 {
+	if ctx.Data.PlayerUnit.Area.IsTown() {
 		return false
 	}
 
-	if ctaFound(*ctx.Data) && (!ctx.Data.PlayerUnit.States.HasState(state.Battleorders) || !ctx.Data.PlayerUnit.States.HasState(state.Battlecommand)) {
-		return true
+	return !buffPlan().Empty()
+}
+
+func buffPlan() buff.Plan {
+	ctx := context.Get()
+
+	hasKeyBinding := func(id skill.ID) bool {
+		_, found := ctx.Data.KeyBindings.KeyBindingForSkill(id)
+		return found
 	}
+	preCTA := buff.Bound(ctx.Char.PreCTABuffSkills(), hasKeyBinding)
+	post := buff.Bound(ctx.Char.BuffSkills(), hasKeyBinding)
 
-	// TODO: Find a better way to convert skill to state
-	buffs := ctx.Char.BuffSkills()
-	for _, buff := range buffs {
-		if _, found := ctx.Data.KeyBindings.KeyBindingForSkill(buff); found {
-			if buff == skill.HolyShield && !ctx.Data.PlayerUnit.States.HasState(state.Holyshield) {
-				return true
-			}
-			if buff == skill.FrozenArmor && (!ctx.Data.PlayerUnit.States.HasState(state.Frozenarmor) && !ctx.Data.PlayerUnit.States.HasState(state.Shiverarmor) && !ctx.Data.PlayerUnit.States.HasState(state.Chillingarmor)) {
-				return true
-			}
-			if buff == skill.EnergyShield && !ctx.Data.PlayerUnit.States.HasState(state.Energyshield) {
-				return true
-			}
-			if buff == skill.CycloneArmor && !ctx.Data.PlayerUnit.States.HasState(state.Cyclonearmor) {
-				return true
-			}
+	return ctx.Buffs.Plan(preCTA, post, ctaFound(*ctx.Data), ctx.Data.PlayerUnit.States, time.Now())
+}
+
+func castBuffs(buffs []skill.ID) {
+	ctx := context.Get()
+
+	for _, b := range buffs {
+		// Unbound skills are removed from the plan, see buffPlan
+		kb, found := ctx.Data.KeyBindings.KeyBindingForSkill(b)
+		if !found {
+			ctx.Logger.Info("Key binding not found, skipping buff", slog.String("skill", b.Desc().Name))
+			continue
 		}
-	}
 
-	return false
+		utils.Sleep(100)
+		ctx.HID.PressKeyBinding(kb)
+		utils.Sleep(180)
+		ctx.HID.Click(game.RightButton, 640, 340)
+		utils.Sleep(100)
+		ctx.Buffs.Casted(b, int(ctx.Data.PlayerUnit.Skills[b].Level), time.Now())
+	}
 }
 
 func buffCTA() {
@@ -159,6 +141,11 @@ func buffCTA() {
 		ctx.HID.Click(game.RightButton, 300, 300)
 		utils.Sleep(100)
 
+		// Skill levels are only known while the CTA is equipped
+		now := time.Now()
+		ctx.Buffs.Casted(skill.BattleCommand, int(ctx.Data.PlayerUnit.Skills[skill.BattleCommand].Level), now)
+		ctx.Buffs.Casted(skill.BattleOrders, int(ctx.Data.PlayerUnit.Skills[skill.BattleOrders].Level), now)
+
 		utils.Sleep(500)
 		step.SwapToMainWeapon()
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/pather"
+	"github.com/hectorgimenez/koolo/internal/target"
 )
 
 func ClearAreaAroundPlayer(radius int, filter data.MonsterFilter) error {
@@ -18,18 +19,32 @@ func ClearAreaAroundPosition(pos data.Position, radius int, filter data.MonsterF
 	ctx := context.Get()
 	ctx.SetLastAction("ClearAreaAroundPosition")
 
+	policy := targetPolicy()
+
 	return ctx.Char.KillMonsterSequence(func(d game.Data) (data.UnitID, bool) {
+		candidates := make([]data.Monster, 0)
 		for _, m := range d.Monsters.Enemies(filter) {
 			distanceToTarget := pather.DistanceFromPoint(pos, m.Position)
 			if ctx.Data.AreaData.IsWalkable(m.Position) && distanceToTarget <= radius {
-				return m.UnitID, true
+				candidates = append(candidates, m)
 			}
 		}
 
+		if m, found := policy.Select(candidates, d.PlayerUnit.Position); found {
+			return m.UnitID, true
+		}
+
 		return 0, false
 	}, nil)
 }
 
+// targetPolicy returns the target selection policy configured for the current run
+func targetPolicy() target.Policy {
+	ctx := context.Get()
+
+	return ctx.CharacterCfg.Game.Targeting.For(ctx.CurrentGame.CurrentRun)
+}
+
 func ClearThroughPath(pos data.Position, radius int, filter data.MonsterFilter) error {
 	ctx := context.Get()
 
@@ -72,12 +72,12 @@ func clearRoom(room data.Room, filter data.MonsterFilter) error {
 			return nil
 		}
 
-		// Check if there are monsters that can summon new monsters, and kill them first
-		targetMonster := monsters[0]
-		for _, m := range monsters {
-			if m.IsMonsterRaiser() {
-				targetMonster = m
-			}
+		// Monsters that can summon or resurrect other monsters are always killed first
+		policy := targetPolicy()
+		policy.RaisersFirst = true
+		targetMonster, found := policy.Select(monsters, ctx.Data.PlayerUnit.Position)
+		if !found {
+			return nil
 		}
 
 		path, _, mPathFound := ctx.PathFinder.GetPath(targetMonster.Position)
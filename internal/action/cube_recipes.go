@@ -1,357 +1,23 @@
 package action
 
 import (
+	"errors"
+	"fmt"
 	"slices"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/recipe"
+	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/utils"
-)
-
-type CubeRecipe struct {
-	Name             string
-	Items            []string
-	PurchaseRequired bool
-	PurchaseItems    []string
-}
-
-var (
-	Recipes = []CubeRecipe{
-
-		// Perfects
-		{
-			Name:  "Perfect Amethyst",
-			Items: []string{"FlawlessAmethyst", "FlawlessAmethyst", "FlawlessAmethyst"},
-		},
-		{
-			Name:  "Perfect Diamond",
-			Items: []string{"FlawlessDiamond", "FlawlessDiamond", "FlawlessDiamond"},
-		},
-		{
-			Name:  "Perfect Emerald",
-			Items: []string{"FlawlessEmerald", "FlawlessEmerald", "FlawlessEmerald"},
-		},
-		{
-			Name:  "Perfect Ruby",
-			Items: []string{"FlawlessRuby", "FlawlessRuby", "FlawlessRuby"},
-		},
-		{
-			Name:  "Perfect Sapphire",
-			Items: []string{"FlawlessSapphire", "FlawlessSapphire", "FlawlessSapphire"},
-		},
-		{
-			Name:  "Perfect Topaz",
-			Items: []string{"FlawlessTopaz", "FlawlessTopaz", "FlawlessTopaz"},
-		},
-		{
-			Name:  "Perfect Skull",
-			Items: []string{"FlawlessSkull", "FlawlessSkull", "FlawlessSkull"},
-		},
-
-		// Token
-		{
-			Name:  "Token of Absolution",
-			Items: []string{"TwistedEssenceOfSuffering", "ChargedEssenceOfHatred", "BurningEssenceOfTerror", "FesteringEssenceOfDestruction"},
-		},
-
-		// Runes
-		{
-			Name:  "Upgrade El",
-			Items: []string{"ElRune", "ElRune", "ElRune"},
-		},
-		{
-			Name:  "Upgrade Eld",
-			Items: []string{"EldRune", "EldRune", "EldRune"},
-		},
-		{
-			Name:  "Upgrade Tir",
-			Items: []string{"TirRune", "TirRune", "TirRune"},
-		},
-		{
-			Name:  "Upgrade Nef",
-			Items: []string{"NefRune", "NefRune", "NefRune"},
-		},
-		{
-			Name:  "Upgrade Eth",
-			Items: []string{"EthRune", "EthRune", "EthRune"},
-		},
-		{
-			Name:  "Upgrade Ith",
-			Items: []string{"IthRune", "IthRune", "IthRune"},
-		},
-		{
-			Name:  "Upgrade Tal",
-			Items: []string{"TalRune", "TalRune", "TalRune"},
-		},
-		{
-			Name:  "Upgrade Ral",
-			Items: []string{"RalRune", "RalRune", "RalRune"},
-		},
-		{
-			Name:  "Upgrade Ort",
-			Items: []string{"OrtRune", "OrtRune", "OrtRune"},
-		},
-		{
-			Name:  "Upgrade Thul",
-			Items: []string{"ThulRune", "ThulRune", "ThulRune", "ChippedTopaz"},
-		},
-		{
-			Name:  "Upgrade Amn",
-			Items: []string{"AmnRune", "AmnRune", "AmnRune", "ChippedAmethyst"},
-		},
-		{
-			Name:  "Upgrade Sol",
-			Items: []string{"SolRune", "SolRune", "SolRune", "ChippedSapphire"},
-		},
-		{
-			Name:  "Upgrade Shael",
-			Items: []string{"ShaelRune", "ShaelRune", "ShaelRune", "ChippedRuby"},
-		},
-		{
-			Name:  "Upgrade Dol",
-			Items: []string{"DolRune", "DolRune", "DolRune", "ChippedEmerald"},
-		},
-		{
-			Name:  "Upgrade Hel",
-			Items: []string{"HelRune", "HelRune", "HelRune", "ChippedDiamond"},
-		},
-		{
-			Name:  "Upgrade Io",
-			Items: []string{"IoRune", "IoRune", "IoRune", "FlawedTopaz"},
-		},
-		{
-			Name:  "Upgrade Lum",
-			Items: []string{"LumRune", "LumRune", "LumRune", "FlawedAmethyst"},
-		},
-		{
-			Name:  "Upgrade Ko",
-			Items: []string{"KoRune", "KoRune", "KoRune", "FlawedSapphire"},
-		},
-		{
-			Name:  "Upgrade Fal",
-			Items: []string{"FalRune", "FalRune", "FalRune", "FlawedRuby"},
-		},
-		{
-			Name:  "Upgrade Lem",
-			Items: []string{"LemRune", "LemRune", "LemRune", "FlawedEmerald"},
-		},
-		{
-			Name:  "Upgrade Pul",
-			Items: []string{"PulRune", "PulRune", "FlawedDiamond"},
-		},
-		{
-			Name:  "Upgrade Um",
-			Items: []string{"UmRune", "UmRune", "Topaz"},
-		},
-		{
-			Name:  "Upgrade Mal",
-			Items: []string{"MalRune", "MalRune", "Amethyst"},
-		},
-		{
-			Name:  "Upgrade Ist",
-			Items: []string{"IstRune", "IstRune", "Sapphire"},
-		},
-		{
-			Name:  "Upgrade Gul",
-			Items: []string{"GulRune", "GulRune", "Ruby"},
-		},
-		{
-			Name:  "Upgrade Vex",
-			Items: []string{"VexRune", "VexRune", "Emerald"},
-		},
-		{
-			Name:  "Upgrade Ohm",
-			Items: []string{"OhmRune", "OhmRune", "Diamond"},
-		},
-		{
-			Name:  "Upgrade Lo",
-			Items: []string{"LoRune", "LoRune", "FlawlessTopaz"},
-		},
-		{
-			Name:  "Upgrade Sur",
-			Items: []string{"SurRune", "SurRune", "FlawlessAmethyst"},
-		},
-		{
-			Name:  "Upgrade Ber",
-			Items: []string{"BerRune", "BerRune", "FlawlessSapphire"},
-		},
-		{
-			Name:  "Upgrade Jah",
-			Items: []string{"JahRune", "JahRune", "FlawlessRuby"},
-		},
-		{
-			Name:  "Upgrade Cham",
-			Items: []string{"ChamRune", "ChamRune", "FlawlessEmerald"},
-		},
-
-		// Crafting
-		{
-			Name:  "Reroll GrandCharms",
-			Items: []string{"GrandCharm", "Perfect", "Perfect", "Perfect"}, // Special handling in hasItemsForRecipe
-		},
-
-		// Caster Amulet
-		{
-			Name:             "Caster Amulet",
-			Items:            []string{"RalRune", "PerfectAmethyst", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Amulet"},
-		},
-
-		// Caster Ring
-		{
-			Name:             "Caster Ring",
-			Items:            []string{"AmnRune", "PerfectAmethyst", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Ring"},
-		},
-
-		// Blood Gloves
-		{
-			Name:             "Blood Gloves",
-			Items:            []string{"NefRune", "PerfectRuby", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"HeavyGloves", "SharkskinGloves", "VampireboneGloves"},
-		},
-
-		// Blood Boots
-		{
-			Name:             "Blood Boots",
-			Items:            []string{"EthRune", "PerfectRuby", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"LightPlatedBoots", "BattleBoots", "MirroredBoots"},
-		},
-
-		// Blood Belt
-		{
-			Name:             "Blood Belt",
-			Items:            []string{"TalRune", "PerfectRuby", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Belt", "MeshBelt", "MithrilCoil"},
-		},
-
-		// Blood Helm
-		{
-			Name:             "Blood Helm",
-			Items:            []string{"RalRune", "PerfectRuby", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Helm", "Casque", "Armet"},
-		},
-
-		// Blood Armor
-		{
-			Name:             "Blood Armor",
-			Items:            []string{"ThulRune", "PerfectRuby", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"PlateMail", "TemplarPlate", "HellforgePlate"},
-		},
-
-		// Blood Weapon
-		{
-			Name:             "Blood Weapon",
-			Items:            []string{"OrtRune", "PerfectRuby", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Axe"},
-		},
-
-		// Safety Shield
-		{
-			Name:             "Safety Shield",
-			Items:            []string{"NefRune", "PerfectEmerald", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"KiteShield", "DragonShield", "Monarch"},
-		},
-
-		// Safety Armor
-		{
-			Name:             "Safety Armor",
-			Items:            []string{"EthRune", "PerfectEmerald", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"BreastPlate", "Curiass", "GreatHauberk"},
-		},
-
-		// Safety Boots
-		{
-			Name:             "Safety Boots",
-			Items:            []string{"OrtRune", "PerfectEmerald", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Greaves", "WarBoots", "MyrmidonBoots"},
-		},
-
-		// Safety Gloves
-		{
-			Name:             "Safety Gloves",
-			Items:            []string{"RalRune", "PerfectEmerald", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Gauntlets", "WarGauntlets", "OgreGauntlets"},
-		},
-
-		// Safety Belt
-		{
-			Name:             "Safety Belt",
-			Items:            []string{"TalRune", "PerfectEmerald", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Sash", "DemonhideSash", "SpiderwebSash"},
-		},
-
-		// Safety Helm
-		{
-			Name:             "Safety Helm",
-			Items:            []string{"IthRune", "PerfectEmerald", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"Crown", "GrandCrown", "Corona"},
-		},
-
-		// Hitpower Gloves
-		{
-			Name:             "Hitpower Gloves",
-			Items:            []string{"OrtRune", "PerfectSapphire", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"ChainGloves", "HeavyBracers", "Vambraces"},
-		},
-
-		// Hitpower Boots
-		{
-			Name:             "Hitpower Boots",
-			Items:            []string{"RalRune", "PerfectSapphire", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"ChainBoots", "MeshBoots", "Boneweave"},
-		},
-
-		// Hitpower Belt
-		{
-			Name:             "Hitpower Belt",
-			Items:            []string{"TalRune", "PerfectSapphire", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"HeavyBelt", "BattleBelt", "TrollBelt"},
-		},
-
-		// Hitpower Helm
-		{
-			Name:             "Hitpower Helm",
-			Items:            []string{"NefRune", "PerfectSapphire", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"FullHelm", "Basinet", "GiantConch"},
-		},
-
-		// Hitpower Armor
-		{
-			Name:             "Hitpower Armor",
-			Items:            []string{"EthRune", "PerfectSapphire", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"FieldPlate", "Sharktooth", "KrakenShell"},
-		},
-
-		// Hitpower Shield
-		{
-			Name:             "Hitpower Shield",
-			Items:            []string{"IthRune", "PerfectSapphire", "Jewel"},
-			PurchaseRequired: true,
-			PurchaseItems:    []string{"GothicShield", "AncientShield", "Ward"},
-		},
-	}
+	"github.com/lxn/win"
 )
 
 func CubeRecipes() error {
@@ -365,38 +31,33 @@ func CubeRecipes() error {
 	}
 
 	itemsInStash := ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash)
-	for _, recipe := range Recipes {
+	sellingRequired := false
+	for _, cubeRecipe := range config.Recipes {
 		// Check if the current recipe is Enabled
-		if !slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, recipe.Name) {
+		if !slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, cubeRecipe.Name) {
 			// is this really needed ? making huge logs
-			//		ctx.Logger.Debug("Cube recipe is not enabled, skipping", "recipe", recipe.Name)
+			//		ctx.Logger.Debug("Cube recipe is not enabled, skipping", "recipe", cubeRecipe.Name)
 			continue
 		}
 
-		ctx.Logger.Debug("Cube recipe is enabled, processing", "recipe", recipe.Name)
+		ctx.Logger.Debug("Cube recipe is enabled, processing", "recipe", cubeRecipe.Name)
 
 		continueProcessing := true
 		for continueProcessing {
-			if items, hasItems := hasItemsForRecipe(ctx, recipe); hasItems {
-
-				// TODO: Check if we have the items in our storage and if not, purchase them, else take the item from the storage
-				if recipe.PurchaseRequired {
-					err := GambleSingleItem(recipe.PurchaseItems, item.QualityMagic)
+			if items, hasBase, hasItems := itemsForRecipe(ctx, cubeRecipe); hasItems {
+				if cubeRecipe.IsCrafting() && !hasBase {
+					base, err := acquireCraftingBase(ctx, cubeRecipe)
 					if err != nil {
-						ctx.Logger.Error("Error gambling item, skipping recipe", "error", err, "recipe", recipe.Name)
-						break
-					}
-
-					purchasedItem := getPurchasedItem(ctx, recipe.PurchaseItems)
-					if purchasedItem.Name == "" {
-						ctx.Logger.Debug("Could not find purchased item. Skipping recipe", "recipe", recipe.Name)
+						ctx.Logger.Info("Could not get crafting base, skipping recipe", "error", err, "recipe", cubeRecipe.Name)
 						break
 					}
 
 					// Add the purchased item the list of items to cube
-					items = append(items, purchasedItem)
+					items = append(items, base)
 				}
 
+				itemsBefore := inventoryUnitIDs(ctx)
+
 				// Add items to the cube and perform the transmutation
 				err := CubeAddItems(items...)
 				if err != nil {
@@ -406,6 +67,20 @@ func CubeRecipes() error {
 					return err
 				}
 
+				// Crafted items are evaluated against the pickit rules, keepers are stashed and the rest sold
+				crafted := make(map[data.UnitID]bool)
+				if cubeRecipe.IsCrafting() {
+					for _, itm := range newInventoryItems(ctx, itemsBefore, items) {
+						crafted[itm.UnitID] = true
+						shouldStash, _, _ := shouldStashIt(itm, false)
+						ctx.Logger.Info("Item crafted", "item", itm.Name, "recipe", cubeRecipe.Name, "kept", shouldStash)
+						event.Send(event.ItemCrafted(event.Text(ctx.Name, fmt.Sprintf("Item %s crafted using %s recipe", itm.Name, cubeRecipe.Name)), cubeRecipe.Name, itm, shouldStash))
+						if !shouldStash {
+							sellingRequired = true
+						}
+					}
+				}
+
 				// Get a list of items that are in our inventory
 				itemsInInv := ctx.Data.Inventory.ByLocation(item.LocationInventory)
 
@@ -423,10 +98,13 @@ func CubeRecipes() error {
 						shouldStash, reason, _ := shouldStashIt(item, false)
 
 						if shouldStash {
-							ctx.Logger.Debug("Stashing item after cube recipe.", "item", item.Name, "recipe", recipe.Name, "reason", reason)
+							ctx.Logger.Debug("Stashing item after cube recipe.", "item", item.Name, "recipe", cubeRecipe.Name, "reason", reason)
 							stashingRequired = true
+						} else if crafted[item.UnitID] {
+							// Failed crafts are sold at the vendor once all the recipes are done
+							continue
 						} else if item.Name == "GrandCharm" {
-							ctx.Logger.Debug("Checking if we need to stash a GrandCharm that doesn't match any NIP rules.", "recipe", recipe.Name)
+							ctx.Logger.Debug("Checking if we need to stash a GrandCharm that doesn't match any NIP rules.", "recipe", cubeRecipe.Name)
 							// Check if we have a GrandCharm in stash that doesn't match any NIP rules
 							hasUnmatchedGrandCharm := false
 							for _, stashItem := range itemsInStash {
@@ -439,7 +117,7 @@ func CubeRecipes() error {
 							}
 							if !hasUnmatchedGrandCharm {
 
-								ctx.Logger.Debug("GrandCharm doesn't match any NIP rules and we don't have any in stash to be used for this recipe. Stashing it.", "recipe", recipe.Name)
+								ctx.Logger.Debug("GrandCharm doesn't match any NIP rules and we don't have any in stash to be used for this recipe. Stashing it.", "recipe", cubeRecipe.Name)
 								stashingRequired = true
 								stashingGrandCharm = true
 
@@ -470,89 +148,183 @@ func CubeRecipes() error {
 		}
 	}
 
+	if sellingRequired {
+		return VendorRefill(false, true)
+	}
+
 	return nil
 }
 
-func hasItemsForRecipe(ctx *context.Status, recipe CubeRecipe) ([]data.Item, bool) {
-
+// itemsForRecipe returns the stash items to be used by the recipe. Crafting recipes can be processed without the
+// base if it can be bought, hasBase tells if the base is already part of the returned items.
+func itemsForRecipe(ctx *context.Status, r recipe.Recipe) (items []data.Item, hasBase bool, found bool) {
 	ctx.RefreshGameData()
-	items := ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash)
-	// Special handling for "Reroll GrandCharms" recipe
-	if recipe.Name == "Reroll GrandCharms" {
-		return hasItemsForGrandCharmReroll(ctx, items)
+	stashItems := ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash)
+	opts := matchOptions(ctx)
+
+	if !r.IsCrafting() {
+		items, found = r.Match(stashItems, opts)
+		return items, false, found
 	}
 
-	recipeItems := make(map[string]int)
-	for _, item := range recipe.Items {
-		recipeItems[item]++
+	if slices.Contains(r.Base.Sources, recipe.SourceStash) {
+		if items, found = r.Match(stashItems, opts); found {
+			return items, true, true
+		}
 	}
 
-	itemsForRecipe := []data.Item{}
+	if !canBuyCraftingBase(ctx, r) {
+		return nil, false, false
+	}
 
-	// Iterate over the items in our stash to see if we have the items for the recipie.
-	for _, item := range items {
-		if count, ok := recipeItems[string(item.Name)]; ok {
+	items, found = r.MatchIngredients(stashItems, opts)
+	return items, false, found
+}
 
-			// Let's make sure we don't use an item we don't want to. Add more if needed (depending on the recipes we have)
-			if item.Name == "Jewel" {
-				if _, result := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(item); result == nip.RuleResultFullMatch {
-					continue
-				}
-			}
+func matchOptions(ctx *context.Status) recipe.MatchOptions {
+	// Skipped gems are kept for other recipes, but still used when a recipe explicitly requires them
+	excluded := make([]string, 0)
+	if ctx.CharacterCfg.CubeRecipes.SkipPerfectAmethysts {
+		excluded = append(excluded, "PerfectAmethyst")
+	}
+	if ctx.CharacterCfg.CubeRecipes.SkipPerfectRubies {
+		excluded = append(excluded, "PerfectRuby")
+	}
 
-			itemsForRecipe = append(itemsForRecipe, item)
+	return recipe.MatchOptions{
+		Rules:   ctx.CharacterCfg.Runtime.Rules,
+		Exclude: excluded,
+	}
+}
 
-			// Check if we now have exactly the needed count before decrementing
-			count -= 1
-			if count == 0 {
-				delete(recipeItems, string(item.Name))
-				if len(recipeItems) == 0 {
-					return itemsForRecipe, true
-				}
-			} else {
-				recipeItems[string(item.Name)] = count
+// canBuyCraftingBase checks if the base can be bought or gambled, item level of the bought items depends on the
+// character level, so low level characters are not allowed to buy bases when the recipe requires it.
+func canBuyCraftingBase(ctx *context.Status, r recipe.Recipe) bool {
+	if !slices.Contains(r.Base.Sources, recipe.SourceVendor) && !slices.Contains(r.Base.Sources, recipe.SourceGamble) {
+		return false
+	}
+
+	lvl, _ := ctx.Data.PlayerUnit.FindStat(stat.Level, 0)
+
+	return lvl.Value >= r.Base.MinCharacterLevel
+}
+
+func acquireCraftingBase(ctx *context.Status, r recipe.Recipe) (data.Item, error) {
+	ctx.SetLastStep("acquireCraftingBase")
+
+	for _, source := range r.Base.Sources {
+		itemsBefore := inventoryUnitIDs(ctx)
+
+		var err error
+		switch source {
+		case recipe.SourceVendor:
+			err = buyCraftingBase(ctx, r)
+		case recipe.SourceGamble:
+			err = GambleSingleItem(r.Base.Names(), item.QualityMagic)
+		default:
+			continue
+		}
+		if err != nil {
+			ctx.Logger.Debug("Error getting crafting base", "source", source, "recipe", r.Name, "error", err)
+			continue
+		}
+
+		for _, itm := range newInventoryItems(ctx, itemsBefore, nil) {
+			if r.Base.Matches(itm, matchOptions(ctx)) {
+				ctx.Logger.Debug("Got crafting base", "item", itm.Name, "source", source, "recipe", r.Name)
+				return itm, nil
 			}
 		}
 	}
 
-	// We don't have all the items for the recipie.
-	return nil, false
+	return data.Item{}, errors.New("crafting base not available in any of the sources")
 }
 
-func hasItemsForGrandCharmReroll(ctx *context.Status, items []data.Item) ([]data.Item, bool) {
-	var grandCharm data.Item
-	perfectGems := make([]data.Item, 0, 3)
+// craftingBaseMaxPrice is the price assumed for a crafting base never bought before, vendor prices are not exposed by
+// the memory reader so the real one is learnt from the gold spent. It's high on purpose, so the first purchase can't
+// go below the gold floor.
+const craftingBaseMaxPrice = 100000
 
-	for _, itm := range items {
-		if itm.Name == "GrandCharm" {
-			if _, result := ctx.CharacterCfg.Runtime.Rules.EvaluateAll(itm); result != nip.RuleResultFullMatch && itm.Quality == item.QualityMagic {
-				grandCharm = itm
-			}
-		} else if isPerfectGem(itm) && len(perfectGems) < 3 {
-			// Skip perfect amethysts and rubies if configured
-			if (ctx.CharacterCfg.CubeRecipes.SkipPerfectAmethysts && itm.Name == "PerfectAmethyst") ||
-				(ctx.CharacterCfg.CubeRecipes.SkipPerfectRubies && itm.Name == "PerfectRuby") {
-				continue
-			}
-			perfectGems = append(perfectGems, itm)
+func buyCraftingBase(ctx *context.Status, r recipe.Recipe) error {
+	floor := ctx.CharacterCfg.CubeRecipes.GoldFloor
+	if ctx.Data.PlayerUnit.TotalPlayerGold() <= floor {
+		return fmt.Errorf("gold below the cubing gold floor (%d)", floor)
+	}
+
+	vendorNPC := town.GetTownByArea(ctx.Data.PlayerUnit.Area).RepairNPC()
+	if vendorNPC == npc.Hratli {
+		MoveToCoords(data.Position{X: 5224, Y: 5045})
+	}
+
+	if err := InteractNPC(vendorNPC); err != nil {
+		return err
+	}
+
+	if vendorNPC != npc.Halbu {
+		ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+	} else {
+		ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+	}
+	ctx.RefreshGameData()
+
+	if !ctx.Data.OpenMenus.NPCShop {
+		return errors.New("failed opening vendor window")
+	}
+
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationVendor) {
+		if !r.Base.Matches(itm, matchOptions(ctx)) {
+			continue
 		}
 
-		if grandCharm.Name != "" && len(perfectGems) == 3 {
-			return append([]data.Item{grandCharm}, perfectGems...), true
+		gold := ctx.Data.PlayerUnit.TotalPlayerGold()
+		price, known := ctx.VendorPrices[itm.Name]
+		if !known {
+			price = craftingBaseMaxPrice
+		}
+		if gold-price < floor {
+			_ = step.CloseAllMenus()
+			return fmt.Errorf("not enough gold to buy %s, expected price is %d and the cubing gold floor is %d", itm.Name, price, floor)
 		}
+
+		SwitchStashTab(itm.Location.Page + 1)
+		town.BuyItem(itm, 1)
+		ctx.RefreshGameData()
+
+		if paid := gold - ctx.Data.PlayerUnit.TotalPlayerGold(); paid > 0 {
+			ctx.VendorPrices[itm.Name] = paid
+			ctx.Logger.Debug("Bought crafting base", "item", itm.Name, "price", paid, "recipe", r.Name)
+		}
+
+		return step.CloseAllMenus()
+	}
+
+	_ = step.CloseAllMenus()
+
+	return errors.New("crafting base not found at vendor")
+}
+
+func inventoryUnitIDs(ctx *context.Status) map[data.UnitID]bool {
+	ctx.RefreshGameData()
+	ids := make(map[data.UnitID]bool)
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
+		ids[itm.UnitID] = true
 	}
 
-	return nil, false
+	return ids
 }
 
-func isPerfectGem(item data.Item) bool {
-	perfectGems := []string{"PerfectAmethyst", "PerfectDiamond", "PerfectEmerald", "PerfectRuby", "PerfectSapphire", "PerfectTopaz", "PerfectSkull"}
-	for _, gemName := range perfectGems {
-		if string(item.Name) == gemName {
-			return true
+// newInventoryItems returns the inventory items that were not present before, ignoring the ones given
+func newInventoryItems(ctx *context.Status, before map[data.UnitID]bool, ignored []data.Item) []data.Item {
+	ctx.RefreshGameData()
+	items := make([]data.Item, 0)
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
+		if before[itm.UnitID] || slices.ContainsFunc(ignored, func(i data.Item) bool { return i.UnitID == itm.UnitID }) {
+			continue
 		}
+		items = append(items, itm)
 	}
-	return false
+
+	return items
 }
 
 func removeUsedItems(stash []data.Item, usedItems []data.Item) []data.Item {
@@ -575,15 +347,3 @@ func removeUsedItems(stash []data.Item, usedItems []data.Item) []data.Item {
 
 	return remainingItems
 }
-
-func getPurchasedItem(ctx *context.Status, purchaseItems []string) data.Item {
-	itemsInInv := ctx.Data.Inventory.ByLocation(item.LocationInventory)
-	for _, citem := range itemsInInv {
-		for _, pi := range purchaseItems {
-			if string(citem.Name) == pi && citem.Quality == item.QualityMagic {
-				return citem
-			}
-		}
-	}
-	return data.Item{}
-}
@@ -0,0 +1,45 @@
+package action
+
+import (
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/ui"
+	"github.com/hectorgimenez/koolo/internal/utils"
+)
+
+// UseCurePotions drinks a thawing or antidote potion from the inventory when frozen or poisoned, potions should be
+// kept in locked inventory slots, so they are not sold in town.
+func UseCurePotions() error {
+	ctx := context.Get()
+	ctx.SetLastAction("UseCurePotions")
+
+	name, needed := ctx.CharacterCfg.Curses.Potion(ctx.Data.PlayerUnit.States)
+	if !needed || ctx.Data.PlayerUnit.Area.IsTown() {
+		return nil
+	}
+
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationInventory) {
+		if itm.Name != name {
+			continue
+		}
+
+		ctx.Logger.Debug("Drinking cure potion", slog.String("potion", string(name)))
+		step.CloseAllMenus()
+		for !ctx.Data.OpenMenus.Inventory {
+			ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.Inventory)
+			utils.Sleep(300)
+		}
+
+		screenPos := ui.GetScreenCoordsForItem(itm)
+		ctx.HID.Click(game.RightButton, screenPos.X, screenPos.Y)
+		utils.Sleep(200)
+
+		return step.CloseAllMenus()
+	}
+
+	return nil
+}
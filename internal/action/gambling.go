@@ -2,7 +2,10 @@ package action
 
 import (
 	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand"
+	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/item"
@@ -11,6 +14,8 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/nip"
 	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/gamble"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/town"
 	"github.com/hectorgimenez/koolo/internal/ui"
@@ -23,7 +28,7 @@ func Gamble() error {
 	ctx.SetLastAction("Gamble")
 
 	stashedGold, _ := ctx.Data.PlayerUnit.FindStat(stat.StashGold, 0)
-	if ctx.CharacterCfg.Gambling.Enabled && stashedGold.Value >= 2480000 {
+	if ctx.CharacterCfg.Gambling.Enabled && ctx.CharacterCfg.GamblingStrategy().ShouldStart(stashedGold.Value) {
 		ctx.Logger.Info("Time to gamble! Visiting vendor...")
 
 		vendorNPC := town.GetTownByArea(ctx.Data.PlayerUnit.Area).GamblingNPC()
@@ -149,22 +154,18 @@ func gambleItems() error {
 	ctx := context.Get()
 	ctx.SetLastAction("gambleItems")
 
+	lvl, _ := ctx.Data.PlayerUnit.FindStat(stat.Level, 0)
+	session := gamble.NewSession(ctx.CharacterCfg.GamblingStrategy(), lvl.Value, rand.New(rand.NewSource(time.Now().UnixNano())))
+
 	var itemBought data.Item
+	var goldBeforeBuy int
 	var refreshAttempts int
-	var currentItemIndex int
 	const maxRefreshAttempts = 11
 
 	for {
 		ctx.PauseIfNotPriority()
 		ctx.RefreshGameData()
 
-		// Check if we should stop gambling due to low gold
-		if ctx.Data.PlayerUnit.TotalPlayerGold() < 500000 {
-			ctx.Logger.Info("Finished gambling - gold below 500k",
-				slog.Int("currentGold", ctx.Data.PlayerUnit.TotalPlayerGold()))
-			return step.CloseAllMenus()
-		}
-
 		// Process bought item if we have one
 		if itemBought.Name != "" {
 			// Find the bought item in inventory
@@ -176,8 +177,13 @@ func gambleItems() error {
 				}
 			}
 
+			// Game data was refreshed after buying and before selling, so the gold difference is the item cost
+			cost := goldBeforeBuy - ctx.Data.PlayerUnit.TotalPlayerGold()
+
 			// Check if item matches NIP rules
-			if _, result := ctx.Data.CharacterCfg.Runtime.Rules.EvaluateAll(itemBought); result == nip.RuleResultFullMatch {
+			_, result := ctx.Data.CharacterCfg.Runtime.Rules.EvaluateAll(itemBought)
+			kept := result == nip.RuleResultFullMatch
+			if kept {
 				ctx.Logger.Info("Found item matching NIP rules, keeping", slog.Any("item", itemBought))
 			} else {
 				// Filter not pass, selling the item
@@ -185,21 +191,34 @@ func gambleItems() error {
 				town.SellItem(itemBought)
 			}
 
+			session.Record(gamble.Outcome{Item: string(itemBought.Name), Quality: itemBought.Quality, Cost: cost, Kept: kept})
+			event.Send(event.ItemGambled(event.Text(ctx.Name, fmt.Sprintf("Gambled %s [%s]", itemBought.Name, itemBought.Quality.ToString())), itemBought, cost, kept))
+
 			itemBought = data.Item{} // Reset itemBought after processing
 			refreshAttempts = 0      // Reset refresh counter after successful purchase
-
-			// Move to next item in the gambling list
-			currentItemIndex = (currentItemIndex + 1) % len(ctx.Data.CharacterCfg.Gambling.Items)
 			continue
 		}
 
+		if finished, reason := session.Finished(ctx.Data.PlayerUnit.TotalPlayerGold()); finished {
+			ctx.Logger.Info("Finished gambling",
+				slog.String("reason", reason),
+				slog.Int("spent", session.Spent()),
+				slog.Int("hits", session.Hits()),
+				slog.Int("currentGold", ctx.Data.PlayerUnit.TotalPlayerGold()))
+			return step.CloseAllMenus()
+		}
+
 		// Try to find and buy items
+		available := make([]string, 0)
+		for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationVendor) {
+			available = append(available, string(itm.Name))
+		}
+
 		itemFound := false
-		if len(ctx.Data.CharacterCfg.Gambling.Items) > 0 {
-			// Get current item to gamble for
-			currentItem := ctx.Data.CharacterCfg.Gambling.Items[currentItemIndex]
-			itm, found := ctx.Data.Inventory.Find(currentItem, item.LocationVendor)
+		if name, found := session.Next(available); found {
+			itm, found := ctx.Data.Inventory.Find(item.Name(name), item.LocationVendor)
 			if found {
+				goldBeforeBuy = ctx.Data.PlayerUnit.TotalPlayerGold()
 				town.BuyItem(itm, 1)
 				itemBought = itm
 				itemFound = true
@@ -235,12 +254,13 @@ func gambleItems() error {
 
 			ctx.Logger.Debug("Refreshing.. ",
 				slog.Int("Attempt", refreshAttempts),
-				slog.String("Looking For ", string(ctx.Data.CharacterCfg.Gambling.Items[currentItemIndex])))
+				slog.Any("Looking For ", session.Wanted()))
 			RefreshGamblingWindow(ctx)
 			utils.Sleep(500)
 		}
 	}
 }
+
 func RefreshGamblingWindow(ctx *context.Status) {
 	if ctx.Data.LegacyGraphics {
 		ctx.HID.Click(game.LeftButton, ui.GambleRefreshButtonXClassic, ui.GambleRefreshButtonYClassic)
@@ -0,0 +1,155 @@
+package action
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/merc"
+	"github.com/hectorgimenez/koolo/internal/town"
+	"github.com/hectorgimenez/koolo/internal/ui"
+	"github.com/hectorgimenez/koolo/internal/utils"
+	"github.com/lxn/win"
+)
+
+// RehireMerc hires a new Act 2 mercenary when the dead one is too expensive to revive, equipped items are lost.
+// ReviveMerc should be called before, so the merc is only re-hired when the revive was skipped.
+func RehireMerc() error {
+	ctx := context.Get()
+	ctx.SetLastAction("RehireMerc")
+
+	if !ctx.CharacterCfg.Character.UseMerc || ctx.Data.MercHPPercent() > 0 {
+		return nil
+	}
+
+	goldBefore := ctx.Data.PlayerUnit.TotalPlayerGold()
+	if mercPolicy().Decide(goldBefore, ctx.HealthManager.Merc().ReviveCost()) != merc.DecisionRehire {
+		return nil
+	}
+
+	ctx.Logger.Info("Merc is too expensive to revive, hiring a new one...")
+	if ctx.Data.PlayerUnit.Area != area.LutGholein {
+		if err := WayPoint(area.LutGholein); err != nil {
+			return err
+		}
+	}
+
+	// Resurrect option is shown before Hire while the merc is dead
+	if err := hireFirstMerc(win.VK_HOME, win.VK_DOWN, win.VK_DOWN, win.VK_RETURN); err != nil {
+		return err
+	}
+
+	ctx.RefreshGameData()
+	if ctx.Data.MercHPPercent() <= 0 {
+		return fmt.Errorf("merc could not be hired")
+	}
+
+	cost := goldBefore - ctx.Data.PlayerUnit.TotalPlayerGold()
+	event.Send(event.MercRevived(event.Text(ctx.Name, fmt.Sprintf("New mercenary hired for %d gold", cost)), cost, true))
+
+	return nil
+}
+
+func hireFirstMerc(keys ...byte) error {
+	ctx := context.Get()
+
+	err := InteractNPC(town.GetTownByArea(ctx.Data.PlayerUnit.Area).MercContractorNPC())
+	if err != nil {
+		return err
+	}
+	ctx.HID.KeySequence(keys...)
+	utils.Sleep(2000)
+	ctx.HID.Click(game.LeftButton, ui.FirstMercFromContractorListX, ui.FirstMercFromContractorListY)
+	utils.Sleep(500)
+	ctx.HID.Click(game.LeftButton, ui.FirstMercFromContractorListX, ui.FirstMercFromContractorListY)
+	utils.Sleep(500)
+
+	return step.CloseAllMenus()
+}
+
+// EquipMerc moves the stash items matching the merc.nip rules to the mercenary, see merc.Upgrades
+func EquipMerc() error {
+	ctx := context.Get()
+	ctx.SetLastAction("EquipMerc")
+
+	cfg := ctx.CharacterCfg
+	if !cfg.Character.UseMerc || !cfg.Character.Merc.EquipFromStash || len(cfg.Runtime.MercRules) == 0 || ctx.Data.MercHPPercent() <= 0 {
+		return nil
+	}
+
+	upgrades := merc.Upgrades(
+		ctx.Data.Inventory.ByLocation(item.LocationStash, item.LocationSharedStash),
+		ctx.Data.Inventory.ByLocation(item.LocationMercenary),
+		func(i data.Item) bool {
+			_, result := cfg.Runtime.MercRules.EvaluateAll(i)
+			return result == nip.RuleResultFullMatch
+		},
+	)
+	if len(upgrades) == 0 {
+		return nil
+	}
+
+	if err := OpenStash(); err != nil {
+		return err
+	}
+	if err := TakeItemsFromStash(upgrades); err != nil {
+		return err
+	}
+	if err := step.CloseAllMenus(); err != nil {
+		return err
+	}
+
+	ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.Inventory)
+	utils.Sleep(300)
+
+	// Merc portrait is used as drop target, it's hidden again after the run starts if configured
+	ctx.RefreshGameData()
+	if !ctx.Data.OpenMenus.PortraitsShown {
+		ctx.HID.PressKey(ctx.Data.KeyBindings.ShowPortraits.Key1[0])
+		utils.Sleep(300)
+	}
+
+	for _, upgrade := range upgrades {
+		itm, found := ctx.Data.Inventory.FindByID(upgrade.UnitID)
+		if !found || itm.Location.LocationType != item.LocationInventory {
+			ctx.Logger.Warn("Merc item not found in the inventory, skipping", slog.String("item", string(upgrade.Name)))
+			continue
+		}
+
+		pos := ui.GetScreenCoordsForItem(itm)
+		ctx.HID.Click(game.LeftButton, pos.X, pos.Y)
+		utils.Sleep(300)
+		if ctx.Data.LegacyGraphics {
+			ctx.HID.Click(game.LeftButton, ui.MercAvatarPositionXClassic, ui.MercAvatarPositionYClassic)
+		} else {
+			ctx.HID.Click(game.LeftButton, ui.MercAvatarPositionX, ui.MercAvatarPositionY)
+		}
+		utils.Sleep(500)
+
+		// The replaced item (or the new one when requirements are not met) is left on the cursor
+		ctx.RefreshGameData()
+		if len(ctx.Data.Inventory.ByLocation(item.LocationCursor)) > 0 {
+			ctx.HID.Click(game.LeftButton, pos.X, pos.Y)
+			utils.Sleep(300)
+			ctx.RefreshGameData()
+		}
+
+		if equipped, found := ctx.Data.Inventory.FindByID(upgrade.UnitID); found && equipped.Location.LocationType == item.LocationMercenary {
+			ctx.Logger.Info("Item equipped on the mercenary", slog.String("item", string(equipped.Name)))
+			event.Send(event.MercEquipped(event.Text(ctx.Name, fmt.Sprintf("Mercenary equipped %s", equipped.Name)), equipped))
+		}
+	}
+
+	if err := step.CloseAllMenus(); err != nil {
+		return err
+	}
+
+	return Stash(false)
+}
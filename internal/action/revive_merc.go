@@ -1,11 +1,16 @@
 package action
 
 import (
+	"fmt"
+
 	"github.com/hectorgimenez/d2go/pkg/data/area"
 	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
 	"github.com/hectorgimenez/d2go/pkg/data/npc"
 	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+	"github.com/hectorgimenez/koolo/internal/merc"
 	"github.com/hectorgimenez/koolo/internal/town"
+	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/lxn/win"
 )
 
@@ -20,6 +25,14 @@ func ReviveMerc() {
 			return
 		}
 
+		goldBefore := ctx.Data.PlayerUnit.TotalPlayerGold()
+		expectedCost := ctx.HealthManager.Merc().ReviveCost()
+		decision := mercPolicy().Decide(goldBefore, expectedCost)
+		if decision != merc.DecisionRevive {
+			ctx.Logger.Info(fmt.Sprintf("Merc is dead, skipping revive [Decision: %s, Gold: %d, Expected cost: %d]", decision, goldBefore, expectedCost))
+			return
+		}
+
 		ctx.Logger.Info("Merc is dead, let's revive it!")
 
 		mercNPC := town.GetTownByArea(ctx.Data.PlayerUnit.Area).MercContractorNPC()
@@ -30,5 +43,28 @@ func ReviveMerc() {
 		} else {
 			ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN, win.VK_ESCAPE)
 		}
+
+		utils.Sleep(500)
+		ctx.RefreshGameData()
+		if ctx.Data.MercHPPercent() <= 0 {
+			ctx.Logger.Warn("Merc revive failed")
+			return
+		}
+
+		cost := goldBefore - ctx.Data.PlayerUnit.TotalPlayerGold()
+		if cost > 0 {
+			ctx.HealthManager.Merc().SetReviveCost(cost)
+		}
+		event.Send(event.MercRevived(event.Text(ctx.Name, fmt.Sprintf("Mercenary revived for %d gold", cost)), cost, false))
+	}
+}
+
+func mercPolicy() merc.Policy {
+	ctx := context.Get()
+
+	return merc.Policy{
+		MaxReviveCost: ctx.CharacterCfg.Character.Merc.MaxReviveCost,
+		GoldFloor:     ctx.CharacterCfg.Character.Merc.GoldFloor,
+		Rehire:        ctx.CharacterCfg.Character.Merc.Rehire,
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/object"
 	"github.com/hectorgimenez/d2go/pkg/nip"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
@@ -231,6 +232,11 @@ func shouldStashIt(i data.Item, firstRun bool) (bool, string, string) {
 	if res == nip.RuleResultFullMatch {
 		return true, rule.RawLine, rule.Filename + ":" + strconv.Itoa(rule.LineNumber)
 	}
+
+	// Items bought by the shopping run
+	if rule, res = ctx.CharacterCfg.Runtime.ShoppingRules.EvaluateAll(i); res == nip.RuleResultFullMatch {
+		return true, rule.RawLine, rule.Filename + ":" + strconv.Itoa(rule.LineNumber)
+	}
 	return false, "", ""
 }
 
@@ -258,8 +264,8 @@ func shouldKeepRecipeItem(i data.Item) bool {
 	recipeMatch := false
 
 	// Check if the item is part of a recipe and if that recipe is enabled
-	for _, recipe := range Recipes {
-		if slices.Contains(recipe.Items, string(i.Name)) && slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, recipe.Name) {
+	for _, recipe := range config.Recipes {
+		if recipe.Uses(string(i.Name)) && slices.Contains(ctx.CharacterCfg.CubeRecipes.EnabledRecipes, recipe.Name) {
 			recipeMatch = true
 			break
 		}
@@ -17,6 +17,9 @@ import (
 
 const attackCycleDuration = 120 * time.Millisecond
 
+// Maximum time waiting for Iron Maiden to expire before giving up the target, monsters may keep recasting it
+const ironMaidenMaxWait = 3 * time.Second
+
 var (
 	statesMutex   sync.RWMutex
 	monsterStates = make(map[data.UnitID]*attackState)
@@ -45,6 +48,14 @@ type attackState struct {
 	lastHealthCheckTime    time.Time
 	failedAttemptStartTime time.Time
 	position               data.Position
+
+	// Telemetry, see attack_telemetry.go
+	monsterType string
+	skill       string
+	startedAt   time.Time
+	lastCastAt  time.Time
+	initialLife int
+	casts       int
 }
 
 // Distance configures attack to follow enemy within specified range
@@ -149,6 +160,11 @@ func isValidEnemy(monster data.Monster, ctx *context.Status) bool {
 	return true
 }
 
+// Close-range attacks are considered melee, same as ensureEnemyIsInRange does
+func isMelee(settings attackSettings) bool {
+	return settings.maxDistance <= 3
+}
+
 // Cleanup function to ensure proper state on exit
 func keyCleanup(ctx *context.Status) {
 	ctx.HID.KeyUp(ctx.Data.KeyBindings.StandStill)
@@ -161,6 +177,8 @@ func attack(settings attackSettings) error {
 
 	numOfAttacksRemaining := settings.numOfAttacks
 	lastRunAt := time.Time{}
+	cursedSince := time.Time{}
+	FlushAttackTelemetry(false)
 
 	for {
 		ctx.PauseIfNotPriority()
@@ -171,6 +189,9 @@ func attack(settings attackSettings) error {
 
 		monster, found := ctx.Data.Monsters.FindByID(settings.target)
 		if !found || !isValidEnemy(monster, ctx) {
+			if found {
+				finishAttack(ctx, monster, monster.Stats[stat.Life] <= 0)
+			}
 			return nil // Target is not valid, we don't have anything to attack
 		}
 
@@ -179,6 +200,29 @@ func attack(settings attackSettings) error {
 			return nil // Enemy is out of range and followEnemy is disabled, we cannot attack
 		}
 
+		// Don't attack into Conviction packs if configured, fragile builds die fast there
+		if ctx.CharacterCfg.Curses.AvoidTarget(monster, ctx.Data.Monsters.Enemies()) {
+			ctx.Logger.Debug("Skipping monster inside a Conviction aura")
+			finishAttack(ctx, monster, false)
+			return nil
+		}
+
+		// Melee attacks under Iron Maiden would return the damage to us, wait for the curse to expire. The target is
+		// abandoned if it takes too long, so the caller can move away or cure it
+		if isMelee(settings) && !ctx.CharacterCfg.Curses.CanMelee(ctx.Data.PlayerUnit.States) {
+			if cursedSince.IsZero() {
+				cursedSince = time.Now()
+			}
+			if time.Since(cursedSince) > ironMaidenMaxWait {
+				ctx.Logger.Debug("Iron Maiden didn't expire, abandoning target")
+				finishAttack(ctx, monster, false)
+				return nil
+			}
+			time.Sleep(attackCycleDuration)
+			continue
+		}
+		cursedSince = time.Time{}
+
 		// Check if we need to reposition if we aren't doing any damage (prevent attacking through doors etc.)
 		_, state := checkMonsterDamage(monster)
 		needsRepositioning := !state.failedAttemptStartTime.IsZero() &&
@@ -187,6 +231,7 @@ func attack(settings attackSettings) error {
 		// Be sure we stay in range of the enemy
 		err := ensureEnemyIsInRange(monster, settings.maxDistance, settings.minDistance, needsRepositioning)
 		if err != nil {
+			finishAttack(ctx, monster, false)
 			return fmt.Errorf("enemy is out of range and cannot be reached: %w", err)
 		}
 
@@ -200,7 +245,9 @@ func attack(settings attackSettings) error {
 			continue
 		}
 
-		performAttack(ctx, settings, monster.Position.X, monster.Position.Y)
+		if performAttack(ctx, settings, monster.Position.X, monster.Position.Y) {
+			recordCast(monster, settings)
+		}
 
 		lastRunAt = time.Now()
 		numOfAttacksRemaining--
@@ -212,6 +259,8 @@ func burstAttack(settings attackSettings) error {
 	ctx.SetLastStep("BurstAttack")
 	defer keyCleanup(ctx) // cleanup possible pressed keys/buttons
 
+	FlushAttackTelemetry(false)
+
 	monster, found := ctx.Data.Monsters.FindByID(settings.target)
 	if !found || !isValidEnemy(monster, ctx) {
 		return nil // Target is not valid, we don't have anything to attack
@@ -234,7 +283,7 @@ func burstAttack(settings attackSettings) error {
 		target := data.Monster{}
 		for _, monster = range ctx.Data.Monsters.Enemies() {
 			distance := ctx.PathFinder.DistanceFromMe(monster.Position)
-			if isValidEnemy(monster, ctx) && distance <= settings.maxDistance {
+			if isValidEnemy(monster, ctx) && distance <= settings.maxDistance && !ctx.CharacterCfg.Curses.AvoidTarget(monster, ctx.Data.Monsters.Enemies()) {
 				target = monster
 				break
 			}
@@ -253,19 +302,23 @@ func burstAttack(settings attackSettings) error {
 		if !ctx.PathFinder.LineOfSight(ctx.Data.PlayerUnit.Position, target.Position) || needsRepositioning {
 			err = ensureEnemyIsInRange(target, settings.maxDistance, settings.minDistance, needsRepositioning)
 			if err != nil {
+				finishAttack(ctx, target, false)
 				return fmt.Errorf("enemy is out of range and cannot be reached: %w", err)
 			}
 			continue
 		}
 
-		performAttack(ctx, settings, target.Position.X, target.Position.Y)
+		if performAttack(ctx, settings, target.Position.X, target.Position.Y) {
+			recordCast(target, settings)
+		}
 	}
 }
 
-func performAttack(ctx *context.Status, settings attackSettings, x, y int) {
+// performAttack returns false when the attack was skipped
+func performAttack(ctx *context.Status, settings attackSettings, x, y int) bool {
 	monsterPos := data.Position{X: x, Y: y}
 	if !ctx.PathFinder.LineOfSight(ctx.Data.PlayerUnit.Position, monsterPos) {
-		return // Skip attack if no line of sight
+		return false // Skip attack if no line of sight
 	}
 
 	// Ensure we have the skill selected
@@ -288,6 +341,8 @@ func performAttack(ctx *context.Status, settings attackSettings, x, y int) {
 	if settings.shouldStandStill {
 		ctx.HID.KeyUp(ctx.Data.KeyBindings.StandStill)
 	}
+
+	return true
 }
 
 func ensureEnemyIsInRange(monster data.Monster, maxDistance, minDistance int, needsRepositioning bool) error {
@@ -352,10 +407,8 @@ func ensureEnemyIsInRange(monster data.Monster, maxDistance, minDistance int, ne
 	return nil
 }
 
-func checkMonsterDamage(monster data.Monster) (bool, *attackState) {
-	statesMutex.Lock()
-	defer statesMutex.Unlock()
-
+// stateFor returns the attack state for the monster, statesMutex must be held by the caller
+func stateFor(monster data.Monster) *attackState {
 	state, exists := monsterStates[monster.UnitID]
 	if !exists {
 		state = &attackState{
@@ -366,6 +419,15 @@ func checkMonsterDamage(monster data.Monster) (bool, *attackState) {
 		monsterStates[monster.UnitID] = state
 	}
 
+	return state
+}
+
+func checkMonsterDamage(monster data.Monster) (bool, *attackState) {
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+
+	state := stateFor(monster)
+
 	didDamage := false
 	currentHealth := monster.Stats[stat.Life]
 
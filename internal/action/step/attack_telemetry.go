@@ -0,0 +1,112 @@
+package step
+
+import (
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/combat"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// Monsters not attacked for this long are considered abandoned, the kill sequence moved to another target
+const abandonedAttackTimeout = 10 * time.Second
+
+// Monster names are not mapped in d2go, so telemetry is grouped by monster type
+func monsterType(monster data.Monster) string {
+	if monster.Type == data.MonsterTypeNone || monster.Type == "" {
+		return "Normal"
+	}
+
+	return string(monster.Type)
+}
+
+func skillName(settings attackSettings) string {
+	if settings.primaryAttack {
+		return "Primary attack"
+	}
+
+	if name, found := skill.SkillNames[settings.skill]; found {
+		return name
+	}
+
+	return "Unknown"
+}
+
+// recordCast counts a cast against the monster, the attack starts being measured with the first one
+func recordCast(monster data.Monster, settings attackSettings) {
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+
+	state := stateFor(monster)
+	if state.startedAt.IsZero() {
+		state.startedAt = time.Now()
+		state.initialLife = monster.Stats[stat.Life]
+		state.monsterType = monsterType(monster)
+	}
+	state.skill = skillName(settings)
+	state.casts++
+	state.lastCastAt = time.Now()
+}
+
+// finishAttack reports the telemetry of the attack against the monster, it's a no-op if we didn't cast anything on it
+func finishAttack(ctx *context.Status, monster data.Monster, killed bool) {
+	statesMutex.Lock()
+	state, found := monsterStates[monster.UnitID]
+	if !found || state.casts == 0 {
+		statesMutex.Unlock()
+		return
+	}
+	delete(monsterStates, monster.UnitID)
+	statesMutex.Unlock()
+
+	sendAttack(ctx, state, monster.Stats[stat.Life], killed, time.Since(state.startedAt))
+}
+
+// FlushAttackTelemetry reports the attacks still in progress, monsters that died after the last attack step are
+// reported as killed and the ones not attacked for a while as abandoned. When force is set, all of them are reported.
+func FlushAttackTelemetry(force bool) {
+	ctx := context.Get()
+
+	finished := make(map[*attackState]data.Monster)
+	statesMutex.Lock()
+	for id, state := range monsterStates {
+		if state.casts == 0 {
+			continue
+		}
+
+		monster, found := ctx.Data.Monsters.FindByID(id)
+		if !found || monster.Stats[stat.Life] <= 0 || force || time.Since(state.lastCastAt) > abandonedAttackTimeout {
+			finished[state] = monster
+			delete(monsterStates, id)
+		}
+	}
+	statesMutex.Unlock()
+
+	for state, monster := range finished {
+		life, killed := state.lastHealth, false
+		if monster.UnitID != 0 {
+			life = monster.Stats[stat.Life]
+			killed = life <= 0
+		}
+		sendAttack(ctx, state, life, killed, state.lastCastAt.Sub(state.startedAt)+ctx.Data.PlayerCastDuration())
+	}
+}
+
+func sendAttack(ctx *context.Status, state *attackState, life int, killed bool, duration time.Duration) {
+	damage := state.initialLife - life
+	if killed {
+		damage = state.initialLife
+	}
+
+	event.Send(event.MonsterAttacked(event.Text(ctx.Name, ""), combat.Attack{
+		Monster:  state.monsterType,
+		Skill:    state.skill,
+		Casts:    state.casts,
+		Duration: duration,
+		Damage:   max(damage, 0),
+		Killed:   killed,
+	}))
+}
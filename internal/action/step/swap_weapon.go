@@ -1,41 +1,46 @@
 package step
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/koolo/internal/character/weapon"
 	"github.com/hectorgimenez/koolo/internal/context"
 )
 
+const maxSwapAttempts = 5
+
 func SwapToMainWeapon() error {
-	return swapWeapon(false)
+	return SwapToSlot(context.Get().CharacterCfg.Character.WeaponSets.Main())
 }
 
 func SwapToCTA() error {
-	return swapWeapon(true)
+	return SwapToSet(weapon.CTA)
 }
 
-func swapWeapon(toCTA bool) error {
-	lastRun := time.Time{}
+// SwapToSet swaps to the weapon slot holding the given gear
+func SwapToSet(h weapon.Holding) error {
+	return SwapToSlot(context.Get().CharacterCfg.Character.WeaponSets.SlotFor(h))
+}
 
+// SwapToSlot swaps weapons until the given slot is active, Default slot is resolved to the main one
+func SwapToSlot(slot weapon.Slot) error {
 	ctx := context.Get()
-	ctx.SetLastStep("SwapToCTA")
+	ctx.SetLastStep("SwapToSlot")
 
-	for {
+	slot = ctx.CharacterCfg.Character.WeaponSets.Resolve(slot)
+	for attempt := 0; attempt < maxSwapAttempts; attempt++ {
 		// Pause the execution if the priority is not the same as the execution priority
 		ctx.PauseIfNotPriority()
 
-		if time.Since(lastRun) < time.Millisecond*500 {
-			continue
-		}
-
-		_, found := ctx.Data.PlayerUnit.Skills[skill.BattleOrders]
-		if (toCTA && found) || (!toCTA && !found) {
+		if weapon.FromActive(ctx.Data.ActiveWeaponSlot) == slot {
 			return nil
 		}
 
 		ctx.HID.PressKeyBinding(ctx.Data.KeyBindings.SwapWeapons)
-
-		lastRun = time.Now()
+		time.Sleep(500 * time.Millisecond)
+		ctx.RefreshGameData()
 	}
+
+	return fmt.Errorf("could not swap to weapon slot %s", slot)
 }
@@ -61,6 +61,8 @@ func PreRun(firstRun bool) error {
 	HealAtNPC()
 	ReviveMerc()
 	HireMerc()
+	RehireMerc()
+	EquipMerc()
 
 	return Repair()
 }
@@ -54,6 +54,7 @@ func VendorRefill(forceRefill, sellJunk bool) error {
 	ctx.Logger.Info("Checking for consumables to buy...")
 	SwitchStashTab(4) // Assuming consumables are on this tab
 	town.BuyConsumables(forceRefill)
+	storeOverflowPotions()
 
 	return step.CloseAllMenus()
 }
@@ -0,0 +1,302 @@
+package belt
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+const (
+	Columns = 4
+
+	inventoryWidth  = 10
+	inventoryHeight = 4
+)
+
+// Tiers contains the potion names for every type, sorted from worst to best
+var Tiers = map[data.PotionType][]item.Name{
+	data.HealingPotion:      {"MinorHealingPotion", "LightHealingPotion", "HealingPotion", "GreaterHealingPotion", "SuperHealingPotion"},
+	data.ManaPotion:         {"MinorManaPotion", "LightManaPotion", "ManaPotion", "GreaterManaPotion", "SuperManaPotion"},
+	data.RejuvenationPotion: {"RejuvenationPotion", "FullRejuvenationPotion"},
+}
+
+// approximated vendor prices per tier, only used to step down tiers when there is not enough gold
+var prices = map[data.PotionType][]int{
+	data.HealingPotion:      {30, 75, 150, 300, 450},
+	data.ManaPotion:         {30, 75, 150, 300, 450},
+	data.RejuvenationPotion: {750, 1500},
+}
+
+// maxTierByDifficulty limits the tier bought per difficulty, in Normal greater potions are expensive for low level
+// characters so mana potions are kept cheap
+var maxTierByDifficulty = map[difficulty.Difficulty]map[data.PotionType]int{
+	difficulty.Normal: {data.HealingPotion: 4, data.ManaPotion: 2, data.RejuvenationPotion: 1},
+}
+
+type Config struct {
+	// Columns is the potion type per belt column: healing, mana or rejuvenation
+	Columns [Columns]string
+	// Overflow is the amount of potions per type kept in locked inventory slots to refill the belt during runs
+	Overflow      map[data.PotionType]int
+	InventoryLock [][]int
+	// RefillThreshold is the percentage of the belt target amount below which potions should be bought
+	RefillThreshold int
+}
+
+type Column struct {
+	Type data.PotionType
+	// Potions indexed by row, empty rows contain a zero value item
+	Potions []data.Item
+	// Misplaced are the potions in the column with a different type than the configured one
+	Misplaced int
+}
+
+func (c Column) Count() int {
+	count := 0
+	for _, p := range c.Potions {
+		if p.Name != "" && PotionType(p.Name) == c.Type {
+			count++
+		}
+	}
+
+	return count
+}
+
+type Plan struct {
+	Rows    int
+	Columns [Columns]Column
+	// Overflow are the potions stored in locked inventory slots
+	Overflow map[data.PotionType][]data.Item
+	// Misplaced are the potions in unlocked inventory slots, they can be moved to the free overflow slots
+	Misplaced []data.Item
+	// FreeOverflowSlots are the empty locked inventory cells where potions can be stored
+	FreeOverflowSlots []data.Position
+
+	cfg Config
+}
+
+// NewPlan builds the belt plan for the given inventory, it doesn't modify anything
+func NewPlan(inv data.Inventory, cfg Config) Plan {
+	if cfg.RefillThreshold == 0 {
+		cfg.RefillThreshold = 75
+	}
+
+	p := Plan{
+		Rows:     inv.Belt.Rows(),
+		Overflow: make(map[data.PotionType][]data.Item),
+		cfg:      cfg,
+	}
+
+	for c := 0; c < Columns; c++ {
+		p.Columns[c] = Column{
+			Type:    columnType(cfg.Columns[c]),
+			Potions: make([]data.Item, p.Rows),
+		}
+	}
+
+	for _, itm := range inv.Belt.Items {
+		col, row := itm.Position.X, itm.Position.Y
+		if col < 0 || col >= Columns || row < 0 || row >= p.Rows {
+			continue
+		}
+		p.Columns[col].Potions[row] = itm
+		if PotionType(itm.Name) != p.Columns[col].Type {
+			p.Columns[col].Misplaced++
+		}
+	}
+
+	occupied := make(map[data.Position]bool)
+	for _, itm := range inv.AllItems {
+		if itm.Location.LocationType != item.LocationInventory {
+			continue
+		}
+
+		w, h := itm.Desc().InventoryWidth, itm.Desc().InventoryHeight
+		for x := 0; x < max(w, 1); x++ {
+			for y := 0; y < max(h, 1); y++ {
+				occupied[data.Position{X: itm.Position.X + x, Y: itm.Position.Y + y}] = true
+			}
+		}
+
+		pt := PotionType(itm.Name)
+		if pt == "" {
+			continue
+		}
+		if p.locked(itm.Position) {
+			p.Overflow[pt] = append(p.Overflow[pt], itm)
+		} else {
+			p.Misplaced = append(p.Misplaced, itm)
+		}
+	}
+
+	for y := 0; y < inventoryHeight; y++ {
+		for x := 0; x < inventoryWidth; x++ {
+			pos := data.Position{X: x, Y: y}
+			if p.locked(pos) && !occupied[pos] {
+				p.FreeOverflowSlots = append(p.FreeOverflowSlots, pos)
+			}
+		}
+	}
+
+	return p
+}
+
+// Target returns the amount of potions of the given type that should be in the belt
+func (p Plan) Target(pt data.PotionType) int {
+	total := 0
+	for _, c := range p.Columns {
+		if c.Type == pt {
+			total += p.Rows
+		}
+	}
+
+	return total
+}
+
+func (p Plan) Current(pt data.PotionType) int {
+	total := 0
+	for _, c := range p.Columns {
+		if c.Type == pt {
+			total += c.Count()
+		}
+	}
+
+	return total
+}
+
+// Missing returns the amount of potions required to fill the belt columns of the given type
+func (p Plan) Missing(pt data.PotionType) int {
+	return max(p.Target(pt)-p.Current(pt), 0)
+}
+
+// OverflowMissing returns the amount of potions required to fill the overflow stack, limited by the free slots
+func (p Plan) OverflowMissing(pt data.PotionType) int {
+	missing := max(p.cfg.Overflow[pt]-len(p.Overflow[pt]), 0)
+	free := len(p.FreeOverflowSlots)
+	for t, items := range p.Overflow {
+		if t != pt {
+			free -= max(p.cfg.Overflow[t]-len(items), 0)
+		}
+	}
+
+	return max(min(missing, free), 0)
+}
+
+// ToBuy returns the amount of potions to buy, belt first and then overflow stack
+func (p Plan) ToBuy(pt data.PotionType) int {
+	return p.Missing(pt) + p.OverflowMissing(pt)
+}
+
+// ShouldBuy returns true when healing or mana potions in the belt are below the refill threshold or the
+// overflow stack is not complete, rejuvenation potions are ignored as usually they are not sold in early acts
+func (p Plan) ShouldBuy() bool {
+	for _, pt := range []data.PotionType{data.HealingPotion, data.ManaPotion} {
+		if p.Current(pt) < p.Target(pt)*p.cfg.RefillThreshold/100 || p.OverflowMissing(pt) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BeltRefill returns the overflow potions that can be moved to the belt right now, best tiers first
+func (p Plan) BeltRefill() []data.Item {
+	refill := make([]data.Item, 0)
+	for _, pt := range []data.PotionType{data.HealingPotion, data.ManaPotion, data.RejuvenationPotion} {
+		potions := sortByTier(p.Overflow[pt])
+		refill = append(refill, potions[:min(p.Missing(pt), len(potions))]...)
+	}
+
+	return refill
+}
+
+func (p Plan) locked(pos data.Position) bool {
+	if pos.Y < 0 || pos.Y >= len(p.cfg.InventoryLock) || pos.X < 0 || pos.X >= len(p.cfg.InventoryLock[pos.Y]) {
+		return false
+	}
+
+	return p.cfg.InventoryLock[pos.Y][pos.X] == 0
+}
+
+// BestAffordable returns the best potion available at the vendor for the given difficulty, stepping down tiers
+// when the gold is not enough to buy all of them
+func BestAffordable(pt data.PotionType, d difficulty.Difficulty, available []item.Name, count, gold int) (item.Name, bool) {
+	maxTier := len(Tiers[pt]) - 1
+	if limit, found := maxTierByDifficulty[d][pt]; found {
+		maxTier = limit
+	}
+
+	var fallback item.Name
+	for tier := maxTier; tier >= 0; tier-- {
+		name := Tiers[pt][tier]
+		if !containsName(available, name) {
+			continue
+		}
+		if prices[pt][tier]*max(count, 1) <= gold {
+			return name, true
+		}
+		fallback = name
+	}
+
+	// Not enough gold for all of them, cheapest one will be bought while gold lasts
+	return fallback, fallback != ""
+}
+
+// Tier returns the tier of the potion, -1 if it's not a potion
+func Tier(name item.Name) int {
+	for _, names := range Tiers {
+		for i, n := range names {
+			if strings.EqualFold(string(n), string(name)) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// PotionType returns the type of the potion, empty if it's not a potion
+func PotionType(name item.Name) data.PotionType {
+	for pt, names := range Tiers {
+		if containsName(names, name) {
+			return pt
+		}
+	}
+
+	return ""
+}
+
+func columnType(cfg string) data.PotionType {
+	switch strings.ToLower(cfg) {
+	case "healing":
+		return data.HealingPotion
+	case "mana":
+		return data.ManaPotion
+	case "rejuvenation":
+		return data.RejuvenationPotion
+	}
+
+	return ""
+}
+
+func sortByTier(potions []data.Item) []data.Item {
+	sorted := slices.Clone(potions)
+	slices.SortStableFunc(sorted, func(a, b data.Item) int {
+		return Tier(b.Name) - Tier(a.Name)
+	})
+
+	return sorted
+}
+
+func containsName(names []item.Name, name item.Name) bool {
+	for _, n := range names {
+		if strings.EqualFold(string(n), string(name)) {
+			return true
+		}
+	}
+
+	return false
+}
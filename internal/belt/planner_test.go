@@ -0,0 +1,155 @@
+package belt
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+var testLock = [][]int{
+	{1, 1, 1, 1, 1, 1, 1, 1, 0, 0},
+	{1, 1, 1, 1, 1, 1, 1, 1, 0, 0},
+	{1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+	{1, 1, 1, 1, 1, 1, 1, 1, 1, 1},
+}
+
+func potion(name string, location item.LocationType, x, y int) data.Item {
+	return data.Item{
+		ID:       item.GetIDByName(name),
+		Name:     item.Name(name),
+		Location: item.Location{LocationType: location},
+		Position: data.Position{X: x, Y: y},
+	}
+}
+
+func testConfig() Config {
+	return Config{
+		Columns:       [Columns]string{"healing", "healing", "mana", "rejuvenation"},
+		InventoryLock: testLock,
+		Overflow:      map[data.PotionType]int{data.HealingPotion: 2, data.ManaPotion: 1},
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	inv := data.Inventory{
+		Belt: data.Belt{
+			Name: "HeavyBelt",
+			Items: []data.Item{
+				potion("SuperHealingPotion", item.LocationBelt, 0, 0),
+				potion("SuperHealingPotion", item.LocationBelt, 0, 1),
+				potion("SuperManaPotion", item.LocationBelt, 1, 0),
+				potion("SuperManaPotion", item.LocationBelt, 2, 0),
+				potion("FullRejuvenationPotion", item.LocationBelt, 3, 0),
+			},
+		},
+		AllItems: []data.Item{
+			potion("GreaterHealingPotion", item.LocationInventory, 8, 0),
+			potion("SuperHealingPotion", item.LocationInventory, 9, 0),
+			potion("SuperManaPotion", item.LocationInventory, 3, 2),
+		},
+	}
+
+	p := NewPlan(inv, testConfig())
+
+	if p.Rows != 3 {
+		t.Fatalf("Expected 3 rows, got %d", p.Rows)
+	}
+	if p.Columns[1].Misplaced != 1 {
+		t.Errorf("Expected mana potion in healing column to be misplaced")
+	}
+	if got := p.Missing(data.HealingPotion); got != 4 {
+		t.Errorf("Expected 4 missing healing potions, got %d", got)
+	}
+	if got := p.Missing(data.ManaPotion); got != 2 {
+		t.Errorf("Expected 2 missing mana potions, got %d", got)
+	}
+	if got := p.Missing(data.RejuvenationPotion); got != 2 {
+		t.Errorf("Expected 2 missing rejuvenation potions, got %d", got)
+	}
+	if len(p.Overflow[data.HealingPotion]) != 2 || len(p.Misplaced) != 1 {
+		t.Errorf("Expected 2 healing potions in overflow and 1 misplaced, got %d and %d", len(p.Overflow[data.HealingPotion]), len(p.Misplaced))
+	}
+	if len(p.FreeOverflowSlots) != 2 {
+		t.Errorf("Expected 2 free overflow slots, got %v", p.FreeOverflowSlots)
+	}
+	if got := p.OverflowMissing(data.ManaPotion); got != 1 {
+		t.Errorf("Expected 1 mana potion missing in overflow, got %d", got)
+	}
+	if got := p.ToBuy(data.HealingPotion); got != 4 {
+		t.Errorf("Expected 4 healing potions to buy, got %d", got)
+	}
+	if !p.ShouldBuy() {
+		t.Errorf("Expected potions to be bought")
+	}
+
+	refill := p.BeltRefill()
+	if len(refill) != 2 || refill[0].Name != "SuperHealingPotion" {
+		t.Errorf("Expected overflow potions to refill the belt best tier first, got %v", refill)
+	}
+}
+
+func TestShouldBuyThreshold(t *testing.T) {
+	items := make([]data.Item, 0)
+	for col := 0; col < Columns; col++ {
+		for row := 0; row < 4; row++ {
+			name := "SuperHealingPotion"
+			if col >= 2 {
+				name = "SuperManaPotion"
+			}
+			items = append(items, potion(name, item.LocationBelt, col, row))
+		}
+	}
+	cfg := Config{Columns: [Columns]string{"healing", "healing", "mana", "mana"}, InventoryLock: testLock}
+
+	full := data.Inventory{Belt: data.Belt{Name: "VampirefangBelt", Items: items}}
+	if NewPlan(full, cfg).ShouldBuy() {
+		t.Errorf("Expected full belt to not require potions")
+	}
+
+	// 6 of 8 healing potions is still 75%
+	partial := data.Inventory{Belt: data.Belt{Name: "VampirefangBelt", Items: items[2:]}}
+	if NewPlan(partial, cfg).ShouldBuy() {
+		t.Errorf("Expected belt at threshold to not require potions")
+	}
+
+	partial.Belt.Items = items[3:]
+	if !NewPlan(partial, cfg).ShouldBuy() {
+		t.Errorf("Expected belt below threshold to require potions")
+	}
+
+	cfg.RefillThreshold = 50
+	if NewPlan(partial, cfg).ShouldBuy() {
+		t.Errorf("Expected custom threshold to be used")
+	}
+}
+
+func TestBestAffordable(t *testing.T) {
+	available := []item.Name{"LightManaPotion", "ManaPotion", "GreaterManaPotion", "SuperManaPotion", "SuperHealingPotion"}
+
+	tests := []struct {
+		name       string
+		pt         data.PotionType
+		difficulty difficulty.Difficulty
+		count      int
+		gold       int
+		want       item.Name
+		wantFound  bool
+	}{
+		{name: "Best tier", pt: data.ManaPotion, difficulty: difficulty.Hell, count: 5, gold: 100000, want: "SuperManaPotion", wantFound: true},
+		{name: "Capped by difficulty", pt: data.ManaPotion, difficulty: difficulty.Normal, count: 5, gold: 100000, want: "ManaPotion", wantFound: true},
+		{name: "Step down when gold is low", pt: data.ManaPotion, difficulty: difficulty.Hell, count: 5, gold: 800, want: "ManaPotion", wantFound: true},
+		{name: "Cheapest when gold is not enough", pt: data.ManaPotion, difficulty: difficulty.Hell, count: 5, gold: 10, want: "LightManaPotion", wantFound: true},
+		{name: "Not available", pt: data.RejuvenationPotion, difficulty: difficulty.Hell, count: 1, gold: 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := BestAffordable(tt.pt, tt.difficulty, available, tt.count, tt.gold)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("Expected %s (%v), got %s (%v)", tt.want, tt.wantFound, got, found)
+			}
+		})
+	}
+}
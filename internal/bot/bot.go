@@ -8,6 +8,7 @@ import (
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/action/step"
 	botCtx "github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/health"
@@ -16,7 +17,8 @@ import (
 )
 
 type Bot struct {
-	ctx *botCtx.Context
+	ctx       *botCtx.Context
+	remaining []run.Run
 }
 
 func NewBot(ctx *botCtx.Context) *Bot {
@@ -25,13 +27,29 @@ func NewBot(ctx *botCtx.Context) *Bot {
 	}
 }
 func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
+	b.ctx.CurrentGame = botCtx.NewGameHelper() // Reset current game helper structure
+
+	return b.run(ctx, firstRun, runs)
+}
+
+// Resume keeps executing runs in the current game, ex: after recovering from a death
+func (b *Bot) Resume(ctx context.Context, runs []run.Run) error {
+	return b.run(ctx, false, runs)
+}
+
+// RemainingRuns returns the runs not started yet in the last Run or Resume call
+func (b *Bot) RemainingRuns() []run.Run {
+	return b.remaining
+}
+
+func (b *Bot) run(ctx context.Context, firstRun bool, runs []run.Run) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	g, ctx := errgroup.WithContext(ctx)
 
 	gameStartedAt := time.Now()
 	b.ctx.SwitchPriority(botCtx.PriorityNormal) // Restore priority to normal, in case it was stopped in previous game
-	b.ctx.CurrentGame = botCtx.NewGameHelper()  // Reset current game helper structure
+	b.remaining = runs
 
 	err := b.ctx.GameReader.FetchMapData()
 	if err != nil {
@@ -152,10 +170,23 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 					action.ItemPickup(30)
 				}
 				action.BuffIfRequired()
+				if err = action.UseCurePotions(); err != nil {
+					b.ctx.Logger.Warn("Failed drinking cure potion", "error", err)
+				}
 
 				_, healingPotsFound := b.ctx.Data.Inventory.Belt.GetFirstPotion(data.HealingPotion)
 				_, manaPotsFound := b.ctx.Data.Inventory.Belt.GetFirstPotion(data.ManaPotion)
 
+				// Try to use the potions stored in the inventory before going back to town
+				if (!healingPotsFound || !manaPotsFound) && !b.ctx.Data.PlayerUnit.Area.IsTown() && len(b.ctx.BeltManager.Plan().BeltRefill()) > 0 {
+					if err = action.RefillBeltFromOverflow(); err != nil {
+						b.ctx.Logger.Warn("Failed refilling belt from inventory", "error", err)
+					}
+					b.ctx.RefreshGameData()
+					_, healingPotsFound = b.ctx.Data.Inventory.Belt.GetFirstPotion(data.HealingPotion)
+					_, manaPotsFound = b.ctx.Data.Inventory.Belt.GetFirstPotion(data.ManaPotion)
+				}
+
 				// Check if we need to go back to town (no pots or merc died)
 				if (b.ctx.CharacterCfg.BackToTown.NoHpPotions && !healingPotsFound ||
 					b.ctx.CharacterCfg.BackToTown.EquipmentBroken && action.IsEquipmentBroken() ||
@@ -196,11 +227,13 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 		}()
 
 		b.ctx.AttachRoutine(botCtx.PriorityNormal)
-		for _, r := range runs {
+		for i, r := range runs {
 			select {
 			case <-ctx.Done():
 				return nil
 			default:
+				b.remaining = runs[i+1:]
+				b.ctx.CurrentGame.CurrentRun = r.Name()
 				event.Send(event.RunStarted(event.Text(b.ctx.Name, fmt.Sprintf("Starting run: %s", r.Name())), r.Name()))
 				err = action.PreRun(firstRun)
 				if err != nil {
@@ -217,6 +250,8 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 						runFinishReason = event.FinishedChicken
 					case errors.Is(err, health.ErrMercChicken):
 						runFinishReason = event.FinishedMercChicken
+					case errors.Is(err, health.ErrDied) && b.WillRecover(err):
+						runFinishReason = event.FinishedCorpseRun
 					case errors.Is(err, health.ErrDied):
 						runFinishReason = event.FinishedDied
 					default:
@@ -226,6 +261,8 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 					runFinishReason = event.FinishedOK
 				}
 
+				// Attacks still in progress belong to this run
+				step.FlushAttackTelemetry(true)
 				event.Send(event.RunFinished(event.Text(b.ctx.Name, fmt.Sprintf("Finished run: %s", r.Name())), r.Name(), runFinishReason))
 
 				if err != nil {
@@ -244,6 +281,11 @@ func (b *Bot) Run(ctx context.Context, firstRun bool, runs []run.Run) error {
 	return g.Wait()
 }
 
+// WillRecover returns true if the corpse will be recovered after the death instead of finishing the game
+func (b *Bot) WillRecover(err error) bool {
+	return !errors.Is(err, health.ErrCorpseLost) && b.ctx.CharacterCfg.DeathRecovery.ShouldRecover(b.ctx.CurrentGame.Deaths+1)
+}
+
 func (b *Bot) Stop() {
 	b.ctx.SwitchPriority(botCtx.PriorityStop)
 	b.ctx.Detach()
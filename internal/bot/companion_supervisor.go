@@ -66,9 +66,9 @@ package bot
 //				}
 //
 //				if config.Koolo.Discord.EnableGameCreatedMessages {
-//					event.Send(event.GameCreated(event.Text(s.name, "New game created: %s"), gameName, config.Characters[s.name].Companion.GamePassword))
+//					event.Send(event.GameCreated(event.Text(s.name, "New game created: %s"), gameName, config.Characters()[s.name].Companion.GamePassword))
 //				} else {
-//					event.Send(event.GameCreated(event.Text(s.name, ""), gameName, config.Characters[s.name].Companion.GamePassword))
+//					event.Send(event.GameCreated(event.Text(s.name, ""), gameName, config.Characters()[s.name].Companion.GamePassword))
 //				}
 //				err = s.startBot(ctx, s.runFactory.BuildRuns(), firstRun)
 //				if err != nil {
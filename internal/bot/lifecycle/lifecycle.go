@@ -0,0 +1,204 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// State is the supervisor lifecycle state, values are shown as they are in the dashboard
+type State string
+
+const (
+	NotStarted State = "Not Started"
+	Starting   State = "Starting"
+	InGame     State = "In game"
+	Paused     State = "Paused"
+	Stopping   State = "Stopping"
+	Crashed    State = "Crashed"
+	BackingOff State = "Backing off"
+	// Disabled supervisors crashed too many times, they are not restarted until enabled again
+	Disabled State = "Disabled"
+)
+
+var ErrInvalidTransition = errors.New("invalid supervisor state transition")
+
+var transitions = map[State][]State{
+	NotStarted: {Starting},
+	// Going back to NotStarted means the start failed before the supervisor was running
+	Starting:   {InGame, Paused, Stopping, Crashed, NotStarted},
+	InGame:     {Paused, Stopping, Crashed},
+	Paused:     {InGame, Stopping, Crashed},
+	Stopping:   {NotStarted},
+	Crashed:    {BackingOff, Disabled},
+	BackingOff: {Starting, NotStarted},
+	Disabled:   {NotStarted},
+}
+
+// Running returns true for the states with a game client to take care of
+func (s State) Running() bool {
+	return s == Starting || s == InGame || s == Paused || s == Stopping
+}
+
+// Policy decides the restart delays after a crash and when to give up
+type Policy struct {
+	// BaseDelay is the delay for the first crash, doubled on every crash inside the window
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxCrashes inside the Window disable the supervisor
+	MaxCrashes int
+	Window     time.Duration
+}
+
+var DefaultPolicy = Policy{
+	BaseDelay:  5 * time.Second,
+	MaxDelay:   5 * time.Minute,
+	MaxCrashes: 5,
+	Window:     time.Hour,
+}
+
+// Machine holds the lifecycle state of a supervisor, it's thread safe
+type Machine struct {
+	mu      sync.Mutex
+	policy  Policy
+	now     func() time.Time
+	state   State
+	since   time.Time
+	crashes []time.Time
+	// onChange is called on every transition, holding the machine lock. err is only set by Fail
+	onChange func(from, to State, at time.Time, err error)
+}
+
+func New(policy Policy, now func() time.Time) *Machine {
+	return &Machine{
+		policy: policy,
+		now:    now,
+		state:  NotStarted,
+		since:  now(),
+	}
+}
+
+func (m *Machine) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.state
+}
+
+// Since returns the time of the last transition
+func (m *Machine) Since() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.since
+}
+
+// Crashes returns the amount of crashes inside the policy window
+func (m *Machine) Crashes() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneCrashes()
+	return len(m.crashes)
+}
+
+// To moves to the given state, transitioning to the current state is a no-op
+func (m *Machine) To(s State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.to(s)
+}
+
+// Fail moves to the given state after a failed start, the error is sent along with the change. The change is sent
+// even when the state doesn't change, ex: a failed restart stays in Starting.
+func (m *Machine) Fail(s State, err error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == s {
+		if m.onChange != nil {
+			m.onChange(s, s, m.now(), err)
+		}
+		return nil
+	}
+
+	return m.transition(s, err)
+}
+
+// Crash records a crash and moves to BackingOff, returning the delay before restarting. When the crash budget is
+// exhausted the machine moves to Disabled instead and true is returned.
+func (m *Machine) Crash() (time.Duration, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.to(Crashed); err != nil {
+		return 0, false, err
+	}
+
+	m.crashes = append(m.crashes, m.now())
+	m.pruneCrashes()
+
+	if m.policy.MaxCrashes > 0 && len(m.crashes) >= m.policy.MaxCrashes {
+		return 0, true, m.to(Disabled)
+	}
+
+	delay := m.policy.BaseDelay
+	for i := 1; i < len(m.crashes) && delay < m.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if m.policy.MaxDelay > 0 {
+		delay = min(delay, m.policy.MaxDelay)
+	}
+
+	return delay, false, m.to(BackingOff)
+}
+
+// Enable moves a disabled supervisor back to NotStarted, forgetting its crashes
+func (m *Machine) Enable() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state != Disabled {
+		return nil
+	}
+	m.crashes = nil
+
+	return m.to(NotStarted)
+}
+
+func (m *Machine) to(s State) error {
+	if m.state == s {
+		return nil
+	}
+
+	return m.transition(s, nil)
+}
+
+func (m *Machine) transition(s State, err error) error {
+	if !slices.Contains(transitions[m.state], s) {
+		return fmt.Errorf("%w: from %s to %s", ErrInvalidTransition, m.state, s)
+	}
+
+	from := m.state
+	m.state = s
+	m.since = m.now()
+	if m.onChange != nil {
+		m.onChange(from, s, m.since, err)
+	}
+
+	return nil
+}
+
+func (m *Machine) pruneCrashes() {
+	if m.policy.Window <= 0 {
+		return
+	}
+
+	limit := m.now().Add(-m.policy.Window)
+	m.crashes = slices.DeleteFunc(m.crashes, func(t time.Time) bool {
+		return t.Before(limit)
+	})
+}
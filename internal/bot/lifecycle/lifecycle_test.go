@@ -0,0 +1,123 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func TestTransitions(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  []State
+		valid bool
+	}{
+		{name: "start and stop", path: []State{Starting, InGame, Paused, InGame, Stopping, NotStarted}, valid: true},
+		{name: "failed start", path: []State{Starting, NotStarted}, valid: true},
+		{name: "same state is a no-op", path: []State{Starting, InGame, InGame}, valid: true},
+		{name: "stop requested while backing off", path: []State{Starting, Crashed, BackingOff, NotStarted}, valid: true},
+		{name: "in game without starting", path: []State{InGame}},
+		{name: "stopped without stopping", path: []State{Starting, InGame, NotStarted}},
+		{name: "crash when not running", path: []State{Crashed}},
+		{name: "restart a disabled supervisor", path: []State{Starting, Crashed, Disabled, Starting}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := New(DefaultPolicy, time.Now)
+
+			var err error
+			for _, s := range tc.path {
+				if err = m.To(s); err != nil {
+					break
+				}
+			}
+
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidTransition) {
+				t.Fatalf("expected an invalid transition, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCrashBackoff(t *testing.T) {
+	c := &clock{t: time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)}
+	m := New(Policy{BaseDelay: 5 * time.Second, MaxDelay: 15 * time.Second, MaxCrashes: 5, Window: time.Hour}, c.now)
+
+	for _, expected := range []time.Duration{5 * time.Second, 10 * time.Second, 15 * time.Second, 15 * time.Second} {
+		if err := m.To(Starting); err != nil {
+			t.Fatal(err)
+		}
+		delay, disabled, err := m.Crash()
+		if err != nil || disabled {
+			t.Fatalf("unexpected crash result: disabled %t, err %v", disabled, err)
+		}
+		if delay != expected {
+			t.Fatalf("expected a %s delay, got %s", expected, delay)
+		}
+		if m.State() != BackingOff {
+			t.Fatalf("expected %s, got %s", BackingOff, m.State())
+		}
+		c.t = c.t.Add(time.Minute)
+	}
+
+	// Crashes out of the window are forgotten
+	c.t = c.t.Add(time.Hour)
+	m.To(Starting)
+	if delay, _, _ := m.Crash(); delay != 5*time.Second {
+		t.Fatalf("expected the base delay once the window is over, got %s", delay)
+	}
+}
+
+func TestCrashBudget(t *testing.T) {
+	c := &clock{t: time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)}
+	m := New(Policy{BaseDelay: time.Second, MaxCrashes: 3, Window: time.Hour}, c.now)
+
+	for i := 1; i <= 3; i++ {
+		m.To(Starting)
+		m.To(InGame)
+		_, disabled, err := m.Crash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if disabled != (i == 3) {
+			t.Fatalf("crash %d: unexpected disabled %t", i, disabled)
+		}
+		c.t = c.t.Add(10 * time.Minute)
+	}
+
+	if m.State() != Disabled {
+		t.Fatalf("expected %s, got %s", Disabled, m.State())
+	}
+	if err := m.To(Starting); err == nil {
+		t.Fatal("disabled supervisors should not start")
+	}
+
+	if err := m.Enable(); err != nil {
+		t.Fatal(err)
+	}
+	if m.State() != NotStarted || m.Crashes() != 0 {
+		t.Fatalf("expected a clean state after enabling, got %s with %d crashes", m.State(), m.Crashes())
+	}
+}
+
+func TestCrashWhileStopping(t *testing.T) {
+	m := New(DefaultPolicy, time.Now)
+	m.To(Starting)
+	m.To(Stopping)
+
+	if _, _, err := m.Crash(); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("crashes while stopping should be ignored, got %v", err)
+	}
+}
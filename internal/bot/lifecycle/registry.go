@@ -0,0 +1,285 @@
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	ErrAlreadyRunning = errors.New("supervisor is already running")
+	ErrDisabled       = errors.New("supervisor is disabled after crashing too many times")
+	// ErrStopped is returned by Start when the supervisor is stopped while it's being built
+	ErrStopped = errors.New("supervisor stopped while starting")
+)
+
+// Runner is a supervisor as seen by the registry
+type Runner interface {
+	// Run blocks until the supervisor is stopped or fails
+	Run() error
+	Stop()
+}
+
+// Change is a lifecycle state change, sent to the subscribers
+type Change struct {
+	Supervisor string
+	From       State
+	To         State
+	At         time.Time
+	// Err is set when the supervisor failed to start
+	Err error
+}
+
+type entry[R Runner] struct {
+	runner R
+}
+
+// Registry owns the running supervisors and their lifecycle, all methods are safe for concurrent use. Supervisors
+// are built without holding any lock, so slow starts don't block the rest of the supervisors.
+type Registry[R Runner] struct {
+	policy Policy
+	now    func() time.Time
+
+	mu       sync.RWMutex
+	entries  map[string]*entry[R]
+	starting map[string]bool
+	machines map[string]*Machine
+
+	subsMu sync.Mutex
+	subs   map[chan Change]struct{}
+}
+
+func NewRegistry[R Runner](policy Policy, now func() time.Time) *Registry[R] {
+	return &Registry[R]{
+		policy:   policy,
+		now:      now,
+		entries:  make(map[string]*entry[R]),
+		starting: make(map[string]bool),
+		machines: make(map[string]*Machine),
+		subs:     make(map[chan Change]struct{}),
+	}
+}
+
+// Start builds the supervisor and runs it in the background. Only the errors preventing the start, like
+// ErrAlreadyRunning or ErrDisabled, are returned. The build result is sent to the returned channel, failures are also
+// published as a Change with Err set. A failed restart after a crash is left in Starting, so the caller can handle it
+// as another crash.
+func (r *Registry[R]) Start(name string, build func() (R, error)) (<-chan error, error) {
+	r.mu.Lock()
+	if _, found := r.entries[name]; found || r.starting[name] {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyRunning, name)
+	}
+
+	m := r.machine(name)
+	if m.State() == Disabled {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDisabled, name)
+	}
+	restart := m.State() == BackingOff
+	if err := m.To(Starting); err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	r.starting[name] = true
+	r.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		err := r.build(name, m, restart, build)
+		done <- err
+		close(done)
+	}()
+
+	return done, nil
+}
+
+func (r *Registry[R]) build(name string, m *Machine, restart bool, build func() (R, error)) error {
+	runner, err := build()
+
+	r.mu.Lock()
+	delete(r.starting, name)
+	if err != nil {
+		r.mu.Unlock()
+		if !restart || m.State() == Stopping {
+			m.Fail(NotStarted, err)
+		} else {
+			m.Fail(Starting, err)
+		}
+		return err
+	}
+
+	// Stop was called while building
+	if m.State() == Stopping {
+		r.mu.Unlock()
+		runner.Stop()
+		m.To(NotStarted)
+		return fmt.Errorf("%w: %s", ErrStopped, name)
+	}
+
+	e := &entry[R]{runner: runner}
+	r.entries[name] = e
+	r.mu.Unlock()
+
+	go r.run(name, e)
+
+	return nil
+}
+
+func (r *Registry[R]) run(name string, e *entry[R]) {
+	// Errors are handled by the runner, the registry only cares about the supervisor being gone
+	_ = e.runner.Run()
+
+	r.mu.Lock()
+	current, found := r.entries[name]
+	if !found || current != e {
+		// Already stopped or removed after a crash
+		r.mu.Unlock()
+		return
+	}
+	delete(r.entries, name)
+	r.mu.Unlock()
+
+	m := r.Machine(name)
+	m.To(Stopping)
+	m.To(NotStarted)
+}
+
+// Stop stops the supervisor, a pending restart is cancelled and a supervisor being built is stopped once ready
+func (r *Registry[R]) Stop(name string) {
+	r.mu.Lock()
+	m := r.machine(name)
+
+	switch {
+	case m.State() == BackingOff:
+		m.To(NotStarted)
+		r.mu.Unlock()
+		return
+	case r.starting[name]:
+		m.To(Stopping)
+		r.mu.Unlock()
+		return
+	}
+
+	e, found := r.entries[name]
+	if !found {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.entries, name)
+	m.To(Stopping)
+	r.mu.Unlock()
+
+	e.runner.Stop()
+	m.To(NotStarted)
+}
+
+// Crash removes the crashed supervisor and records the crash, see Machine.Crash
+func (r *Registry[R]) Crash(name string) (time.Duration, bool, error) {
+	r.mu.Lock()
+	e, found := r.entries[name]
+	delete(r.entries, name)
+	m := r.machine(name)
+	r.mu.Unlock()
+
+	if found {
+		e.runner.Stop()
+	}
+
+	return m.Crash()
+}
+
+// Enable allows a disabled supervisor to be started again
+func (r *Registry[R]) Enable(name string) error {
+	return r.Machine(name).Enable()
+}
+
+// Get returns the running supervisor
+func (r *Registry[R]) Get(name string) (R, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, found := r.entries[name]
+	if !found {
+		var zero R
+		return zero, false
+	}
+
+	return e.runner, true
+}
+
+// Each calls fn for every running supervisor sorted by name, fn is called without holding any lock
+func (r *Registry[R]) Each(fn func(name string, runner R)) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.entries))
+	runners := make(map[string]R, len(r.entries))
+	for name, e := range r.entries {
+		names = append(names, name)
+		runners[name] = e.runner
+	}
+	r.mu.RUnlock()
+
+	sort.Strings(names)
+	for _, name := range names {
+		fn(name, runners[name])
+	}
+}
+
+func (r *Registry[R]) State(name string) State {
+	return r.Machine(name).State()
+}
+
+// Machine returns the lifecycle of the supervisor, created on first use
+func (r *Registry[R]) Machine(name string) *Machine {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.machine(name)
+}
+
+func (r *Registry[R]) machine(name string) *Machine {
+	m, found := r.machines[name]
+	if !found {
+		m = New(r.policy, r.now)
+		m.onChange = func(from, to State, at time.Time, err error) {
+			r.publish(Change{Supervisor: name, From: from, To: to, At: at, Err: err})
+		}
+		r.machines[name] = m
+	}
+
+	return m
+}
+
+// Subscribe returns a channel receiving every state change and a function to cancel the subscription. Slow
+// subscribers miss changes instead of blocking the supervisors.
+func (r *Registry[R]) Subscribe() (<-chan Change, func()) {
+	ch := make(chan Change, 64)
+
+	r.subsMu.Lock()
+	r.subs[ch] = struct{}{}
+	r.subsMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			r.subsMu.Lock()
+			delete(r.subs, ch)
+			r.subsMu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (r *Registry[R]) publish(c Change) {
+	r.subsMu.Lock()
+	defer r.subsMu.Unlock()
+
+	for ch := range r.subs {
+		select {
+		case ch <- c:
+		default:
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package lifecycle
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeRunner struct {
+	done    chan struct{}
+	once    sync.Once
+	stopped atomic.Bool
+	// Config is mutated on reload while the runner is in use
+	mu     sync.Mutex
+	config int
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{done: make(chan struct{})}
+}
+
+func (f *fakeRunner) Run() error {
+	<-f.done
+	return nil
+}
+
+func (f *fakeRunner) Stop() {
+	f.stopped.Store(true)
+	f.once.Do(func() {
+		close(f.done)
+	})
+}
+
+func (f *fakeRunner) reload(config int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config = config
+}
+
+func build() (*fakeRunner, error) {
+	return newFakeRunner(), nil
+}
+
+// start starts the supervisor and waits until it's built
+func start(r *Registry[*fakeRunner], name string, build func() (*fakeRunner, error)) error {
+	done, err := r.Start(name, build)
+	if err != nil {
+		return err
+	}
+
+	return <-done
+}
+
+// waitFor polls the condition, the registry runs the supervisors in the background
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRegistryStartStop(t *testing.T) {
+	r := NewRegistry[*fakeRunner](DefaultPolicy, time.Now)
+	changes, cancel := r.Subscribe()
+	defer cancel()
+
+	if err := start(r, "sorc", build); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Start("sorc", build); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected %v, got %v", ErrAlreadyRunning, err)
+	}
+
+	runner, found := r.Get("sorc")
+	if !found {
+		t.Fatal("expected the supervisor to be running")
+	}
+
+	r.Stop("sorc")
+	if !runner.stopped.Load() {
+		t.Error("expected the runner to be stopped")
+	}
+	if _, found := r.Get("sorc"); found || r.State("sorc") != NotStarted {
+		t.Errorf("expected the supervisor to be gone, got %s", r.State("sorc"))
+	}
+
+	expected := []State{Starting, Stopping, NotStarted}
+	for _, s := range expected {
+		c := <-changes
+		if c.Supervisor != "sorc" || c.To != s {
+			t.Fatalf("expected a change to %s, got %+v", s, c)
+		}
+	}
+}
+
+func TestRegistryStopWhileStarting(t *testing.T) {
+	r := NewRegistry[*fakeRunner](DefaultPolicy, time.Now)
+	building := make(chan struct{})
+	release := make(chan struct{})
+	runner := newFakeRunner()
+
+	// Start returns before the supervisor is built
+	result, err := r.Start("sorc", func() (*fakeRunner, error) {
+		close(building)
+		<-release
+		return runner, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-building
+	if _, err := r.Start("sorc", build); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("expected %v while building, got %v", ErrAlreadyRunning, err)
+	}
+	r.Stop("sorc")
+	close(release)
+
+	if err := <-result; !errors.Is(err, ErrStopped) {
+		t.Fatalf("expected %v, got %v", ErrStopped, err)
+	}
+	if !runner.stopped.Load() || r.State("sorc") != NotStarted {
+		t.Fatalf("expected a stopped runner and %s, got %s", NotStarted, r.State("sorc"))
+	}
+}
+
+func TestRegistryRunnerExits(t *testing.T) {
+	r := NewRegistry[*fakeRunner](DefaultPolicy, time.Now)
+	start(r, "sorc", build)
+	runner, _ := r.Get("sorc")
+	r.Machine("sorc").To(InGame)
+
+	// The supervisor finishes on its own, without a Stop call from the registry
+	runner.once.Do(func() { close(runner.done) })
+
+	waitFor(t, func() bool {
+		_, found := r.Get("sorc")
+		return !found && r.State("sorc") == NotStarted
+	})
+}
+
+func TestRegistryCrash(t *testing.T) {
+	r := NewRegistry[*fakeRunner](Policy{BaseDelay: time.Second, MaxCrashes: 3, Window: time.Hour}, time.Now)
+	start(r, "sorc", build)
+	runner, _ := r.Get("sorc")
+
+	delay, disabled, err := r.Crash("sorc")
+	if err != nil || disabled || delay != time.Second {
+		t.Fatalf("unexpected crash result: %s, %t, %v", delay, disabled, err)
+	}
+	if !runner.stopped.Load() || r.State("sorc") != BackingOff {
+		t.Fatalf("expected a stopped runner backing off, got %s", r.State("sorc"))
+	}
+
+	// A failed restart stays in Starting to be handled as another crash, the error is published
+	changes, cancel := r.Subscribe()
+	defer cancel()
+	failed := errors.New("game did not start")
+	if err := start(r, "sorc", func() (*fakeRunner, error) { return nil, failed }); !errors.Is(err, failed) {
+		t.Fatalf("expected the build error, got %v", err)
+	}
+	if r.State("sorc") != Starting {
+		t.Fatalf("expected %s after a failed restart, got %s", Starting, r.State("sorc"))
+	}
+	for c := range changes {
+		if c.Err != nil {
+			if !errors.Is(c.Err, failed) || c.To != Starting {
+				t.Fatalf("unexpected failure change %+v", c)
+			}
+			break
+		}
+	}
+	r.Crash("sorc")
+	if _, disabled, _ := r.Crash("sorc"); disabled {
+		t.Fatal("crashes while backing off should be ignored")
+	}
+
+	// Stopping cancels the pending restart
+	r.Stop("sorc")
+	if r.State("sorc") != NotStarted {
+		t.Fatalf("expected %s, got %s", NotStarted, r.State("sorc"))
+	}
+
+	// A failed manual start goes back to NotStarted
+	if err := start(r, "sorc", func() (*fakeRunner, error) { return nil, failed }); err == nil || r.State("sorc") != NotStarted {
+		t.Fatalf("expected %s after a failed start, got %s", NotStarted, r.State("sorc"))
+	}
+}
+
+// TestRegistryConcurrency is meant to be run with -race
+func TestRegistryConcurrency(t *testing.T) {
+	r := NewRegistry[*fakeRunner](DefaultPolicy, time.Now)
+	names := []string{"sorc", "pala", "necro", "barb"}
+
+	changes, cancel := r.Subscribe()
+	received := make(chan int)
+	go func() {
+		n := 0
+		for range changes {
+			n++
+		}
+		received <- n
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				name := names[(i+j)%len(names)]
+				switch j % 4 {
+				case 0, 1:
+					start(r, name, build)
+				case 2:
+					r.Stop(name)
+				case 3:
+					// Config reload
+					r.Each(func(_ string, runner *fakeRunner) {
+						runner.reload(j)
+					})
+				}
+				r.Get(name)
+				r.State(name)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, name := range names {
+		r.Stop(name)
+	}
+	for _, name := range names {
+		if _, found := r.Get(name); found || r.State(name) != NotStarted {
+			t.Errorf("%s: expected %s, got %s", name, NotStarted, r.State(name))
+		}
+	}
+
+	cancel()
+	cancel()
+	if n := <-received; n == 0 {
+		t.Error("expected state changes to be published")
+	}
+}
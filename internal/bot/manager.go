@@ -4,13 +4,16 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 	"unsafe"
 
 	"github.com/hectorgimenez/koolo/cmd/koolo/log"
+	"github.com/hectorgimenez/koolo/internal/bot/lifecycle"
 	"github.com/hectorgimenez/koolo/internal/character"
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/config/reload"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
@@ -21,25 +24,56 @@ import (
 	"github.com/lxn/win"
 )
 
+// SupervisorManager is safe for concurrent use, supervisors and their lifecycle are owned by the registry
 type SupervisorManager struct {
-	logger         *slog.Logger
-	supervisors    map[string]Supervisor
-	crashDetectors map[string]*game.CrashDetector
-	eventListener  *event.Listener
+	logger        *slog.Logger
+	registry      *lifecycle.Registry[*managedSupervisor]
+	eventListener *event.Listener
+}
+
+// managedSupervisor runs the supervisor together with its crash detector
+type managedSupervisor struct {
+	Supervisor
+	crashDetector *game.CrashDetector
+	logger        *slog.Logger
+
+	// Config reloaded while running, see reload
+	mu      sync.Mutex
+	pending *config.CharacterCfg
+	waiting []reload.Change
+}
+
+func (m *managedSupervisor) Run() error {
+	// Start the Crash Detector in a thread to avoid blocking and speed up start
+	go m.crashDetector.Start()
+
+	err := m.Supervisor.Start()
+	if err != nil {
+		m.logger.Error(fmt.Sprintf("error running supervisor %s: %s", m.Name(), err.Error()))
+	}
+
+	return err
+}
+
+func (m *managedSupervisor) Stop() {
+	m.Supervisor.Stop()
+	m.crashDetector.Stop()
 }
 
 func NewSupervisorManager(logger *slog.Logger, eventListener *event.Listener) *SupervisorManager {
-	return &SupervisorManager{
-		logger:         logger,
-		supervisors:    make(map[string]Supervisor),
-		crashDetectors: make(map[string]*game.CrashDetector),
-		eventListener:  eventListener,
+	mng := &SupervisorManager{
+		logger:        logger,
+		registry:      lifecycle.NewRegistry[*managedSupervisor](lifecycle.DefaultPolicy, time.Now),
+		eventListener: eventListener,
 	}
+	eventListener.Register(mng.applyPendingConfig)
+
+	return mng
 }
 
 func (mng *SupervisorManager) AvailableSupervisors() []string {
 	availableSupervisors := make([]string, 0)
-	for name := range config.Characters {
+	for name := range config.Characters() {
 		if name != "template" {
 			availableSupervisors = append(availableSupervisors, name)
 		}
@@ -48,23 +82,25 @@ func (mng *SupervisorManager) AvailableSupervisors() []string {
 	return availableSupervisors
 }
 
+// Start builds the supervisor and runs it in the background. It returns right away, only the errors preventing the
+// start are returned, build failures are published through Subscribe. See StartAndWait.
 func (mng *SupervisorManager) Start(supervisorName string, attachToExisting bool, pidHwnd ...uint32) error {
-	// Avoid multiple instances of the supervisor - shitstorm prevention
-	if _, exists := mng.supervisors[supervisorName]; exists {
-		return fmt.Errorf("supervisor %s is already running", supervisorName)
-	}
+	_, err := mng.start(supervisorName, attachToExisting, pidHwnd...)
 
-	// Reload config to get the latest local changes before starting the supervisor
-	err := config.Load()
-	if err != nil {
-		return fmt.Errorf("error loading config: %w", err)
-	}
+	return err
+}
 
-	supervisorLogger, err := log.NewLogger(config.Koolo.Debug.Log, config.Koolo.LogSaveDirectory, supervisorName)
+// StartAndWait starts the supervisor and waits until the game is started or attached, returning the build error
+func (mng *SupervisorManager) StartAndWait(supervisorName string, attachToExisting bool, pidHwnd ...uint32) error {
+	done, err := mng.start(supervisorName, attachToExisting, pidHwnd...)
 	if err != nil {
 		return err
 	}
 
+	return <-done
+}
+
+func (mng *SupervisorManager) start(supervisorName string, attachToExisting bool, pidHwnd ...uint32) (<-chan error, error) {
 	var optionalPID uint32
 	var optionalHWND win.HWND
 
@@ -74,136 +110,138 @@ func (mng *SupervisorManager) Start(supervisorName string, attachToExisting bool
 			optionalPID = pidHwnd[0]
 			optionalHWND = win.HWND(pidHwnd[1])
 		} else {
-			return fmt.Errorf("pid and hwnd are required when attaching to an existing game")
+			return nil, fmt.Errorf("pid and hwnd are required when attaching to an existing game")
 		}
 	}
 
-	supervisor, crashDetector, err := mng.buildSupervisor(supervisorName, supervisorLogger, attachToExisting, optionalPID, optionalHWND)
-	if err != nil {
-		return err
-	}
+	// The registry prevents multiple instances of the supervisor - shitstorm prevention
+	built, err := mng.registry.Start(supervisorName, func() (*managedSupervisor, error) {
+		// Reload config to get the latest local changes before starting the supervisor
+		if err := config.Load(); err != nil {
+			return nil, fmt.Errorf("error loading config: %w", err)
+		}
+
+		supervisorLogger, err := log.NewLogger(config.Koolo.Debug.Log, config.Koolo.LogSaveDirectory, supervisorName)
+		if err != nil {
+			return nil, err
+		}
+
+		supervisor, crashDetector, err := mng.buildSupervisor(supervisorName, supervisorLogger, attachToExisting, optionalPID, optionalHWND)
+		if err != nil {
+			return nil, err
+		}
 
-	if oldCrashDetector, exists := mng.crashDetectors[supervisorName]; exists {
-		oldCrashDetector.Stop() // Stop the old crash detector if it exists
+		return &managedSupervisor{Supervisor: supervisor, crashDetector: crashDetector, logger: mng.logger}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	mng.supervisors[supervisorName] = supervisor
-	mng.crashDetectors[supervisorName] = crashDetector
+	done := make(chan error, 1)
+	go func() {
+		err := <-built
+		if err != nil {
+			mng.logger.Error("Failed to start supervisor", slog.String("supervisor", supervisorName), slog.String("error", err.Error()))
+		}
+		done <- err
+		close(done)
 
-	if config.Koolo.GameWindowArrangement {
-		go func() {
+		if err == nil && config.Koolo.GameWindowArrangement {
 			// When the game starts, its doing some weird stuff like repositioning and resizing window automatically
 			// we need to wait until this is done in order to reposition, or it will be overridden
 			time.Sleep(time.Second * 5)
 			mng.rearrangeWindows()
-		}()
-	}
-
-	// Start the Crash Detector in a thread to avoid blocking and speed up start
-	go crashDetector.Start()
-
-	err = supervisor.Start()
-	if err != nil {
-		mng.logger.Error(fmt.Sprintf("error running supervisor %s: %s", supervisorName, err.Error()))
-	}
+		}
+	}()
 
-	return nil
+	return done, nil
 }
 
-func (mng *SupervisorManager) ReloadConfig() error {
+// ReloadConfig loads the config files and applies the changes to the running supervisors. Only safe changes are
+// applied right away, the report tells when the rest will take effect.
+func (mng *SupervisorManager) ReloadConfig() ([]ReloadReport, error) {
 
-	// Load fresh configs
+	// Load fresh configs, the running supervisors with an invalid config (or pickit rules) keep their current one
 	if err := config.Load(); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Apply new configs to running supervisors
-	for name, sup := range mng.supervisors {
-		newCfg, exists := config.Characters[name]
+	reports := make([]ReloadReport, 0)
+	mng.registry.Each(func(name string, sup *managedSupervisor) {
+		if sup.GetContext() == nil {
+			return
+		}
+		if invalid, found := config.InvalidCharacters()[name]; found {
+			reports = append(reports, ReloadReport{Supervisor: name, Error: invalid.Err.Error()})
+			return
+		}
+		loaded, exists := config.Characters()[name]
 		if !exists {
-			continue
+			return
 		}
 
-		ctx := sup.GetContext()
-		if ctx == nil {
-			continue
+		if changes := sup.reload(loaded); len(changes) > 0 {
+			reports = append(reports, ReloadReport{Supervisor: name, Changes: changes})
 		}
+	})
 
-		// Preserve runtime data
-		//oldRuntimeData := ctx.CharacterCfg.Runtime
-
-		// Update the config
-		*ctx.CharacterCfg = *newCfg
-		//ctx.CharacterCfg.Runtime = oldRuntimeData
-	}
-
-	return nil
+	return reports, nil
 }
 
 func (mng *SupervisorManager) StopAll() {
-	for _, s := range mng.supervisors {
-		s.Stop()
-	}
+	mng.registry.Each(func(name string, _ *managedSupervisor) {
+		mng.registry.Stop(name)
+	})
 }
 
+// Stop stops the supervisor, cancelling any pending restart after a crash
 func (mng *SupervisorManager) Stop(supervisor string) {
+	mng.registry.Stop(supervisor)
+}
 
-	s, found := mng.supervisors[supervisor]
-	if found {
-
-		// Stop the Supervisor
-		s.Stop()
-
-		// Delete him from the list of Supervisors
-		delete(mng.supervisors, supervisor)
+// Enable allows a supervisor disabled after too many crashes to be started again
+func (mng *SupervisorManager) Enable(supervisor string) error {
+	return mng.registry.Enable(supervisor)
+}
 
-		if cd, ok := mng.crashDetectors[supervisor]; ok {
-			cd.Stop()
-			delete(mng.crashDetectors, supervisor)
-		}
-	}
+// Subscribe returns a channel receiving every supervisor status change and a function to cancel the subscription
+func (mng *SupervisorManager) Subscribe() (<-chan lifecycle.Change, func()) {
+	return mng.registry.Subscribe()
 }
 
 func (mng *SupervisorManager) TogglePause(supervisor string) {
-	s, found := mng.supervisors[supervisor]
+	s, found := mng.registry.Get(supervisor)
 	if found {
 		s.TogglePause()
 	}
 }
 
 func (mng *SupervisorManager) Status(characterName string) Stats {
-	for name, supervisor := range mng.supervisors {
-		if name == characterName {
-			return supervisor.Stats()
-		}
-	}
-
-	return Stats{}
+	return mng.GetSupervisorStats(characterName)
 }
 
 func (mng *SupervisorManager) GetData(characterName string) *game.Data {
-	for name, supervisor := range mng.supervisors {
-		if name == characterName {
-			return supervisor.GetData()
-		}
+	if supervisor, found := mng.registry.Get(characterName); found {
+		return supervisor.GetData()
 	}
 
 	return nil
 }
 
 func (mng *SupervisorManager) GetContext(characterName string) *context.Context {
-	for name, supervisor := range mng.supervisors {
-		if name == characterName {
-			return supervisor.GetContext()
-		}
+	if supervisor, found := mng.registry.Get(characterName); found {
+		return supervisor.GetContext()
 	}
 
 	return nil
 }
 
 func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slog.Logger, attach bool, optionalPID uint32, optionalHWND win.HWND) (Supervisor, *game.CrashDetector, error) {
-	cfg, found := config.Characters[supervisorName]
+	cfg, found := config.Characters()[supervisorName]
 	if !found {
+		if invalid, isInvalid := config.InvalidCharacters()[supervisorName]; isInvalid {
+			return nil, nil, invalid.Err
+		}
 		return nil, nil, fmt.Errorf("character %s not found", supervisorName)
 	}
 
@@ -218,8 +256,16 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 			return nil, nil, fmt.Errorf("pid and hwnd are required when attaching to an existing game")
 		}
 	} else {
-		var err error
-		pid, hwnd, err = game.StartGame(cfg.Username, cfg.Password, cfg.AuthMethod, cfg.AuthToken, cfg.Realm, cfg.CommandLineArgs, config.Koolo.UseCustomSettings)
+		// Secrets are only resolved here, the config keeps their references
+		password, err := config.ResolveSecret(cfg.Password)
+		if err != nil {
+			return nil, nil, err
+		}
+		authToken, err := config.ResolveSecret(cfg.AuthToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		pid, hwnd, err = game.StartGame(cfg.Username, password, cfg.AuthMethod, authToken, cfg.Realm, cfg.CommandLineArgs, config.Koolo.UseCustomSettings)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error starting game: %w", err)
 		}
@@ -261,7 +307,7 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 
 	bot := NewBot(ctx.Context)
 
-	statsHandler := NewStatsHandler(supervisorName, logger)
+	statsHandler := NewStatsHandler(supervisorName, logger, mng.registry.Machine(supervisorName))
 	mng.eventListener.Register(statsHandler.Handle)
 
 	var supervisor Supervisor
@@ -273,63 +319,44 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 
 	}
 
-	// This function will be used to restart the client - passed to the crashDetector
+	// This function will be used to restart the client - passed to the crashDetector. Restarts are delayed with an
+	// exponential backoff, the supervisor is disabled once the crash budget is exhausted.
 	restartFunc := func() {
-		mng.logger.Info("Restarting supervisor after crash", slog.String("supervisor", supervisorName))
-		mng.Stop(supervisorName)
-		time.Sleep(5 * time.Second) // Wait a bit before restarting
-
-		// Get a list of all available Supervisors
-		supervisorList := mng.AvailableSupervisors()
+		lc := mng.registry.Machine(supervisorName)
 
 		for {
-
-			// Set the default state
-			tokenAuthStarting := false
-
-			// Get the current supervisor's config
-			supCfg := config.Characters[supervisorName]
-
-			for _, sup := range supervisorList {
-
-				// If the current don't check against the one we're trying to launch
-				if sup == supervisorName {
-					continue
-				}
-
-				if mng.GetSupervisorStats(sup).SupervisorStatus == Starting {
-					if supCfg.AuthMethod == "TokenAuth" {
-						tokenAuthStarting = true
-						mng.logger.Info("Waiting before restart as another client is already starting and we're using token auth", slog.String("supervisor", sup))
-						break
-					}
-
-					sCfg, found := config.Characters[sup]
-					if found {
-						if sCfg.AuthMethod == "TokenAuth" {
-							// A client that uses token auth is currently starting, hold off restart
-							tokenAuthStarting = true
-							mng.logger.Info("Waiting before restart as a client that's using token auth is already starting", slog.String("supervisor", sup))
-							break
-						}
-					}
-				}
+			delay, disabled, err := mng.registry.Crash(supervisorName)
+			if err != nil {
+				// Crashes while stopping are expected, the game is being closed
+				mng.logger.Debug("Ignoring client crash", slog.String("supervisor", supervisorName), slog.String("error", err.Error()))
+				return
 			}
-
-			if !tokenAuthStarting {
-				break
+			if disabled {
+				message := fmt.Sprintf("Supervisor %s disabled after crashing %d times in %s", supervisorName, lc.Crashes(), lifecycle.DefaultPolicy.Window)
+				mng.logger.Error(message)
+				event.Send(event.SupervisorDisabled(event.Text(supervisorName, message), lc.Crashes()))
+				return
 			}
 
-			// Wait 5 seconds before checking again
-			utils.Sleep(5000)
-		}
+			mng.logger.Info("Restarting supervisor after crash", slog.String("supervisor", supervisorName), slog.Duration("delay", delay))
+			time.Sleep(delay)
+			mng.waitForTokenAuthClients(supervisorName)
 
-		gameTitle := "D2R - [" + strconv.FormatInt(int64(pid), 10) + "] - " + supervisorName + " - " + cfg.Realm
-		winproc.SetWindowText.Call(uintptr(hwnd), uintptr(unsafe.Pointer(syscall.StringToUTF16Ptr(gameTitle))))
+			// Stopped while backing off
+			if lc.State() != BackingOff {
+				return
+			}
 
-		err := mng.Start(supervisorName, false)
-		if err != nil {
+			err = mng.StartAndWait(supervisorName, false)
+			if err == nil {
+				return
+			}
 			mng.logger.Error("Failed to restart supervisor", slog.String("supervisor", supervisorName), slog.String("Error: ", err.Error()))
+
+			// Only failures while starting the game are handled as crashes
+			if lc.State() != Starting {
+				return
+			}
 		}
 	}
 
@@ -341,10 +368,16 @@ func (mng *SupervisorManager) buildSupervisor(supervisorName string, logger *slo
 }
 
 func (mng *SupervisorManager) GetSupervisorStats(supervisor string) Stats {
-	if mng.supervisors[supervisor] == nil {
-		return Stats{}
+	s, found := mng.registry.Get(supervisor)
+	if !found {
+		// Supervisors being built or backing off after a crash are not running, their state is still relevant
+		return Stats{SupervisorStatus: mng.registry.State(supervisor)}
 	}
-	return mng.supervisors[supervisor].Stats()
+
+	stats := s.Stats()
+	stats.PendingConfig = s.pendingChanges()
+
+	return stats
 }
 
 func (mng *SupervisorManager) rearrangeWindows() {
@@ -365,7 +398,7 @@ func (mng *SupervisorManager) rearrangeWindows() {
 	)
 
 	var column, row int32
-	for _, sp := range mng.supervisors {
+	mng.registry.Each(func(_ string, sp *managedSupervisor) {
 		// reminder that columns are vertical (they go up and down) and rows are horizontal (they go left and right)
 		if column > maxColumns {
 			column = 0
@@ -385,5 +418,53 @@ func (mng *SupervisorManager) rearrangeWindows() {
 		} else {
 			mng.logger.Debug("Window position of supervisor " + sp.Name() + " was not changed, no free space for it")
 		}
+	})
+}
+
+// waitForTokenAuthClients blocks while another client is starting and any of them uses token auth
+func (mng *SupervisorManager) waitForTokenAuthClients(supervisorName string) {
+	// Get a list of all available Supervisors
+	supervisorList := mng.AvailableSupervisors()
+
+	for {
+
+		// Set the default state
+		tokenAuthStarting := false
+
+		// Get the current supervisor's config
+		supCfg := config.Characters()[supervisorName]
+
+		for _, sup := range supervisorList {
+
+			// If the current don't check against the one we're trying to launch
+			if sup == supervisorName {
+				continue
+			}
+
+			if mng.GetSupervisorStats(sup).SupervisorStatus == Starting {
+				if supCfg.AuthMethod == "TokenAuth" {
+					tokenAuthStarting = true
+					mng.logger.Info("Waiting before restart as another client is already starting and we're using token auth", slog.String("supervisor", sup))
+					break
+				}
+
+				sCfg, found := config.Characters()[sup]
+				if found {
+					if sCfg.AuthMethod == "TokenAuth" {
+						// A client that uses token auth is currently starting, hold off restart
+						tokenAuthStarting = true
+						mng.logger.Info("Waiting before restart as a client that's using token auth is already starting", slog.String("supervisor", sup))
+						break
+					}
+				}
+			}
+		}
+
+		if !tokenAuthStarting {
+			break
+		}
+
+		// Wait 5 seconds before checking again
+		utils.Sleep(5000)
 	}
 }
@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/config/reload"
+	"github.com/hectorgimenez/koolo/internal/event"
+)
+
+// ReloadReport tells when each config change of a running supervisor takes effect
+type ReloadReport struct {
+	Supervisor string
+	Changes    []reload.Change
+	// Error is set when the new config is invalid, the supervisor keeps running with its current config
+	Error string `json:",omitempty"`
+}
+
+// reload applies the safe changes of the loaded config right away, the rest is kept for the next game or restart
+func (m *managedSupervisor) reload(loaded *config.CharacterCfg) []reload.Change {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	live := m.GetContext().CharacterCfg
+	changes := append(config.ReloadPolicy.Diff(live, loaded), runtimeChanges(live, loaded)...)
+
+	m.apply(live, loaded, reload.Now)
+	m.pending = loaded
+	m.waiting = make([]reload.Change, 0)
+	for _, c := range changes {
+		if c.Apply != reload.Now {
+			m.waiting = append(m.waiting, c)
+		}
+	}
+
+	return changes
+}
+
+// applyPending applies the changes waiting for the next game, changes requiring a restart are still reported
+func (m *managedSupervisor) applyPending() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.pending == nil {
+		return
+	}
+
+	m.apply(m.GetContext().CharacterCfg, m.pending, reload.NextGame)
+	m.waiting = reload.Filter(m.waiting, reload.Restart)
+	m.pending = nil
+	m.logger.Info("Config changes applied for the new game", slog.String("supervisor", m.Name()))
+}
+
+func (m *managedSupervisor) pendingChanges() []reload.Change {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]reload.Change(nil), m.waiting...)
+}
+
+// apply copies the changes that can be applied at the given moment, including the earlier ones
+func (m *managedSupervisor) apply(live, loaded *config.CharacterCfg, until reload.Apply) {
+	changes := make([]reload.Change, 0)
+	for _, c := range config.ReloadPolicy.Diff(live, loaded) {
+		if c.Apply <= until {
+			changes = append(changes, c)
+		}
+	}
+	if err := reload.Copy(live, loaded, changes); err != nil {
+		m.logger.Error("Error applying config changes", slog.String("supervisor", m.Name()), slog.Any("error", err))
+	}
+
+	// Rules are parsed by config.Load for every character before anything is applied, they are replaced as a whole
+	runtime := live.Runtime
+	runtime.Rules = loaded.Runtime.Rules
+	runtime.ShoppingRules = loaded.Runtime.ShoppingRules
+	runtime.MercRules = loaded.Runtime.MercRules
+	live.Runtime = runtime
+}
+
+// runtimeChanges reports the changes on the rules and the build, which are loaded from their own files
+func runtimeChanges(live, loaded *config.CharacterCfg) []reload.Change {
+	changes := make([]reload.Change, 0)
+	rules := func(path string, old, new nip.Rules) {
+		if !sameRules(old, new) {
+			changes = append(changes, reload.Change{Path: path, Old: fmt.Sprintf("%d rules", len(old)), New: fmt.Sprintf("%d rules", len(new)), Apply: reload.Now})
+		}
+	}
+	rules("pickit", live.Runtime.Rules, loaded.Runtime.Rules)
+	rules("shopping", live.Runtime.ShoppingRules, loaded.Runtime.ShoppingRules)
+	rules("merc", live.Runtime.MercRules, loaded.Runtime.MercRules)
+
+	// The character is built once on start
+	if !reflect.DeepEqual(live.Runtime.Build, loaded.Runtime.Build) {
+		changes = append(changes, reload.Change{Path: "build", Old: "build file", New: "build file", Apply: reload.Restart})
+	}
+
+	return changes
+}
+
+func sameRules(a, b nip.Rules) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Filename != b[i].Filename || a[i].LineNumber != b[i].LineNumber || a[i].RawLine != b[i].RawLine || a[i].Enabled != b[i].Enabled {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applyPendingConfig applies the config changes waiting for the next game once the current one is finished
+func (mng *SupervisorManager) applyPendingConfig(_ context.Context, e event.Event) error {
+	if _, ok := e.(event.GameFinishedEvent); !ok {
+		return nil
+	}
+
+	if sup, found := mng.registry.Get(e.Supervisor()); found {
+		sup.applyPending()
+	}
+
+	return nil
+}
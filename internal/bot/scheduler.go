@@ -2,15 +2,23 @@ package bot
 
 import (
 	"log/slog"
+	"math/rand"
+	"reflect"
+	"sync"
 	"time"
 
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/schedule"
 )
 
 type Scheduler struct {
-	manager *SupervisorManager
-	logger  *slog.Logger
-	stop    chan struct{}
+	manager   *SupervisorManager
+	logger    *slog.Logger
+	stop      chan struct{}
+	mu        sync.Mutex
+	engines   map[string]*schedule.Engine
+	waiting   map[string]string
+	lastStart time.Time
 }
 
 func NewScheduler(manager *SupervisorManager, logger *slog.Logger) *Scheduler {
@@ -18,6 +26,8 @@ func NewScheduler(manager *SupervisorManager, logger *slog.Logger) *Scheduler {
 		manager: manager,
 		logger:  logger,
 		stop:    make(chan struct{}),
+		engines: make(map[string]*schedule.Engine),
+		waiting: make(map[string]string),
 	}
 }
 
@@ -26,10 +36,19 @@ func (s *Scheduler) Start() {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
+	// Stopped supervisors free a fleet slot, waiting ones don't have to wait for the next tick
+	changes, cancel := s.manager.Subscribe()
+	defer cancel()
+
 	for {
 		select {
 		case <-ticker.C:
 			s.checkSchedules()
+		case change := <-changes:
+			// Failed starts are retried on the next tick, not right away
+			if change.Err == nil && (change.To == NotStarted || change.To == Disabled) {
+				s.checkSchedules()
+			}
 		case <-s.stop:
 			s.logger.Info("Scheduler stopped")
 			return
@@ -41,52 +60,161 @@ func (s *Scheduler) Stop() {
 	close(s.stop)
 }
 
+// Next returns the next scheduled start or stop for the supervisor, false if the scheduler is disabled for it
+func (s *Scheduler) Next(supervisorName string) (schedule.Transition, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	engine, found := s.engines[supervisorName]
+	if !found {
+		return schedule.Transition{}, false
+	}
+
+	if reason, waiting := s.waiting[supervisorName]; waiting {
+		return schedule.Transition{At: time.Now(), Action: schedule.Start, Reason: reason}, true
+	}
+
+	return engine.Next()
+}
+
 func (s *Scheduler) checkSchedules() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	now := time.Now()
-	currentDay := int(now.Weekday())
+	decisions := make(map[string]schedule.Decision)
+	characters := config.Characters()
+	candidates := make([]schedule.Candidate, 0, len(characters))
+	for supervisorName, cfg := range characters {
+		running := !s.supervisorNotStarted(supervisorName)
 
-	for supervisorName, cfg := range config.Characters {
+		// Supervisors out of the scheduler are left alone, but they still use the machine
 		if !cfg.Scheduler.Enabled {
+			delete(s.engines, supervisorName)
+			if running {
+				candidates = append(candidates, schedule.Candidate{Name: supervisorName, Running: true, Pinned: true})
+			}
 			continue
 		}
 
-		for _, day := range cfg.Scheduler.Days {
-			if day.DayOfWeek != currentDay {
-				continue
-			}
+		engine := s.engine(supervisorName, cfg.Scheduler)
+		decision := engine.Tick(running)
+		if decision.Action == schedule.Stop {
+			s.logger.Info("Stopping supervisor based on schedule: "+decision.Reason, "supervisor", supervisorName)
+			s.stopSupervisor(supervisorName)
+			running = false
+		}
+		decisions[supervisorName] = decision
 
-			actionTaken := false
-
-			// Check if any time range is active
-			for _, timeRange := range day.TimeRanges {
-				start := time.Date(now.Year(), now.Month(), now.Day(), timeRange.Start.Hour(), timeRange.Start.Minute(), 0, 0, now.Location())
-				end := time.Date(now.Year(), now.Month(), now.Day(), timeRange.End.Hour(), timeRange.End.Minute(), 0, 0, now.Location())
-
-				if now.After(start) && now.Before(end) && s.supervisorNotStarted(supervisorName) {
-					s.logger.Info("Starting supervisor based on schedule. Time range: "+start.Format("15:04")+" - "+end.Format("15:04"), "supervisor", supervisorName)
-					go s.startSupervisor(supervisorName)
-					actionTaken = true
-					break
-				} else if now.After(end) || now == end || now.Before(start) && !s.supervisorNotStarted(supervisorName) {
-					s.logger.Info("Stopping supervisor based on schedule. Time range: "+start.Format("15:04")+" - "+end.Format("15:04"), "supervisor", supervisorName)
-					s.stopSupervisor(supervisorName)
-					actionTaken = true
-					break
-				}
-			}
+		since := s.manager.GetSupervisorStats(supervisorName).StartedAt
+		if since.IsZero() {
+			since = now
+		}
+		candidates = append(candidates, schedule.Candidate{
+			Name:     supervisorName,
+			Priority: cfg.Scheduler.Priority,
+			// Disabled supervisors are only started again manually
+			Wants:   decision.Run && s.manager.GetSupervisorStats(supervisorName).SupervisorStatus != Disabled,
+			Running: running,
+			Since:   since,
+			Played:  engine.Played(now),
+		})
+	}
 
-			// To prevent unnecessary checks since it can be only one day.
-			// Also avoid starting/stoping a supervisor in the same minute ... this is rediculous
-			if actionTaken {
-				break
-			}
+	plan := fleetPolicy().Plan(now, s.lastStart, candidates)
+	for _, change := range plan.Stop {
+		s.logger.Info("Stopping supervisor based on fleet policy: "+change.Reason, "supervisor", change.Name)
+		s.stopSupervisor(change.Name)
+	}
+
+	for _, change := range plan.Start {
+		s.logger.Info("Starting supervisor based on schedule: "+decisions[change.Name].Reason, "supervisor", change.Name)
+		s.lastStart = now
+		go s.startSupervisor(change.Name)
+	}
+
+	s.waiting = make(map[string]string)
+	for _, change := range plan.Waiting {
+		s.waiting[change.Name] = change.Reason
+	}
+}
+
+func fleetPolicy() schedule.FleetPolicy {
+	return schedule.FleetPolicy{
+		MaxConcurrent: config.Koolo.Fleet.MaxConcurrent,
+		RotateAfter:   time.Duration(config.Koolo.Fleet.RotateAfter) * time.Minute,
+		StartDelay:    time.Duration(config.Koolo.Fleet.StartDelay) * time.Second,
+	}
+}
+
+// engine returns the schedule engine for the supervisor, rules are updated when the config changes
+func (s *Scheduler) engine(supervisorName string, cfg config.Scheduler) *schedule.Engine {
+	rules := scheduleRules(cfg)
+
+	engine, found := s.engines[supervisorName]
+	if !found {
+		engine = schedule.New(rules, time.Now, rand.New(rand.NewSource(time.Now().UnixNano())))
+		s.engines[supervisorName] = engine
+	} else if !reflect.DeepEqual(engine.Rules(), rules) {
+		engine.SetRules(rules)
+	}
+
+	return engine
+}
+
+func scheduleRules(cfg config.Scheduler) schedule.Rules {
+	minutes := func(m int) time.Duration {
+		return time.Duration(m) * time.Minute
+	}
+	ranges := func(timeRanges []config.TimeRange) []schedule.Range {
+		rs := make([]schedule.Range, 0, len(timeRanges))
+		for _, tr := range timeRanges {
+			rs = append(rs, schedule.Range{
+				Start: time.Duration(tr.Start.Hour())*time.Hour + minutes(tr.Start.Minute()),
+				End:   time.Duration(tr.End.Hour())*time.Hour + minutes(tr.End.Minute()),
+			})
 		}
+		return rs
 	}
+	// Dates in the config file are written in local time but parsed as UTC
+	local := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.Local)
+	}
+
+	rules := schedule.Rules{
+		Exceptions: make(map[string][]schedule.Range),
+		Jitter:     minutes(cfg.Jitter),
+		Breaks: schedule.Breaks{
+			EveryMin:  minutes(cfg.Breaks.EveryMin),
+			EveryMax:  minutes(cfg.Breaks.EveryMax),
+			LengthMin: minutes(cfg.Breaks.LengthMin),
+			LengthMax: minutes(cfg.Breaks.LengthMax),
+		},
+	}
+
+	for _, day := range cfg.Days {
+		if day.DayOfWeek >= 0 && day.DayOfWeek < 7 {
+			rules.Weekly[day.DayOfWeek] = ranges(day.TimeRanges)
+		}
+	}
+	for i, budget := range cfg.DailyBudget {
+		if i < 7 {
+			rules.Budgets[i] = minutes(budget)
+		}
+	}
+	for _, exception := range cfg.Exceptions {
+		rules.Exceptions[exception.Date] = ranges(exception.TimeRanges)
+	}
+	for _, w := range cfg.Windows {
+		rules.Windows = append(rules.Windows, schedule.Window{Start: local(w.Start), End: local(w.End)})
+	}
+
+	return rules
 }
 
 func (s *Scheduler) supervisorNotStarted(name string) bool {
 	stats := s.manager.GetSupervisorStats(name)
-	return stats.SupervisorStatus == NotStarted || stats.SupervisorStatus == Crashed || stats.SupervisorStatus == ""
+	return stats.SupervisorStatus == NotStarted || stats.SupervisorStatus == Disabled || stats.SupervisorStatus == ""
 }
 
 func (s *Scheduler) startSupervisor(name string) {
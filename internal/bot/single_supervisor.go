@@ -16,6 +16,7 @@ import (
 	"github.com/hectorgimenez/koolo/internal/health"
 	"github.com/hectorgimenez/koolo/internal/run"
 	"github.com/hectorgimenez/koolo/internal/utils"
+	"github.com/lxn/win"
 )
 
 type SinglePlayerSupervisor struct {
@@ -81,11 +82,11 @@ func (s *SinglePlayerSupervisor) Start() error {
 
 			runs := run.BuildRuns(s.bot.ctx.CharacterCfg)
 			gameStart := time.Now()
-			if config.Characters[s.name].Game.RandomizeRuns {
+			if config.Characters()[s.name].Game.RandomizeRuns {
 				rand.Shuffle(len(runs), func(i, j int) { runs[i], runs[j] = runs[j], runs[i] })
 			}
 			event.Send(event.GameCreated(event.Text(s.name, "New game created"), s.bot.ctx.GameReader.LastGameName(), s.bot.ctx.GameReader.LastGamePass()))
-			s.bot.ctx.LastBuffAt = time.Time{}
+			s.bot.ctx.Buffs.Reset()
 			s.logGameStart(runs)
 
 			// Refresh game data to make sure we have the latest information
@@ -109,6 +110,20 @@ func (s *SinglePlayerSupervisor) Start() error {
 			err = s.bot.Run(ctx, firstRun, runs)
 			firstRun = false
 
+			// Recover the corpse and keep going with the remaining runs, the game is finished when it's not possible
+			for errors.Is(err, health.ErrDied) && s.bot.WillRecover(err) {
+				s.bot.ctx.CurrentGame.Deaths++
+				deathArea := s.bot.ctx.Data.PlayerUnit.Area
+				s.bot.ctx.Logger.Warn("Character died, trying to recover the corpse", slog.String("area", deathArea.Area().Name), slog.Int("deaths", s.bot.ctx.CurrentGame.Deaths))
+
+				if respawnErr := s.respawn(); respawnErr != nil {
+					err = fmt.Errorf("%w: %w", health.ErrCorpseLost, respawnErr)
+					break
+				}
+
+				err = s.bot.Resume(ctx, append([]run.Run{run.NewCorpseRecovery(deathArea)}, s.bot.RemainingRuns()...))
+			}
+
 			var gameFinishReason event.FinishReason
 			if err != nil {
 				switch {
@@ -141,6 +156,21 @@ func (s *SinglePlayerSupervisor) Start() error {
 	}
 }
 
+// respawn goes back to town after a death, the death screen is closed pressing escape
+func (s *SinglePlayerSupervisor) respawn() error {
+	for range 10 {
+		s.bot.ctx.HID.PressKey(win.VK_ESCAPE)
+		utils.Sleep(1000)
+		s.bot.ctx.RefreshGameData()
+
+		if s.bot.ctx.Data.PlayerUnit.Area.IsTown() && s.bot.ctx.Data.PlayerUnit.HPPercent() > 0 {
+			return nil
+		}
+	}
+
+	return errors.New("character could not respawn in town")
+}
+
 // This function is responsible for handling all interactions with joining/creating games
 func (s *SinglePlayerSupervisor) HandleOutOfGameFlow() error {
 	// Refresh the data
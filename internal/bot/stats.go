@@ -7,32 +7,40 @@ import (
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/bot/lifecycle"
+	"github.com/hectorgimenez/koolo/internal/combat"
+	"github.com/hectorgimenez/koolo/internal/config/reload"
 	"github.com/hectorgimenez/koolo/internal/event"
 )
 
 const (
-	NotStarted SupervisorStatus = "Not Started"
-	Starting   SupervisorStatus = "Starting"
-	InGame     SupervisorStatus = "In game"
-	Paused     SupervisorStatus = "Paused"
-	Crashed    SupervisorStatus = "Crashed"
+	NotStarted = lifecycle.NotStarted
+	Starting   = lifecycle.Starting
+	InGame     = lifecycle.InGame
+	Paused     = lifecycle.Paused
+	Stopping   = lifecycle.Stopping
+	Crashed    = lifecycle.Crashed
+	BackingOff = lifecycle.BackingOff
+	Disabled   = lifecycle.Disabled
 )
 
-type SupervisorStatus string
+type SupervisorStatus = lifecycle.State
 
 type StatsHandler struct {
-	stats  *Stats
-	name   string
-	logger *slog.Logger
+	stats     *Stats
+	name      string
+	logger    *slog.Logger
+	lifecycle *lifecycle.Machine
 }
 
-func NewStatsHandler(name string, logger *slog.Logger) *StatsHandler {
+func NewStatsHandler(name string, logger *slog.Logger, lc *lifecycle.Machine) *StatsHandler {
 	return &StatsHandler{
-		name:   name,
-		logger: logger,
+		name:      name,
+		logger:    logger,
+		lifecycle: lc,
 		stats: &Stats{
-			SupervisorStatus: Starting,
-			StartedAt:        time.Now(),
+			StartedAt: time.Now(),
 		},
 	}
 }
@@ -48,7 +56,7 @@ func (h *StatsHandler) Handle(_ context.Context, e event.Event) error {
 		h.stats.Games = append(h.stats.Games, GameStats{
 			StartedAt: evt.OccurredAt(),
 		})
-		h.stats.SupervisorStatus = InGame
+		h.transition(InGame)
 
 	case event.GameFinishedEvent:
 		if len(h.stats.Games) > 0 {
@@ -73,14 +81,63 @@ func (h *StatsHandler) Handle(_ context.Context, e event.Event) error {
 
 	case event.GamePausedEvent:
 		if evt.Paused {
-			h.stats.SupervisorStatus = Paused
+			h.transition(Paused)
 		} else {
-			h.stats.SupervisorStatus = InGame
+			h.transition(InGame)
 		}
 
 	case event.ItemStashedEvent:
 		h.stats.Drops = append(h.stats.Drops, evt.Item)
 
+	case event.ItemCraftedEvent:
+		if h.stats.Crafting == nil {
+			h.stats.Crafting = make(map[string]CraftingStats)
+		}
+		cs := h.stats.Crafting[evt.Recipe]
+		cs.Crafted++
+		if evt.Kept {
+			cs.Kept++
+		} else {
+			cs.Sold++
+		}
+		h.stats.Crafting[evt.Recipe] = cs
+
+	case event.ItemGambledEvent:
+		h.stats.Gambling.Bought++
+		h.stats.Gambling.Spent += evt.Cost
+		if evt.Kept {
+			h.stats.Gambling.Kept++
+		}
+		switch evt.Item.Quality {
+		case item.QualityRare:
+			h.stats.Gambling.Rares++
+		case item.QualityUnique:
+			h.stats.Gambling.Uniques++
+		}
+
+	case event.MercDiedEvent:
+		h.stats.Merc.Deaths++
+
+	case event.MercRevivedEvent:
+		if evt.Rehired {
+			h.stats.Merc.Rehires++
+		} else {
+			h.stats.Merc.Revives++
+		}
+		h.stats.Merc.GoldSpent += evt.Cost
+
+	case event.MercEquippedEvent:
+		h.stats.Merc.Equipped++
+
+	case event.MonsterAttackedEvent:
+		if len(h.stats.Games) > 0 && len(h.stats.Games[len(h.stats.Games)-1].Runs) > 0 {
+			lastRun := &h.stats.Games[len(h.stats.Games)-1].Runs[len(h.stats.Games[len(h.stats.Games)-1].Runs)-1]
+			if lastRun.Attacks == nil {
+				lastRun.Attacks = make(combat.Summary)
+			}
+			lastRun.Attacks.Add(evt.Attack)
+		}
+
 	case event.UsedPotionEvent:
 		if len(h.stats.Games) > 0 && len(h.stats.Games[len(h.stats.Games)-1].Runs) > 0 {
 			lastRun := &h.stats.Games[len(h.stats.Games)-1].Runs[len(h.stats.Games[len(h.stats.Games)-1].Runs)-1]
@@ -91,8 +148,17 @@ func (h *StatsHandler) Handle(_ context.Context, e event.Event) error {
 	return nil
 }
 
+func (h *StatsHandler) transition(s SupervisorStatus) {
+	if err := h.lifecycle.To(s); err != nil {
+		h.logger.Debug("Ignoring supervisor state change", "error", err)
+	}
+}
+
 func (h *StatsHandler) Stats() Stats {
-	return *h.stats
+	stats := *h.stats
+	stats.SupervisorStatus = h.lifecycle.State()
+
+	return stats
 }
 
 type Stats struct {
@@ -101,6 +167,32 @@ type Stats struct {
 	Details          string
 	Drops            []data.Drop
 	Games            []GameStats
+	Crafting         map[string]CraftingStats
+	Gambling         GamblingStats
+	Merc             MercStats
+	PendingConfig    []reload.Change
+}
+
+type GamblingStats struct {
+	Bought  int
+	Spent   int
+	Kept    int
+	Rares   int
+	Uniques int
+}
+
+type MercStats struct {
+	Deaths    int
+	Revives   int
+	Rehires   int
+	GoldSpent int
+	Equipped  int
+}
+
+type CraftingStats struct {
+	Crafted int
+	Kept    int
+	Sold    int
 }
 
 type GameStats struct {
@@ -117,6 +209,7 @@ type RunStats struct {
 	Items       []data.Item
 	FinishedAt  time.Time
 	UsedPotions []event.UsedPotionEvent
+	Attacks     combat.Summary
 }
 
 func (s Stats) TotalGames() int {
@@ -147,3 +240,21 @@ func (s Stats) totalRunsByReason(reason event.FinishReason) int {
 
 	return total
 }
+
+// Attacks returns the attack telemetry of all the runs, grouped by run name
+func (s Stats) Attacks() map[string]combat.Summary {
+	attacks := make(map[string]combat.Summary)
+	for _, g := range s.Games {
+		for _, r := range g.Runs {
+			if len(r.Attacks) == 0 {
+				continue
+			}
+			if attacks[r.Name] == nil {
+				attacks[r.Name] = make(combat.Summary)
+			}
+			attacks[r.Name].Merge(r.Attacks)
+		}
+	}
+
+	return attacks
+}
@@ -0,0 +1,234 @@
+package buff
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+)
+
+const (
+	// DefaultMargin is the time before the expected expiration when a buff is recast
+	DefaultMargin = 10 * time.Second
+	// Buffs are not recast during this time even if the state is not detected yet, it prevents double buffing because
+	// of network lag
+	recastDelay = 5 * time.Second
+)
+
+// Definition is the state granted by a buff skill and its expected duration. Durations are approximations from the
+// skill level, states are the source of truth: a buff is recast as soon as its state is gone.
+type Definition struct {
+	States   []state.State
+	base     time.Duration
+	perLevel time.Duration
+}
+
+// Duration returns the expected duration for the given skill level, 0 means it lasts until the state is gone, ex:
+// absorb shields like Bone Armor
+func (d Definition) Duration(level int) time.Duration {
+	if d.base == 0 && d.perLevel == 0 {
+		return 0
+	}
+
+	return d.base + d.perLevel*time.Duration(max(level, 1))
+}
+
+// Definitions contains the tracked buffs, skills not defined here (ex: summons) are cast once per game and then along
+// with the tracked ones
+var Definitions = map[skill.ID]Definition{
+	skill.BattleOrders:  {States: []state.State{state.Battleorders}, base: 20 * time.Second, perLevel: 10 * time.Second},
+	skill.BattleCommand: {States: []state.State{state.Battlecommand}, base: 0, perLevel: 10 * time.Second},
+	skill.Shout:         {States: []state.State{state.Shout}, base: 10 * time.Second, perLevel: 6 * time.Second},
+	skill.FrozenArmor:   {States: []state.State{state.Frozenarmor, state.Shiverarmor, state.Chillingarmor}, base: 132 * time.Second, perLevel: 12 * time.Second},
+	skill.ShiverArmor:   {States: []state.State{state.Shiverarmor, state.Frozenarmor, state.Chillingarmor}, base: 108 * time.Second, perLevel: 12 * time.Second},
+	skill.ChillingArmor: {States: []state.State{state.Chillingarmor, state.Frozenarmor, state.Shiverarmor}, base: 138 * time.Second, perLevel: 6 * time.Second},
+	skill.EnergyShield:  {States: []state.State{state.Energyshield}, base: 96 * time.Second, perLevel: 48 * time.Second},
+	skill.ThunderStorm:  {States: []state.State{state.Thunderstorm}, base: 24 * time.Second, perLevel: 8 * time.Second},
+	skill.HolyShield:    {States: []state.State{state.Holyshield}, base: 5 * time.Second, perLevel: 25 * time.Second},
+	skill.BurstOfSpeed:  {States: []state.State{state.Quickness}, base: 108 * time.Second, perLevel: 12 * time.Second},
+	skill.Fade:          {States: []state.State{state.Fade}, base: 108 * time.Second, perLevel: 12 * time.Second},
+	skill.BladeShield:   {States: []state.State{state.Bladeshield}, base: 15 * time.Second, perLevel: 5 * time.Second},
+	skill.Hurricane:     {States: []state.State{state.Hurricane}, base: 8 * time.Second, perLevel: 2 * time.Second},
+	skill.CycloneArmor:  {States: []state.State{state.Cyclonearmor}},
+	skill.BoneArmor:     {States: []state.State{state.Bonearmor}},
+}
+
+// Plan contains the buffs to cast, in the same order they were requested
+type Plan struct {
+	PreCTA []skill.ID
+	CTA    bool
+	Post   []skill.ID
+}
+
+// Empty returns true if there is nothing to cast
+func (p Plan) Empty() bool {
+	return len(p.PreCTA) == 0 && !p.CTA && len(p.Post) == 0
+}
+
+// Entry is a buff in the timeline
+type Entry struct {
+	Skill     string
+	CastAt    time.Time
+	ExpiresAt time.Time
+	// Remaining is the expected time left in seconds, 0 for buffs without a known duration
+	Remaining int
+}
+
+type cast struct {
+	at      time.Time
+	expires time.Time
+}
+
+// Scheduler tracks when each buff was cast and decides which ones should be recast
+type Scheduler struct {
+	// Margin is the time before the expected expiration when a buff is recast, DefaultMargin if not set
+	Margin time.Duration
+
+	mu    sync.Mutex
+	casts map[skill.ID]cast
+}
+
+// Casted stores a buff cast, level is used to calculate the expected duration
+func (s *Scheduler) Casted(id skill.ID, level int, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.casts == nil {
+		s.casts = make(map[skill.ID]cast)
+	}
+
+	c := cast{at: now}
+	if d := Definitions[id].Duration(level); d > 0 {
+		c.expires = now.Add(d)
+	}
+	s.casts[id] = c
+}
+
+// Reset forgets all the casts, buffs are lost when a new game is created
+func (s *Scheduler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.casts = nil
+}
+
+// Due returns true if the tracked buff is missing or about to expire, untracked buffs are only due by themselves
+// when they were not cast yet in the current game
+func (s *Scheduler) Due(id skill.ID, states state.States, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.due(id, states, now, s.margin())
+}
+
+func (s *Scheduler) due(id skill.ID, states state.States, now time.Time, margin time.Duration) bool {
+	def, tracked := Definitions[id]
+	c, casted := s.casts[id]
+	if !tracked {
+		return !casted
+	}
+
+	if casted && now.Sub(c.at) < recastDelay {
+		return false
+	}
+
+	if !slices.ContainsFunc(def.States, states.HasState) {
+		return true
+	}
+
+	return casted && !c.expires.IsZero() && c.expires.Sub(now) < margin
+}
+
+// Plan returns the buffs to cast. CTA (Battle Command and Battle Orders) is swapped only when Battle Orders is due,
+// or when it will be due soon and other buffs are being cast anyway, saving a second swap shortly after. Untracked
+// buffs are cast along with the tracked ones.
+func (s *Scheduler) Plan(preCTA, post []skill.ID, hasCTA bool, states state.States, now time.Time) Plan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	margin := s.margin()
+	plan := Plan{}
+
+	anyDue := false
+	for _, id := range append(slices.Clone(preCTA), post...) {
+		if s.due(id, states, now, margin) {
+			anyDue = true
+			break
+		}
+	}
+
+	if hasCTA {
+		plan.CTA = s.due(skill.BattleOrders, states, now, margin) || anyDue && s.due(skill.BattleOrders, states, now, margin*2)
+	}
+	if !anyDue && !plan.CTA {
+		return plan
+	}
+
+	include := func(id skill.ID) bool {
+		_, tracked := Definitions[id]
+		return !tracked || s.due(id, states, now, margin)
+	}
+	for _, id := range preCTA {
+		if include(id) {
+			plan.PreCTA = append(plan.PreCTA, id)
+		}
+	}
+	for _, id := range post {
+		if include(id) {
+			plan.Post = append(plan.Post, id)
+		}
+	}
+
+	return plan
+}
+
+// Bound returns the skills having a key binding, in the same order. Buffs without one can't be cast and would be
+// due forever, they must be removed before planning.
+func Bound(skills []skill.ID, hasKeyBinding func(skill.ID) bool) []skill.ID {
+	bound := make([]skill.ID, 0, len(skills))
+	for _, id := range skills {
+		if hasKeyBinding(id) {
+			bound = append(bound, id)
+		}
+	}
+
+	return bound
+}
+
+// Timeline returns the tracked casts sorted by expiration, buffs without a known duration go last
+func (s *Scheduler) Timeline(now time.Time) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make([]Entry, 0, len(s.casts))
+	for id, c := range s.casts {
+		e := Entry{Skill: skill.SkillNames[id], CastAt: c.at, ExpiresAt: c.expires}
+		if !c.expires.IsZero() {
+			e.Remaining = max(int(c.expires.Sub(now).Seconds()), 0)
+		}
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ExpiresAt.IsZero() != entries[j].ExpiresAt.IsZero() {
+			return !entries[i].ExpiresAt.IsZero()
+		}
+		if entries[i].ExpiresAt.Equal(entries[j].ExpiresAt) {
+			return entries[i].Skill < entries[j].Skill
+		}
+		return entries[i].ExpiresAt.Before(entries[j].ExpiresAt)
+	})
+
+	return entries
+}
+
+func (s *Scheduler) margin() time.Duration {
+	if s.Margin <= 0 {
+		return DefaultMargin
+	}
+
+	return s.Margin
+}
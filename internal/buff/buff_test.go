@@ -0,0 +1,174 @@
+package buff
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+)
+
+func TestDuration(t *testing.T) {
+	if d := Definitions[skill.BattleOrders].Duration(20); d != 220*time.Second {
+		t.Errorf("unexpected battle orders duration %s", d)
+	}
+	if d := Definitions[skill.BoneArmor].Duration(20); d != 0 {
+		t.Errorf("absorb shields should not have duration, got %s", d)
+	}
+}
+
+func TestDue(t *testing.T) {
+	now := time.Now()
+	s := &Scheduler{}
+
+	if !s.Due(skill.FrozenArmor, nil, now) {
+		t.Error("missing buff should be due")
+	}
+	if !s.Due(skill.Valkyrie, nil, now) {
+		t.Error("untracked buffs should be due when not cast yet")
+	}
+	s.Casted(skill.Valkyrie, 1, now)
+	if s.Due(skill.Valkyrie, nil, now.Add(time.Hour)) {
+		t.Error("untracked buffs should only be cast once by themselves")
+	}
+
+	// Frozen Armor level 1: 144s
+	s.Casted(skill.FrozenArmor, 1, now)
+	active := state.States{state.Frozenarmor}
+	if s.Due(skill.FrozenArmor, nil, now.Add(time.Second)) {
+		t.Error("buff should not be recast right after casting it, state may not be detected yet")
+	}
+	if s.Due(skill.FrozenArmor, active, now.Add(100*time.Second)) {
+		t.Error("active buff should not be due")
+	}
+	if !s.Due(skill.FrozenArmor, active, now.Add(136*time.Second)) {
+		t.Error("buff about to expire should be due")
+	}
+	if !s.Due(skill.FrozenArmor, nil, now.Add(30*time.Second)) {
+		t.Error("buff whose state is gone should be due")
+	}
+	if s.Due(skill.ShiverArmor, state.States{state.Frozenarmor}, now) {
+		t.Error("only one armor can be active")
+	}
+
+	s.Casted(skill.BoneArmor, 10, now)
+	if s.Due(skill.BoneArmor, state.States{state.Bonearmor}, now.Add(time.Hour)) {
+		t.Error("buffs without duration are only due when the state is gone")
+	}
+
+	s.Reset()
+	if s.Due(skill.FrozenArmor, active, now.Add(136*time.Second)) {
+		t.Error("expiration should be forgotten after reset")
+	}
+}
+
+func TestPlan(t *testing.T) {
+	now := time.Now()
+	pre := []skill.ID{skill.Valkyrie}
+	post := []skill.ID{skill.FrozenArmor, skill.ThunderStorm}
+	all := state.States{state.Battleorders, state.Battlecommand, state.Frozenarmor, state.Thunderstorm}
+
+	tests := []struct {
+		name     string
+		setup    func(s *Scheduler)
+		states   state.States
+		at       time.Duration
+		expected Plan
+	}{
+		{
+			name:     "first buff casts everything",
+			setup:    func(s *Scheduler) {},
+			expected: Plan{PreCTA: pre, CTA: true, Post: post},
+		},
+		{
+			name: "nothing expiring",
+			setup: func(s *Scheduler) {
+				s.Casted(skill.Valkyrie, 1, now)
+				s.Casted(skill.BattleOrders, 20, now)
+				s.Casted(skill.FrozenArmor, 20, now)
+				s.Casted(skill.ThunderStorm, 20, now)
+			},
+			states:   all,
+			at:       60 * time.Second,
+			expected: Plan{},
+		},
+		{
+			name: "only expiring buff, no CTA swap",
+			setup: func(s *Scheduler) {
+				s.Casted(skill.Valkyrie, 1, now)
+				s.Casted(skill.BattleOrders, 20, now)
+				s.Casted(skill.FrozenArmor, 20, now)
+				s.Casted(skill.ThunderStorm, 1, now)
+			},
+			states:   all,
+			at:       25 * time.Second,
+			expected: Plan{PreCTA: pre, Post: []skill.ID{skill.ThunderStorm}},
+		},
+		{
+			name: "battle orders expiring swaps CTA only",
+			setup: func(s *Scheduler) {
+				s.Casted(skill.Valkyrie, 1, now)
+				s.Casted(skill.BattleOrders, 1, now)
+				s.Casted(skill.FrozenArmor, 20, now)
+				s.Casted(skill.ThunderStorm, 20, now)
+			},
+			states:   all,
+			at:       25 * time.Second,
+			expected: Plan{PreCTA: pre, CTA: true},
+		},
+		{
+			name: "battle orders expiring soon is bundled with other buffs",
+			setup: func(s *Scheduler) {
+				s.Casted(skill.Valkyrie, 1, now)
+				s.Casted(skill.BattleOrders, 2, now)
+				s.Casted(skill.FrozenArmor, 20, now)
+				s.Casted(skill.ThunderStorm, 1, now)
+			},
+			states:   all,
+			at:       25 * time.Second,
+			expected: Plan{PreCTA: pre, CTA: true, Post: []skill.ID{skill.ThunderStorm}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &Scheduler{}
+			tc.setup(s)
+			got := s.Plan(pre, post, true, tc.states, now.Add(tc.at))
+			if got.CTA != tc.expected.CTA || !slices.Equal(got.PreCTA, tc.expected.PreCTA) || !slices.Equal(got.Post, tc.expected.Post) {
+				t.Errorf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	now := time.Now()
+	s := &Scheduler{}
+	s.Casted(skill.BoneArmor, 1, now)
+	s.Casted(skill.FrozenArmor, 1, now)
+	s.Casted(skill.BattleOrders, 1, now)
+
+	timeline := s.Timeline(now.Add(10 * time.Second))
+	if len(timeline) != 3 || timeline[0].Skill != skill.SkillNames[skill.BattleOrders] || timeline[0].Remaining != 20 || timeline[2].Skill != skill.SkillNames[skill.BoneArmor] {
+		t.Errorf("unexpected timeline %+v", timeline)
+	}
+}
+
+func TestBoundSkipsBuffsWithoutKeyBinding(t *testing.T) {
+	now := time.Now()
+	s := &Scheduler{}
+	hasKeyBinding := func(id skill.ID) bool { return id != skill.ThunderStorm }
+
+	post := Bound([]skill.ID{skill.FrozenArmor, skill.ThunderStorm}, hasKeyBinding)
+	if !slices.Equal(post, []skill.ID{skill.FrozenArmor}) {
+		t.Fatalf("unexpected bound skills %v", post)
+	}
+
+	// Thunder Storm is never cast, it must not keep the plan due once the bound buffs are active
+	s.Casted(skill.FrozenArmor, 20, now)
+	if plan := s.Plan(nil, post, false, state.States{state.Frozenarmor}, now.Add(time.Minute)); !plan.Empty() {
+		t.Errorf("expected empty plan, got %+v", plan)
+	}
+}
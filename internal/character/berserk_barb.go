@@ -14,6 +14,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/data/state"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/character/weapon"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 )
@@ -111,7 +112,14 @@ func (s *Berserker) PerformBerserkAttack(monsterID data.UnitID) {
 func (s *Berserker) FindItemOnNearbyCorpses(maxRange int) {
 	ctx := context.Get()
 	ctx.PauseIfNotPriority()
-	s.SwapToSlot(1)
+
+	// Find Item gear is only used while horking, attack gear is restored afterward
+	if ctx.CharacterCfg.Character.BerserkerBarb.FindItemSwitch {
+		if err := step.SwapToSet(weapon.FindItem); err != nil {
+			s.Logger.Warn("Failed to swap to Find Item gear", slog.String("error", err.Error()))
+		}
+		defer step.SwapToMainWeapon()
+	}
 
 	findItemKey, found := s.Data.KeyBindings.KeyBindingForSkill(skill.FindItem)
 	if !found {
@@ -188,27 +196,6 @@ func (s *Berserker) getOptimalClickPosition(corpse data.Monster) data.Position {
 	return data.Position{X: corpse.Position.X, Y: corpse.Position.Y + 1}
 }
 
-// slot 0 means lowest Gold Find, slot 1 means highest Gold Find
-// Presuming attack items will be on slot 0 and Goldfind items on slot 1
-// TODO find a way to get active inventory slot from memory.
-func (s *Berserker) SwapToSlot(slot int) {
-	ctx := context.Get()
-	if !ctx.CharacterCfg.Character.BerserkerBarb.FindItemSwitch {
-		return // Do nothing if FindItemSwitch is disabled
-	}
-
-	initialGF, _ := s.Data.PlayerUnit.FindStat(stat.GoldFind, 0)
-	ctx.HID.PressKey('W')
-	time.Sleep(100 * time.Millisecond)
-	ctx.RefreshGameData()
-	swappedGF, _ := s.Data.PlayerUnit.FindStat(stat.GoldFind, 0)
-
-	if (slot == 0 && swappedGF.Value > initialGF.Value) ||
-		(slot == 1 && swappedGF.Value < initialGF.Value) {
-		ctx.HID.PressKey('W') // Swap back if not in desired slot
-	}
-}
-
 func (s *Berserker) BuffSkills() []skill.ID {
 
 	skillsList := make([]skill.ID, 0)
@@ -11,6 +11,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/data/state"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/character/element"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 )
@@ -78,7 +79,13 @@ func (s BlizzardSorceress) KillMonsterSequence(
 			completedAttackLoops = 0
 		}
 
-		if !s.preBattleChecks(id, skipOnImmunities) {
+		// Blizzard + Lightning hybrids switch to lightning against cold immunes instead of skipping them
+		attackSkill, canAttack := s.chooseSkill(id, skipOnImmunities,
+			element.Skill{ID: skill.Blizzard, Damage: 2},
+			element.Skill{ID: skill.ChainLightning},
+			element.Skill{ID: skill.Lightning},
+		)
+		if !canAttack {
 			return nil
 		}
 
@@ -102,11 +109,15 @@ func (s BlizzardSorceress) KillMonsterSequence(
 			}
 		}
 
-		if s.Data.PlayerUnit.States.HasState(state.Cooldown) {
-			step.PrimaryAttack(id, 2, true, lsOpts)
-		}
+		if attackSkill != skill.Blizzard {
+			step.SecondaryAttack(attackSkill, id, 2, lsOpts)
+		} else {
+			if s.Data.PlayerUnit.States.HasState(state.Cooldown) {
+				step.PrimaryAttack(id, 2, true, lsOpts)
+			}
 
-		step.SecondaryAttack(skill.Blizzard, id, 1, blizzOpts)
+			step.SecondaryAttack(skill.Blizzard, id, 1, blizzOpts)
+		}
 
 		completedAttackLoops++
 		previousUnitID = int(id)
@@ -0,0 +1,392 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/character/weapon"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultMaxAttacksLoop = 20
+	defaultNearbyRadius   = 5
+)
+
+// Range modes, they map to the attack options used by the attack steps
+const (
+	// RangeFollow moves towards the target until it's in range
+	RangeFollow = "follow"
+	// RangeRanged attacks from the current position if the target is in range
+	RangeRanged = "ranged"
+	// RangeStationary repositions once and stands still while attacking
+	RangeStationary = "stationary"
+)
+
+// Cooldown conditions
+const (
+	CooldownReady  = "ready"
+	CooldownActive = "active"
+)
+
+// Bosses contains the valid keys for the per boss overrides, one for each Kill* method of the character
+var Bosses = []string{"countess", "andariel", "summoner", "duriel", "mephisto", "pindle", "nihlathak", "council", "izual", "diablo", "baal"}
+
+var resists = []stat.Resist{stat.ColdImmune, stat.FireImmune, stat.LightImmune, stat.PoisonImmune, stat.MagicImmune}
+
+// Build defines a character build: skills that need a key binding, buffs and the attack rotation
+type Build struct {
+	Name        string   `yaml:"name"`
+	KeyBindings []string `yaml:"keyBindings"`
+	PreCTABuffs []string `yaml:"preCTABuffs"`
+	Buffs       []string `yaml:"buffs"`
+	// MaxAttacksLoop is the max amount of rotations against the same target before moving to the next one
+	MaxAttacksLoop int             `yaml:"maxAttacksLoop"`
+	Rotation       []Attack        `yaml:"rotation"`
+	Bosses         map[string]Boss `yaml:"bosses"`
+
+	keyBindings []skill.ID
+	preCTABuffs []skill.ID
+	buffs       []skill.ID
+}
+
+// Attack is a rotation entry, first attack whose conditions are met will be used
+type Attack struct {
+	// Skill is the skill name as defined in d2go (ex: Blizzard, GlacialSpike), required for right click attacks
+	Skill string `yaml:"skill"`
+	// Primary attacks with the left skill, whatever it is, instead of binding Skill to the right click
+	Primary bool   `yaml:"primary"`
+	Casts   int    `yaml:"casts"`
+	Aura    string `yaml:"aura"`
+	// Slot is the weapon set used for the attack (1 or 2), weapons are swapped back when the target is dead.
+	// Empty value uses the main weapon set, see weapon.Sets
+	Slot  weapon.Slot `yaml:"slot"`
+	Range Range       `yaml:"range"`
+	When  When        `yaml:"when"`
+
+	skill skill.ID
+	aura  skill.ID
+}
+
+// Range defines the distance to the target used to cast the skill
+type Range struct {
+	Min  int    `yaml:"min"`
+	Max  int    `yaml:"max"`
+	Mode string `yaml:"mode"`
+}
+
+// When contains the conditions required to use an attack, all of them must be met, empty values are ignored
+type When struct {
+	// Cooldown can be "ready" (no skill cooldown active) or "active"
+	Cooldown    string        `yaml:"cooldown"`
+	NotImmuneTo []stat.Resist `yaml:"notImmuneTo"`
+	ImmuneTo    []stat.Resist `yaml:"immuneTo"`
+	MinDistance int           `yaml:"minDistance"`
+	MaxDistance int           `yaml:"maxDistance"`
+	// MinMonstersNearby is the amount of enemies (target included) in NearbyRadius around the target
+	MinMonstersNearby int `yaml:"minMonstersNearby"`
+	NearbyRadius      int `yaml:"nearbyRadius"`
+}
+
+// Boss overrides the default behavior for a specific Kill* method
+type Boss struct {
+	// Opening attacks are used once before starting the rotation, ex: Static Field
+	Opening []Attack `yaml:"opening"`
+	// Rotation replaces the default rotation when defined
+	Rotation []Attack `yaml:"rotation"`
+}
+
+// State is the information about the current target used to evaluate the attack conditions
+type State struct {
+	CooldownActive bool
+	Immunities     []stat.Resist
+	Distance       int
+	// Nearby returns the amount of enemies in the given radius around the target
+	Nearby func(radius int) int
+}
+
+// Load reads and validates a build file
+func Load(path string) (*Build, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading build file %s: %w", path, err)
+	}
+
+	bld, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return bld, nil
+}
+
+// Parse reads and validates a build definition
+func Parse(b []byte) (*Build, error) {
+	bld := &Build{}
+	if err := yaml.Unmarshal(b, bld); err != nil {
+		return nil, fmt.Errorf("error parsing build: %w", err)
+	}
+
+	if err := bld.compile(); err != nil {
+		return nil, err
+	}
+
+	return bld, nil
+}
+
+// RequiredKeyBindings returns the skills that need a key binding, rotation and buff skills are included
+func (b *Build) RequiredKeyBindings() []skill.ID {
+	return b.keyBindings
+}
+
+func (b *Build) BuffSkills() []skill.ID {
+	return b.buffs
+}
+
+func (b *Build) PreCTABuffSkills() []skill.ID {
+	return b.preCTABuffs
+}
+
+// BossRotation returns the rotation for the given boss, falling back to the default one
+func (b *Build) BossRotation(boss string) []Attack {
+	if o, found := b.Bosses[boss]; found && len(o.Rotation) > 0 {
+		return o.Rotation
+	}
+
+	return b.Rotation
+}
+
+// BossOpening returns the attacks used once before attacking the given boss
+func (b *Build) BossOpening(boss string) []Attack {
+	return b.Bosses[boss].Opening
+}
+
+// Select returns the first attack in the rotation whose conditions are met
+func Select(rotation []Attack, s State) (Attack, bool) {
+	for _, a := range rotation {
+		if a.When.Match(s) {
+			return a, true
+		}
+	}
+
+	return Attack{}, false
+}
+
+// Match returns true if all the conditions are met for the given state
+func (w When) Match(s State) bool {
+	switch w.Cooldown {
+	case CooldownReady:
+		if s.CooldownActive {
+			return false
+		}
+	case CooldownActive:
+		if !s.CooldownActive {
+			return false
+		}
+	}
+
+	for _, r := range w.NotImmuneTo {
+		if slices.Contains(s.Immunities, r) {
+			return false
+		}
+	}
+	for _, r := range w.ImmuneTo {
+		if !slices.Contains(s.Immunities, r) {
+			return false
+		}
+	}
+
+	if w.MinDistance > 0 && s.Distance < w.MinDistance {
+		return false
+	}
+	if w.MaxDistance > 0 && s.Distance > w.MaxDistance {
+		return false
+	}
+
+	if w.MinMonstersNearby > 0 && (s.Nearby == nil || s.Nearby(w.NearbyRadius) < w.MinMonstersNearby) {
+		return false
+	}
+
+	return true
+}
+
+// SkillID returns the resolved skill, skill.Unset for primary attacks without a skill defined
+func (a Attack) SkillID() skill.ID {
+	return a.skill
+}
+
+// AuraID returns the resolved aura, skill.Unset if not defined
+func (a Attack) AuraID() skill.ID {
+	return a.aura
+}
+
+func (b *Build) compile() error {
+	if len(b.Rotation) == 0 {
+		return errors.New("build has no rotation defined")
+	}
+	if b.MaxAttacksLoop == 0 {
+		b.MaxAttacksLoop = defaultMaxAttacksLoop
+	}
+
+	var err error
+	if b.keyBindings, err = skillIDs(b.KeyBindings); err != nil {
+		return fmt.Errorf("keyBindings: %w", err)
+	}
+	if b.preCTABuffs, err = skillIDs(b.PreCTABuffs); err != nil {
+		return fmt.Errorf("preCTABuffs: %w", err)
+	}
+	if b.buffs, err = skillIDs(b.Buffs); err != nil {
+		return fmt.Errorf("buffs: %w", err)
+	}
+
+	if err = compileRotation(b.Rotation); err != nil {
+		return fmt.Errorf("rotation %w", err)
+	}
+
+	for name, boss := range b.Bosses {
+		if !slices.Contains(Bosses, name) {
+			return fmt.Errorf("unknown boss %s, allowed values: %s", name, strings.Join(Bosses, ", "))
+		}
+		if err = compileRotation(boss.Opening); err != nil {
+			return fmt.Errorf("boss %s opening %w", name, err)
+		}
+		if err = compileRotation(boss.Rotation); err != nil {
+			return fmt.Errorf("boss %s rotation %w", name, err)
+		}
+	}
+
+	// Every skill used by the build needs a key binding, except the primary ones
+	b.keyBindings = appendUnique(b.keyBindings, b.preCTABuffs...)
+	b.keyBindings = appendUnique(b.keyBindings, b.buffs...)
+	for _, a := range b.attacks() {
+		if !a.Primary {
+			b.keyBindings = appendUnique(b.keyBindings, a.skill)
+		}
+		if a.aura != skill.Unset {
+			b.keyBindings = appendUnique(b.keyBindings, a.aura)
+		}
+	}
+
+	return nil
+}
+
+func (b *Build) attacks() []Attack {
+	attacks := slices.Clone(b.Rotation)
+	for _, boss := range b.Bosses {
+		attacks = append(attacks, boss.Opening...)
+		attacks = append(attacks, boss.Rotation...)
+	}
+
+	return attacks
+}
+
+func compileRotation(rotation []Attack) error {
+	for i := range rotation {
+		if err := rotation[i].compile(); err != nil {
+			return fmt.Errorf("attack #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *Attack) compile() error {
+	a.skill = skill.Unset
+	a.aura = skill.Unset
+
+	if a.Skill == "" && !a.Primary {
+		return errors.New("skill is required for non primary attacks")
+	}
+	if a.Skill != "" {
+		id, found := skillByName(a.Skill)
+		if !found {
+			return fmt.Errorf("unknown skill %s", a.Skill)
+		}
+		a.skill = id
+	}
+	if a.Aura != "" {
+		id, found := skillByName(a.Aura)
+		if !found {
+			return fmt.Errorf("unknown aura %s", a.Aura)
+		}
+		a.aura = id
+	}
+
+	if !a.Slot.Valid() {
+		return fmt.Errorf("invalid weapon slot %d", a.Slot)
+	}
+
+	if a.Casts == 0 {
+		a.Casts = 1
+	}
+	if a.Casts < 0 {
+		return errors.New("casts can not be negative")
+	}
+
+	switch a.Range.Mode {
+	case "":
+		a.Range.Mode = RangeFollow
+	case RangeFollow, RangeRanged, RangeStationary:
+	default:
+		return fmt.Errorf("unknown range mode %s", a.Range.Mode)
+	}
+	if a.Range.Min < 0 || a.Range.Max < a.Range.Min {
+		return fmt.Errorf("invalid range %d-%d", a.Range.Min, a.Range.Max)
+	}
+
+	switch a.When.Cooldown {
+	case "", CooldownReady, CooldownActive:
+	default:
+		return fmt.Errorf("unknown cooldown condition %s", a.When.Cooldown)
+	}
+	for _, r := range append(slices.Clone(a.When.ImmuneTo), a.When.NotImmuneTo...) {
+		if !slices.Contains(resists, r) {
+			return fmt.Errorf("unknown immunity %s", r)
+		}
+	}
+	if a.When.NearbyRadius == 0 {
+		a.When.NearbyRadius = defaultNearbyRadius
+	}
+
+	return nil
+}
+
+func skillIDs(names []string) ([]skill.ID, error) {
+	ids := make([]skill.ID, 0, len(names))
+	for _, n := range names {
+		id, found := skillByName(n)
+		if !found {
+			return nil, fmt.Errorf("unknown skill %s", n)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// skillByName resolves d2go skill names, case and spaces are ignored so "Glacial Spike" is also valid
+func skillByName(name string) (skill.ID, bool) {
+	normalized := strings.ToLower(strings.ReplaceAll(name, " ", ""))
+	for id, n := range skill.SkillNames {
+		if strings.ToLower(n) == normalized {
+			return id, true
+		}
+	}
+
+	return skill.Unset, false
+}
+
+func appendUnique(ids []skill.ID, add ...skill.ID) []skill.ID {
+	for _, id := range add {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
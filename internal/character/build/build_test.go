@@ -0,0 +1,167 @@
+package build
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/koolo/internal/character/weapon"
+)
+
+const blizzardBuild = `
+name: Blizzard Sorceress
+keyBindings: [ Teleport, StaticField ]
+buffs: [ Energy Shield, ShiverArmor ]
+rotation:
+  - skill: Blizzard
+    range: { min: 8, max: 20, mode: stationary }
+    when: { cooldown: ready, notImmuneTo: [ cold ] }
+  - primary: true
+    casts: 2
+    range: { min: 6, max: 15 }
+    when: { notImmuneTo: [ cold ] }
+  - skill: FrozenOrb
+    when: { minMonstersNearby: 3, nearbyRadius: 6 }
+bosses:
+  diablo:
+    opening:
+      - skill: StaticField
+        casts: 5
+        range: { min: 3, max: 8 }
+`
+
+func TestParse(t *testing.T) {
+	b, err := Parse([]byte(blizzardBuild))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b.MaxAttacksLoop != defaultMaxAttacksLoop {
+		t.Errorf("expected default max attacks loop, got %d", b.MaxAttacksLoop)
+	}
+	if !slices.Equal(b.BuffSkills(), []skill.ID{skill.EnergyShield, skill.ShiverArmor}) {
+		t.Errorf("unexpected buffs %v", b.BuffSkills())
+	}
+
+	expected := []skill.ID{skill.Teleport, skill.StaticField, skill.EnergyShield, skill.ShiverArmor, skill.Blizzard, skill.FrozenOrb}
+	for _, id := range expected {
+		if !slices.Contains(b.RequiredKeyBindings(), id) {
+			t.Errorf("expected %v in required key bindings %v", id, b.RequiredKeyBindings())
+		}
+	}
+	if len(b.RequiredKeyBindings()) != len(expected) {
+		t.Errorf("unexpected key bindings %v", b.RequiredKeyBindings())
+	}
+
+	primary := b.Rotation[1]
+	if primary.SkillID() != skill.Unset || primary.Range.Mode != RangeFollow || primary.Casts != 2 {
+		t.Errorf("unexpected primary attack %+v", primary)
+	}
+	if b.Rotation[2].When.NearbyRadius != 6 || b.Rotation[0].When.NearbyRadius != defaultNearbyRadius {
+		t.Errorf("unexpected nearby radius")
+	}
+
+	if len(b.BossOpening("diablo")) != 1 || b.BossOpening("diablo")[0].SkillID() != skill.StaticField {
+		t.Errorf("unexpected diablo opening %v", b.BossOpening("diablo"))
+	}
+	if len(b.BossRotation("diablo")) != len(b.Rotation) {
+		t.Errorf("boss without rotation override should use the default one")
+	}
+}
+
+func TestParseWeaponSlot(t *testing.T) {
+	b, err := Parse([]byte("rotation: [ { skill: Blizzard }, { skill: LowerResist, slot: 2 } ]"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b.Rotation[0].Slot != weapon.Default || b.Rotation[1].Slot != weapon.II {
+		t.Errorf("unexpected weapon slots")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"no rotation":    `name: test`,
+		"unknown skill":  `rotation: [ { skill: Fireballz } ]`,
+		"missing skill":  `rotation: [ { casts: 2 } ]`,
+		"unknown buff":   "buffs: [ Foo ]\nrotation: [ { primary: true } ]",
+		"unknown mode":   `rotation: [ { primary: true, range: { mode: teleport } } ]`,
+		"invalid range":  `rotation: [ { primary: true, range: { min: 10, max: 5 } } ]`,
+		"invalid slot":   `rotation: [ { primary: true, slot: 3 } ]`,
+		"unknown resist": `rotation: [ { primary: true, when: { notImmuneTo: [ physical ] } } ]`,
+		"cooldown":       `rotation: [ { primary: true, when: { cooldown: sometimes } } ]`,
+		"unknown boss":   "rotation: [ { primary: true } ]\nbosses: { andy: { rotation: [ { primary: true } ] } }",
+		"boss attack":    "rotation: [ { primary: true } ]\nbosses: { andariel: { opening: [ { skill: Nope } ] } }",
+	}
+
+	for name, def := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(def)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	b, err := Parse([]byte(blizzardBuild))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nearby := func(count int) func(int) int {
+		return func(radius int) int {
+			if radius != 6 {
+				t.Errorf("unexpected radius %d", radius)
+			}
+			return count
+		}
+	}
+
+	tests := []struct {
+		name     string
+		state    State
+		expected skill.ID
+		primary  bool
+		found    bool
+	}{
+		{name: "blizzard ready", state: State{Distance: 10}, expected: skill.Blizzard, found: true},
+		{name: "cooldown uses primary", state: State{CooldownActive: true}, primary: true, found: true},
+		{name: "cold immune with pack", state: State{Immunities: []stat.Resist{stat.ColdImmune}, Nearby: nearby(4)}, expected: skill.FrozenOrb, found: true},
+		{name: "cold immune alone", state: State{Immunities: []stat.Resist{stat.ColdImmune}, Nearby: nearby(1)}, found: false},
+		{name: "other immunities ignored", state: State{Immunities: []stat.Resist{stat.FireImmune}}, expected: skill.Blizzard, found: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a, found := Select(b.Rotation, tc.state)
+			if found != tc.found {
+				t.Fatalf("expected found %t, got %t", tc.found, found)
+			}
+			if !found {
+				return
+			}
+			if a.Primary != tc.primary || (!tc.primary && a.SkillID() != tc.expected) {
+				t.Errorf("unexpected attack %+v", a)
+			}
+		})
+	}
+}
+
+func TestWhenDistance(t *testing.T) {
+	w := When{MinDistance: 3, MaxDistance: 10}
+	for distance, expected := range map[int]bool{1: false, 3: true, 10: true, 11: false} {
+		if w.Match(State{Distance: distance}) != expected {
+			t.Errorf("distance %d: expected %t", distance, expected)
+		}
+	}
+
+	if !(When{ImmuneTo: []stat.Resist{stat.FireImmune}}).Match(State{Immunities: []stat.Resist{stat.FireImmune}}) {
+		t.Error("immuneTo should match immune targets")
+	}
+	if (When{Cooldown: CooldownActive}).Match(State{}) {
+		t.Error("cooldown active should not match without cooldown")
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"strings"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/koolo/internal/character/element"
 	"github.com/hectorgimenez/koolo/internal/context"
 )
 
@@ -59,6 +62,11 @@ func BuildCharacter(ctx *context.Context) (context.Character, error) {
 		return Javazon{BaseCharacter: bc}, nil
 	case "berserker":
 		return &Berserker{BaseCharacter: bc}, nil // Return a pointer to Berserker
+	case "custom":
+		if ctx.CharacterCfg.Runtime.Build == nil {
+			return nil, fmt.Errorf("custom class requires a build file")
+		}
+		return ConfigurableCharacter{BaseCharacter: bc, build: ctx.CharacterCfg.Runtime.Build}, nil
 	}
 
 	return nil, fmt.Errorf("class %s not implemented", ctx.CharacterCfg.Character.Class)
@@ -82,3 +90,101 @@ func (bc BaseCharacter) preBattleChecks(id data.UnitID, skipOnImmunities []stat.
 
 	return true
 }
+
+const (
+	defaultConvictionReduction  = 85
+	defaultLowerResistReduction = 50
+)
+
+// chooseSkill returns the bound skill with the highest effective damage against the monster, so hybrid builds switch
+// skills instead of skipping immunes. When none of them can damage the monster, it's skipped if it's immune to any of
+// skipOnImmunities, otherwise the first skill is used (merc may still kill it).
+func (bc BaseCharacter) chooseSkill(id data.UnitID, skipOnImmunities []stat.Resist, skills ...element.Skill) (skill.ID, bool) {
+	monster, found := bc.Data.Monsters.FindByID(id)
+	if !found || len(skills) == 0 {
+		return skill.Unset, false
+	}
+
+	bound := make([]element.Skill, 0, len(skills))
+	for _, s := range skills {
+		if _, found := bc.Data.KeyBindings.KeyBindingForSkill(s.ID); found {
+			s.Pierce = bc.pierce(element.Skills[s.ID])
+			bound = append(bound, s)
+		}
+	}
+
+	if s, found := element.Choose(bound, bc.elementTarget(monster)); found {
+		if s.ID != skills[0].ID {
+			bc.Logger.Debug("Switching skill based on monster resists", slog.String("skill", skill.SkillNames[s.ID]), slog.Any("monster", monster.Name))
+		}
+		return s.ID, true
+	}
+
+	if !bc.preBattleChecks(id, skipOnImmunities) {
+		return skill.Unset, false
+	}
+
+	return skills[0].ID, true
+}
+
+// canDamage returns true if the monster is not immune to the skill element, taking into account the resist reductions
+func (bc BaseCharacter) canDamage(monster data.Monster, sk skill.ID) bool {
+	e := element.Skills[sk]
+
+	return !element.IsImmune(e, bc.elementTarget(monster), bc.pierce(e))
+}
+
+// elementTarget returns the monster resists, Conviction and Lower Resist levels are not exposed so the configured
+// reductions are used when the monster is affected by them
+func (bc BaseCharacter) elementTarget(monster data.Monster) element.Target {
+	t := element.Target{
+		Resists: map[stat.Resist]int{
+			stat.ColdImmune:   monster.Stats[stat.ColdResist],
+			stat.FireImmune:   monster.Stats[stat.FireResist],
+			stat.LightImmune:  monster.Stats[stat.LightningResist],
+			stat.PoisonImmune: monster.Stats[stat.PoisonResist],
+			stat.MagicImmune:  monster.Stats[stat.MagicResist],
+		},
+	}
+
+	cfg := bc.CharacterCfg.Character.ResistReduction
+	if monster.States.HasState(state.Convicted) {
+		t.Conviction = cfg.Conviction
+		if t.Conviction == 0 {
+			t.Conviction = defaultConvictionReduction
+		}
+	}
+	if monster.States.HasState(state.Lowerresist) {
+		t.LowerResist = cfg.LowerResist
+		if t.LowerResist == 0 {
+			t.LowerResist = defaultLowerResistReduction
+		}
+	}
+
+	return t
+}
+
+// pierce returns the enemy resist reduction from masteries and items for the given element
+func (bc BaseCharacter) pierce(e stat.Resist) int {
+	var id stat.ID
+	switch e {
+	case stat.ColdImmune:
+		id = stat.EnemyColdResist
+	case stat.FireImmune:
+		id = stat.EnemyFireResist
+	case stat.LightImmune:
+		id = stat.EnemyLightningResist
+	case stat.PoisonImmune:
+		id = stat.EnemyPoisonResist
+	case stat.MagicImmune:
+		id = stat.PassiveMagicPierce
+	default:
+		return 0
+	}
+
+	if st, found := bc.Data.PlayerUnit.Stats.FindStat(id, 0); found {
+		return st.Value
+	}
+
+	return 0
+}
@@ -0,0 +1,320 @@
+package character
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/d2go/pkg/utils"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/character/build"
+	"github.com/hectorgimenez/koolo/internal/character/element"
+	"github.com/hectorgimenez/koolo/internal/character/weapon"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/game"
+)
+
+var immunities = []stat.Resist{stat.ColdImmune, stat.FireImmune, stat.LightImmune, stat.PoisonImmune, stat.MagicImmune}
+
+// ConfigurableCharacter is a generic character driven by a build file, see build.Build
+type ConfigurableCharacter struct {
+	BaseCharacter
+	build *build.Build
+}
+
+func (s ConfigurableCharacter) CheckKeyBindings() []skill.ID {
+	requireKeybindings := append([]skill.ID{skill.TomeOfTownPortal}, s.build.RequiredKeyBindings()...)
+	missingKeybindings := []skill.ID{}
+
+	for _, cskill := range requireKeybindings {
+		if _, found := s.Data.KeyBindings.KeyBindingForSkill(cskill); !found {
+			missingKeybindings = append(missingKeybindings, cskill)
+		}
+	}
+
+	if len(missingKeybindings) > 0 {
+		s.Logger.Debug("There are missing required key bindings.", slog.Any("Bindings", missingKeybindings))
+	}
+
+	return missingKeybindings
+}
+
+func (s ConfigurableCharacter) KillMonsterSequence(
+	monsterSelector func(d game.Data) (data.UnitID, bool),
+	skipOnImmunities []stat.Resist,
+) error {
+	return s.killSequence(s.build.Rotation, monsterSelector, skipOnImmunities)
+}
+
+func (s ConfigurableCharacter) killSequence(
+	rotation []build.Attack,
+	monsterSelector func(d game.Data) (data.UnitID, bool),
+	skipOnImmunities []stat.Resist,
+) error {
+	ctx := context.Get()
+	completedAttackLoops := 0
+	previousUnitID := 0
+
+	// Targets that can't be attacked, usually because they can't be reached, are skipped and the next one is selected
+	skipped := make(map[data.UnitID]bool)
+	selector := func(d game.Data) (data.UnitID, bool) {
+		if len(skipped) > 0 {
+			d.Monsters = slices.DeleteFunc(slices.Clone(d.Monsters), func(m data.Monster) bool { return skipped[m.UnitID] })
+		}
+
+		return monsterSelector(d)
+	}
+
+	// Switch gear is only used for the attacks requiring it, main weapons are restored when we are done
+	swapped := false
+	defer func() {
+		if swapped {
+			_ = step.SwapToMainWeapon()
+		}
+	}()
+
+	for {
+		// Pause if not priority
+		ctx.PauseIfNotPriority()
+
+		id, found := selector(*s.Data)
+		if !found {
+			return nil
+		}
+		if previousUnitID != int(id) {
+			completedAttackLoops = 0
+		}
+
+		if !s.preBattleChecks(id, skipOnImmunities) {
+			return nil
+		}
+
+		if completedAttackLoops >= s.build.MaxAttacksLoop {
+			return nil
+		}
+
+		monster, found := s.Data.Monsters.FindByID(id)
+		if !found {
+			s.Logger.Info("Monster not found", slog.String("monster", fmt.Sprintf("%v", monster)))
+			return nil
+		}
+
+		attack, found := build.Select(rotation, s.attackState(monster))
+		if !found {
+			s.Logger.Info("No attack in the rotation can be used against the monster, skipping", slog.String("monster", fmt.Sprintf("%v", monster.Name)))
+			return nil
+		}
+
+		if attack.Slot != weapon.Default {
+			swapped = true
+		}
+		if err := step.SwapToSlot(attack.Slot); err != nil {
+			return fmt.Errorf("error swapping weapons for %s: %w", attackName(attack), err)
+		}
+		if err := s.attack(attack, id); err != nil {
+			s.Logger.Debug("Attack failed, skipping the monster", slog.String("monster", fmt.Sprintf("%v", monster.Name)), slog.Any("error", err))
+			skipped[id] = true
+			continue
+		}
+
+		completedAttackLoops++
+		previousUnitID = int(id)
+	}
+}
+
+func (s ConfigurableCharacter) attackState(monster data.Monster) build.State {
+	st := build.State{
+		CooldownActive: s.Data.PlayerUnit.States.HasState(state.Cooldown),
+		Distance:       s.PathFinder.DistanceFromMe(monster.Position),
+		Nearby: func(radius int) int {
+			count := 0
+			for _, m := range s.Data.Monsters.Enemies() {
+				if utils.DistanceFromPoint(monster.Position, m.Position) <= radius {
+					count++
+				}
+			}
+			return count
+		},
+	}
+
+	// Immunities broken by Conviction or Lower Resist are not considered
+	target := s.elementTarget(monster)
+	for _, r := range immunities {
+		if element.IsImmune(r, target, s.pierce(r)) {
+			st.Immunities = append(st.Immunities, r)
+		}
+	}
+
+	return st
+}
+
+// attack uses the given attack against the monster, weapons should be swapped to the attack slot before
+func (s ConfigurableCharacter) attack(a build.Attack, id data.UnitID) error {
+	opts := make([]step.AttackOption, 0, 2)
+	switch a.Range.Mode {
+	case build.RangeRanged:
+		opts = append(opts, step.RangedDistance(a.Range.Min, a.Range.Max))
+	case build.RangeStationary:
+		opts = append(opts, step.StationaryDistance(a.Range.Min, a.Range.Max))
+	default:
+		opts = append(opts, step.Distance(a.Range.Min, a.Range.Max))
+	}
+	if a.AuraID() != skill.Unset {
+		opts = append(opts, step.EnsureAura(a.AuraID()))
+	}
+
+	if a.Primary {
+		return step.PrimaryAttack(id, a.Casts, a.Range.Mode == build.RangeStationary, opts...)
+	}
+
+	return step.SecondaryAttack(a.SkillID(), id, a.Casts, opts...)
+}
+
+func attackName(a build.Attack) string {
+	if a.Primary {
+		return "primary attack"
+	}
+
+	return a.Skill
+}
+
+// killBoss uses the boss opening attacks once and then the boss rotation until the boss is dead
+func (s ConfigurableCharacter) killBoss(boss string, selector func(d game.Data) (data.UnitID, bool), skipOnImmunities []stat.Resist) error {
+	if id, found := selector(*s.Data); found {
+		for _, a := range s.build.BossOpening(boss) {
+			if err := step.SwapToSlot(a.Slot); err != nil {
+				_ = step.SwapToMainWeapon()
+				return fmt.Errorf("error swapping weapons for %s: %w", attackName(a), err)
+			}
+			if err := s.attack(a, id); err != nil {
+				s.Logger.Debug("Boss opening attack failed", slog.String("boss", boss), slog.Any("error", err))
+				break
+			}
+		}
+	}
+
+	return s.killSequence(s.build.BossRotation(boss), selector, skipOnImmunities)
+}
+
+func (s ConfigurableCharacter) killMonsterByName(boss string, id npc.ID, monsterType data.MonsterType, skipOnImmunities []stat.Resist) error {
+	return s.killBoss(boss, func(d game.Data) (data.UnitID, bool) {
+		if m, found := d.Monsters.FindOne(id, monsterType); found && m.Stats[stat.Life] > 0 {
+			return m.UnitID, true
+		}
+
+		return 0, false
+	}, skipOnImmunities)
+}
+
+func (s ConfigurableCharacter) BuffSkills() []skill.ID {
+	return s.boundSkills(s.build.BuffSkills())
+}
+
+func (s ConfigurableCharacter) PreCTABuffSkills() []skill.ID {
+	return s.boundSkills(s.build.PreCTABuffSkills())
+}
+
+func (s ConfigurableCharacter) boundSkills(skills []skill.ID) []skill.ID {
+	skillsList := make([]skill.ID, 0, len(skills))
+	for _, sk := range skills {
+		if _, found := s.Data.KeyBindings.KeyBindingForSkill(sk); found {
+			skillsList = append(skillsList, sk)
+		}
+	}
+
+	return skillsList
+}
+
+func (s ConfigurableCharacter) KillCountess() error {
+	return s.killMonsterByName("countess", npc.DarkStalker, data.MonsterTypeSuperUnique, nil)
+}
+
+func (s ConfigurableCharacter) KillAndariel() error {
+	return s.killMonsterByName("andariel", npc.Andariel, data.MonsterTypeUnique, nil)
+}
+
+func (s ConfigurableCharacter) KillSummoner() error {
+	return s.killMonsterByName("summoner", npc.Summoner, data.MonsterTypeUnique, nil)
+}
+
+func (s ConfigurableCharacter) KillDuriel() error {
+	return s.killMonsterByName("duriel", npc.Duriel, data.MonsterTypeUnique, nil)
+}
+
+func (s ConfigurableCharacter) KillMephisto() error {
+	return s.killMonsterByName("mephisto", npc.Mephisto, data.MonsterTypeUnique, nil)
+}
+
+func (s ConfigurableCharacter) KillPindle() error {
+	return s.killMonsterByName("pindle", npc.DefiledWarrior, data.MonsterTypeSuperUnique, s.CharacterCfg.Game.Pindleskin.SkipOnImmunities)
+}
+
+func (s ConfigurableCharacter) KillNihlathak() error {
+	return s.killMonsterByName("nihlathak", npc.Nihlathak, data.MonsterTypeSuperUnique, nil)
+}
+
+func (s ConfigurableCharacter) KillCouncil() error {
+	return s.killBoss("council", func(d game.Data) (data.UnitID, bool) {
+		var councilMembers []data.Monster
+		for _, m := range d.Monsters.Enemies() {
+			if m.Name == npc.CouncilMember || m.Name == npc.CouncilMember2 || m.Name == npc.CouncilMember3 {
+				councilMembers = append(councilMembers, m)
+			}
+		}
+
+		sort.Slice(councilMembers, func(i, j int) bool {
+			return s.PathFinder.DistanceFromMe(councilMembers[i].Position) < s.PathFinder.DistanceFromMe(councilMembers[j].Position)
+		})
+
+		for _, m := range councilMembers {
+			return m.UnitID, true
+		}
+
+		return 0, false
+	}, nil)
+}
+
+func (s ConfigurableCharacter) KillIzual() error {
+	return s.killMonsterByName("izual", npc.Izual, data.MonsterTypeUnique, nil)
+}
+
+func (s ConfigurableCharacter) KillDiablo() error {
+	timeout := time.Second * 20
+	startTime := time.Now()
+	diabloFound := false
+
+	for {
+		if time.Since(startTime) > timeout && !diabloFound {
+			s.Logger.Error("Diablo was not found, timeout reached")
+			return nil
+		}
+
+		diablo, found := s.Data.Monsters.FindOne(npc.Diablo, data.MonsterTypeUnique)
+		if !found || diablo.Stats[stat.Life] <= 0 {
+			// Already dead
+			if diabloFound {
+				return nil
+			}
+
+			// Keep waiting...
+			time.Sleep(200 * time.Millisecond)
+			continue
+		}
+
+		diabloFound = true
+		s.Logger.Info("Diablo detected, attacking")
+
+		return s.killMonsterByName("diablo", npc.Diablo, data.MonsterTypeUnique, nil)
+	}
+}
+
+func (s ConfigurableCharacter) KillBaal() error {
+	return s.killMonsterByName("baal", npc.BaalCrab, data.MonsterTypeUnique, nil)
+}
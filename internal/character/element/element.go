@@ -0,0 +1,170 @@
+package element
+
+import (
+	"sort"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+// Physical is used for skills not affected by elemental resistances
+const Physical stat.Resist = "physical"
+
+const (
+	immunityThreshold = 100
+	minResist         = -100
+	// Resist reductions from auras and curses are applied at 1/5 against immune monsters
+	immuneReductionDivisor = 5
+)
+
+// Skills maps the common attack skills to their damage element
+var Skills = map[skill.ID]stat.Resist{
+	skill.IceBolt:          stat.ColdImmune,
+	skill.FrostNova:        stat.ColdImmune,
+	skill.IceBlast:         stat.ColdImmune,
+	skill.GlacialSpike:     stat.ColdImmune,
+	skill.Blizzard:         stat.ColdImmune,
+	skill.FrozenOrb:        stat.ColdImmune,
+	skill.BladesOfIce:      stat.ColdImmune,
+	skill.IceArrow:         stat.ColdImmune,
+	skill.FreezingArrow:    stat.ColdImmune,
+	skill.FireBolt:         stat.FireImmune,
+	skill.FireBall:         stat.FireImmune,
+	skill.FireWall:         stat.FireImmune,
+	skill.Meteor:           stat.FireImmune,
+	skill.Hydra:            stat.FireImmune,
+	skill.FistsOfFire:      stat.FireImmune,
+	skill.ChargedBolt:      stat.LightImmune,
+	skill.Lightning:        stat.LightImmune,
+	skill.ChainLightning:   stat.LightImmune,
+	skill.Nova:             stat.LightImmune,
+	skill.ThunderStorm:     stat.LightImmune,
+	skill.ClawsOfThunder:   stat.LightImmune,
+	skill.LightningSentry:  stat.LightImmune,
+	skill.ChargedStrike:    stat.LightImmune,
+	skill.LightningFury:    stat.LightImmune,
+	skill.FistOfTheHeavens: stat.LightImmune,
+	skill.PoisonNova:       stat.PoisonImmune,
+	skill.PoisonJavelin:    stat.PoisonImmune,
+	skill.BlessedHammer:    stat.MagicImmune,
+	skill.Tornado:          Physical,
+	skill.Hurricane:        stat.ColdImmune,
+}
+
+// Target contains the monster resists and the active resist reductions from auras and curses
+type Target struct {
+	Resists map[stat.Resist]int
+	// Conviction is the resist reduction from the Conviction aura, applies to cold, fire and lightning
+	Conviction int
+	// LowerResist is the resist reduction from the Lower Resist curse, applies to cold, fire, lightning and poison
+	LowerResist int
+}
+
+// Skill is an attack skill available to the character
+type Skill struct {
+	ID skill.ID
+	// Element defaults to the one defined in Skills, Physical if not found
+	Element stat.Resist
+	// Damage is the relative damage of the skill, used to prefer the main skill when several are effective
+	Damage float64
+	// Pierce is the enemy resist reduction from masteries and items, ex: Cold Mastery or Griffon's Eye
+	Pierce int
+}
+
+// EffectiveResist returns the target resist after reductions, values >= 100 mean immune. Auras and curses are
+// applied first (at 1/5 against immunes), pierce only applies when the target is not immune after them.
+func EffectiveResist(element stat.Resist, t Target, pierce int) int {
+	if element == Physical {
+		return 0
+	}
+
+	resist := t.Resists[element]
+	reduction := 0
+	switch element {
+	case stat.ColdImmune, stat.FireImmune, stat.LightImmune:
+		reduction = t.Conviction + t.LowerResist
+	case stat.PoisonImmune:
+		reduction = t.LowerResist
+	}
+
+	if resist >= immunityThreshold {
+		resist -= reduction / immuneReductionDivisor
+		if resist >= immunityThreshold {
+			return resist
+		}
+	} else {
+		resist -= reduction
+	}
+
+	resist -= pierce
+
+	return max(resist, minResist)
+}
+
+// EffectiveDamage returns the damage multiplier of the skill against the target, 0 if the target is immune
+func EffectiveDamage(s Skill, t Target) float64 {
+	resist := EffectiveResist(s.element(), t, s.Pierce)
+	if resist >= immunityThreshold {
+		return 0
+	}
+
+	damage := s.Damage
+	if damage == 0 {
+		damage = 1
+	}
+
+	return damage * float64(100-resist) / 100
+}
+
+// Rank returns the skills able to damage the target, sorted by effective damage. Skills with the same effective
+// damage keep the given order.
+func Rank(skills []Skill, t Target) []Skill {
+	type ranked struct {
+		skill  Skill
+		damage float64
+	}
+
+	effective := make([]ranked, 0, len(skills))
+	for _, s := range skills {
+		if dmg := EffectiveDamage(s, t); dmg > 0 {
+			effective = append(effective, ranked{skill: s, damage: dmg})
+		}
+	}
+
+	sort.SliceStable(effective, func(i, j int) bool {
+		return effective[i].damage > effective[j].damage
+	})
+
+	result := make([]Skill, 0, len(effective))
+	for _, r := range effective {
+		result = append(result, r.skill)
+	}
+
+	return result
+}
+
+// Choose returns the skill with the highest effective damage, false if the target is immune to all of them
+func Choose(skills []Skill, t Target) (Skill, bool) {
+	ranked := Rank(skills, t)
+	if len(ranked) == 0 {
+		return Skill{}, false
+	}
+
+	return ranked[0], true
+}
+
+// IsImmune returns true if the element can not damage the target even after the resist reductions
+func IsImmune(element stat.Resist, t Target, pierce int) bool {
+	return EffectiveResist(element, t, pierce) >= immunityThreshold
+}
+
+func (s Skill) element() stat.Resist {
+	if s.Element != "" {
+		return s.Element
+	}
+	if e, found := Skills[s.ID]; found {
+		return e
+	}
+
+	return Physical
+}
@@ -0,0 +1,73 @@
+package element
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/skill"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func TestEffectiveResist(t *testing.T) {
+	tests := []struct {
+		name     string
+		element  stat.Resist
+		target   Target
+		pierce   int
+		expected int
+	}{
+		{name: "no reductions", element: stat.ColdImmune, target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 40}}, expected: 40},
+		{name: "pierce", element: stat.ColdImmune, target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 40}}, pierce: 60, expected: -20},
+		{name: "floor", element: stat.ColdImmune, target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 0}, LowerResist: 70}, pierce: 100, expected: -100},
+		{name: "pierce does not break immunity", element: stat.ColdImmune, target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 110}}, pierce: 200, expected: 110},
+		{name: "conviction breaks immunity at 1/5", element: stat.FireImmune, target: Target{Resists: map[stat.Resist]int{stat.FireImmune: 110}, Conviction: 85}, expected: 93},
+		{name: "broken immunity applies pierce", element: stat.FireImmune, target: Target{Resists: map[stat.Resist]int{stat.FireImmune: 110}, Conviction: 85}, pierce: 20, expected: 73},
+		{name: "not enough to break", element: stat.LightImmune, target: Target{Resists: map[stat.Resist]int{stat.LightImmune: 150}, Conviction: 85, LowerResist: 50}, pierce: 50, expected: 123},
+		{name: "conviction ignored for poison", element: stat.PoisonImmune, target: Target{Resists: map[stat.Resist]int{stat.PoisonImmune: 50}, Conviction: 85, LowerResist: 30}, expected: 20},
+		{name: "magic not reduced", element: stat.MagicImmune, target: Target{Resists: map[stat.Resist]int{stat.MagicImmune: 20}, Conviction: 85, LowerResist: 30}, expected: 20},
+		{name: "physical", element: Physical, target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 100}}, expected: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := EffectiveResist(tc.element, tc.target, tc.pierce); got != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestChoose(t *testing.T) {
+	hybrid := []Skill{{ID: skill.Blizzard, Damage: 2}, {ID: skill.ChainLightning}}
+
+	tests := []struct {
+		name     string
+		target   Target
+		expected skill.ID
+		found    bool
+	}{
+		{name: "main skill preferred", target: Target{}, expected: skill.Blizzard, found: true},
+		{name: "cold immune switches", target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 100}}, expected: skill.ChainLightning, found: true},
+		{name: "high cold resist switches", target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 75}}, expected: skill.ChainLightning, found: true},
+		{name: "lower resist breaks cold immunity", target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 105, stat.LightImmune: 100}, LowerResist: 50}, expected: skill.Blizzard, found: true},
+		{name: "immune to all", target: Target{Resists: map[stat.Resist]int{stat.ColdImmune: 100, stat.LightImmune: 100}}, found: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, found := Choose(hybrid, tc.target)
+			if found != tc.found {
+				t.Fatalf("expected found %t, got %t", tc.found, found)
+			}
+			if found && s.ID != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, s.ID)
+			}
+		})
+	}
+}
+
+func TestRankKeepsOrderOnTies(t *testing.T) {
+	ranked := Rank([]Skill{{ID: skill.Hydra}, {ID: skill.FrozenOrb}, {ID: skill.Tornado}}, Target{Resists: map[stat.Resist]int{stat.FireImmune: 100}})
+	if len(ranked) != 2 || ranked[0].ID != skill.FrozenOrb || ranked[1].ID != skill.Tornado {
+		t.Errorf("unexpected ranking %v", ranked)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/data/state"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/character/element"
 	"github.com/hectorgimenez/koolo/internal/game"
 )
 
@@ -68,7 +69,12 @@ func (s HydraOrbSorceress) KillMonsterSequence(
 			completedAttackLoops = 0
 		}
 
-		if !s.preBattleChecks(id, skipOnImmunities) {
+		// Fire immunes are attacked with Frozen Orb and cold immunes with Hydra
+		attackSkill, canAttack := s.chooseSkill(id, skipOnImmunities,
+			element.Skill{ID: skill.FrozenOrb, Damage: 2},
+			element.Skill{ID: skill.Hydra},
+		)
+		if !canAttack {
 			return nil
 		}
 
@@ -95,11 +101,15 @@ func (s HydraOrbSorceress) KillMonsterSequence(
 		//	}
 		//}
 
-		if s.Data.PlayerUnit.States.HasState(state.Cooldown) {
+		if attackSkill == skill.Hydra {
 			step.SecondaryAttack(skill.Hydra, id, 1, opts)
-		}
+		} else {
+			if s.Data.PlayerUnit.States.HasState(state.Cooldown) && s.canDamage(monster, skill.Hydra) {
+				step.SecondaryAttack(skill.Hydra, id, 1, opts)
+			}
 
-		step.SecondaryAttack(skill.FrozenOrb, id, 1, opts)
+			step.SecondaryAttack(skill.FrozenOrb, id, 1, opts)
+		}
 
 		completedAttackLoops++
 		previousUnitID = int(id)
@@ -12,6 +12,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/data/state"
 	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/character/element"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 )
@@ -71,7 +72,22 @@ func (s MosaicSin) KillMonsterSequence(
 			return nil
 		}
 
-		if !s.preBattleChecks(id, skipOnImmunities) {
+		// Elemental charges the monster is immune to are not built, it's only skipped when none of them can damage it
+		chargeSkills := make([]element.Skill, 0, 3)
+		if ctx.CharacterCfg.Character.MosaicSin.UseClawsOfThunder && s.canDamage(monster, skill.ClawsOfThunder) {
+			chargeSkills = append(chargeSkills, element.Skill{ID: skill.ClawsOfThunder})
+		}
+		if ctx.CharacterCfg.Character.MosaicSin.UseBladesOfIce && s.canDamage(monster, skill.BladesOfIce) {
+			chargeSkills = append(chargeSkills, element.Skill{ID: skill.BladesOfIce})
+		}
+		if ctx.CharacterCfg.Character.MosaicSin.UseFistsOfFire && s.canDamage(monster, skill.FistsOfFire) {
+			chargeSkills = append(chargeSkills, element.Skill{ID: skill.FistsOfFire})
+		}
+		if len(chargeSkills) == 0 {
+			if !s.preBattleChecks(id, skipOnImmunities) {
+				return nil
+			}
+		} else if _, canAttack := s.chooseSkill(id, skipOnImmunities, chargeSkills...); !canAttack {
 			return nil
 		}
 
@@ -122,7 +138,7 @@ func (s MosaicSin) KillMonsterSequence(
 		}
 
 		// Claws of Thunder - 3 charges
-		if ctx.CharacterCfg.Character.MosaicSin.UseClawsOfThunder {
+		if ctx.CharacterCfg.Character.MosaicSin.UseClawsOfThunder && s.canDamage(monster, skill.ClawsOfThunder) {
 			if !s.Data.PlayerUnit.States.HasState(state.Clawsofthunder) || (foundClaws && clawsCharges.Value < 3) {
 				step.SecondaryAttack(skill.ClawsOfThunder, id, 1)
 				continue
@@ -134,7 +150,7 @@ func (s MosaicSin) KillMonsterSequence(
 		}
 
 		// Blades of Ice - 3 charges
-		if ctx.CharacterCfg.Character.MosaicSin.UseBladesOfIce {
+		if ctx.CharacterCfg.Character.MosaicSin.UseBladesOfIce && s.canDamage(monster, skill.BladesOfIce) {
 			if !s.Data.PlayerUnit.States.HasState(state.Bladesofice) || (foundBlades && bladesCharges.Value < 3) {
 				step.SecondaryAttack(skill.BladesOfIce, id, 1)
 				continue
@@ -142,7 +158,7 @@ func (s MosaicSin) KillMonsterSequence(
 		}
 
 		// First of Fire - 3 charges
-		if ctx.CharacterCfg.Character.MosaicSin.UseFistsOfFire {
+		if ctx.CharacterCfg.Character.MosaicSin.UseFistsOfFire && s.canDamage(monster, skill.FistsOfFire) {
 			if !s.Data.PlayerUnit.States.HasState(state.Fistsoffire) || (foundFirst && firstCharges.Value < 3) {
 				step.SecondaryAttack(skill.FistsOfFire, id, 1)
 				continue
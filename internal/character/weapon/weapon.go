@@ -0,0 +1,107 @@
+package weapon
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Slot is a weapon set as shown in game, I or II
+type Slot int
+
+const (
+	// Default is the main slot, see Sets.Main
+	Default Slot = 0
+	I       Slot = 1
+	II      Slot = 2
+)
+
+// FromActive converts the d2go active weapon slot, which is zero based
+func FromActive(active int) Slot {
+	if active == 1 {
+		return II
+	}
+
+	return I
+}
+
+func (s Slot) String() string {
+	switch s {
+	case I:
+		return "I"
+	case II:
+		return "II"
+	}
+
+	return "default"
+}
+
+// Valid returns true for Default, I and II
+func (s Slot) Valid() bool {
+	return s >= Default && s <= II
+}
+
+// Holding is what a weapon slot is used for
+type Holding string
+
+const (
+	// Attack is the gear used to kill monsters, the main slot
+	Attack    Holding = "attack"
+	CTA       Holding = "cta"
+	FindItem  Holding = "find_item"
+	MagicFind Holding = "magic_find"
+	LifeTap   Holding = "life_tap"
+)
+
+var holdings = []Holding{Attack, CTA, FindItem, MagicFind, LifeTap}
+
+// Sets records what each weapon slot holds
+type Sets struct {
+	I  []Holding `yaml:"slot1"`
+	II []Holding `yaml:"slot2"`
+}
+
+// SlotFor returns the slot holding the given gear. When not configured, attack gear is expected in slot I and
+// everything else (CTA, Find Item...) in slot II, as most players do.
+func (s Sets) SlotFor(h Holding) Slot {
+	switch {
+	case slices.Contains(s.I, h):
+		return I
+	case slices.Contains(s.II, h):
+		return II
+	case h == Attack:
+		return I
+	}
+
+	return II
+}
+
+// Main returns the slot that should be active when not using switch gear
+func (s Sets) Main() Slot {
+	return s.SlotFor(Attack)
+}
+
+// Resolve returns the slot to use, Default is resolved to the main slot
+func (s Sets) Resolve(slot Slot) Slot {
+	if slot == Default {
+		return s.Main()
+	}
+
+	return slot
+}
+
+// Validate checks for unknown holdings and gear declared in both slots
+func (s Sets) Validate() error {
+	for _, h := range append(slices.Clone(s.I), s.II...) {
+		if !slices.Contains(holdings, h) {
+			return fmt.Errorf("unknown weapon set holding %s", h)
+		}
+	}
+
+	for _, h := range s.I {
+		if slices.Contains(s.II, h) {
+			return fmt.Errorf("%s can not be in both weapon slots", h)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package weapon
+
+import "testing"
+
+func TestSlotFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		sets     Sets
+		holding  Holding
+		expected Slot
+	}{
+		{name: "default attack", holding: Attack, expected: I},
+		{name: "default cta", holding: CTA, expected: II},
+		{name: "default find item", holding: FindItem, expected: II},
+		{name: "configured", sets: Sets{I: []Holding{LifeTap}, II: []Holding{Attack, CTA}}, holding: LifeTap, expected: I},
+		{name: "configured attack", sets: Sets{I: []Holding{LifeTap}, II: []Holding{Attack, CTA}}, holding: Attack, expected: II},
+		{name: "not configured", sets: Sets{II: []Holding{CTA}}, holding: MagicFind, expected: II},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.sets.SlotFor(tc.holding); got != tc.expected {
+				t.Errorf("expected slot %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	sets := Sets{II: []Holding{Attack}}
+	if sets.Resolve(Default) != II || sets.Resolve(I) != I {
+		t.Error("unexpected resolved slot")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Sets{I: []Holding{Attack}, II: []Holding{CTA, FindItem}}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (Sets{I: []Holding{Attack}, II: []Holding{"hammer"}}).Validate(); err == nil {
+		t.Error("expected error for unknown holding")
+	}
+	if err := (Sets{I: []Holding{Attack, CTA}, II: []Holding{CTA}}).Validate(); err == nil {
+		t.Error("expected error for holding in both slots")
+	}
+}
+
+func TestFromActive(t *testing.T) {
+	if FromActive(0) != I || FromActive(1) != II {
+		t.Error("unexpected slot conversion")
+	}
+	if !Default.Valid() || !II.Valid() || Slot(3).Valid() {
+		t.Error("unexpected slot validation")
+	}
+}
@@ -0,0 +1,120 @@
+package combat
+
+import (
+	"sort"
+	"time"
+)
+
+// Attack is the outcome of attacking a single monster, from the first cast until it died or was abandoned
+type Attack struct {
+	Monster  string
+	Skill    string
+	Casts    int
+	Duration time.Duration
+	Damage   int
+	Killed   bool
+}
+
+// Entry aggregates the attacks done with a skill against a monster type
+type Entry struct {
+	Monster   string
+	Skill     string
+	Kills     int
+	Abandoned int
+	Casts     int
+	Time      time.Duration
+	KillTime  time.Duration
+	Damage    int
+}
+
+// TimeToKill returns the average time spent on monsters that were killed
+func (e Entry) TimeToKill() time.Duration {
+	if e.Kills == 0 {
+		return 0
+	}
+
+	return e.KillTime / time.Duration(e.Kills)
+}
+
+// CastsPerKill returns the average casts needed to kill a monster, casts on abandoned targets are also counted
+func (e Entry) CastsPerKill() float64 {
+	if e.Kills == 0 {
+		return 0
+	}
+
+	return float64(e.Casts) / float64(e.Kills)
+}
+
+// DPS returns the damage done per second while attacking
+func (e Entry) DPS() float64 {
+	if e.Time <= 0 {
+		return 0
+	}
+
+	return float64(e.Damage) / e.Time.Seconds()
+}
+
+// Summary keeps the attack entries grouped by monster type and skill
+type Summary map[string]*Entry
+
+func key(monster, skill string) string {
+	return monster + "|" + skill
+}
+
+// Add records a finished attack, attacks without casts are ignored since we didn't do anything
+func (s Summary) Add(a Attack) {
+	if a.Casts == 0 {
+		return
+	}
+
+	e, found := s[key(a.Monster, a.Skill)]
+	if !found {
+		e = &Entry{Monster: a.Monster, Skill: a.Skill}
+		s[key(a.Monster, a.Skill)] = e
+	}
+
+	if a.Killed {
+		e.Kills++
+		e.KillTime += a.Duration
+	} else {
+		e.Abandoned++
+	}
+	e.Casts += a.Casts
+	e.Time += a.Duration
+	e.Damage += a.Damage
+}
+
+// Merge adds all the entries from another summary
+func (s Summary) Merge(other Summary) {
+	for k, o := range other {
+		e, found := s[k]
+		if !found {
+			e = &Entry{Monster: o.Monster, Skill: o.Skill}
+			s[k] = e
+		}
+
+		e.Kills += o.Kills
+		e.Abandoned += o.Abandoned
+		e.Casts += o.Casts
+		e.Time += o.Time
+		e.KillTime += o.KillTime
+		e.Damage += o.Damage
+	}
+}
+
+// Entries returns the entries sorted by monster type and skill
+func (s Summary) Entries() []Entry {
+	entries := make([]Entry, 0, len(s))
+	for _, e := range s {
+		entries = append(entries, *e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Monster != entries[j].Monster {
+			return entries[i].Monster < entries[j].Monster
+		}
+		return entries[i].Skill < entries[j].Skill
+	})
+
+	return entries
+}
@@ -0,0 +1,62 @@
+package combat
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	s := Summary{}
+	s.Add(Attack{Monster: "champion", Skill: "Blizzard", Casts: 4, Duration: 2 * time.Second, Damage: 1000, Killed: true})
+	s.Add(Attack{Monster: "champion", Skill: "Blizzard", Casts: 2, Duration: time.Second, Damage: 600, Killed: true})
+	s.Add(Attack{Monster: "champion", Skill: "Blizzard", Casts: 3, Duration: 3 * time.Second, Damage: 200})
+	s.Add(Attack{Monster: "normal", Skill: "Glacial Spike", Casts: 0, Duration: time.Second})
+
+	entries := s.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+
+	e := entries[0]
+	if e.Kills != 2 || e.Abandoned != 1 || e.Casts != 9 {
+		t.Errorf("unexpected counters %+v", e)
+	}
+	if e.TimeToKill() != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s time to kill, got %s", e.TimeToKill())
+	}
+	if e.CastsPerKill() != 4.5 {
+		t.Errorf("expected 4.5 casts per kill, got %f", e.CastsPerKill())
+	}
+	if e.DPS() != 300 {
+		t.Errorf("expected 300 dps, got %f", e.DPS())
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := Summary{}
+	a.Add(Attack{Monster: "unique", Skill: "Blizzard", Casts: 10, Duration: 5 * time.Second, Damage: 5000, Killed: true})
+
+	b := Summary{}
+	b.Add(Attack{Monster: "unique", Skill: "Blizzard", Casts: 6, Duration: 3 * time.Second, Damage: 3000, Killed: true})
+	b.Add(Attack{Monster: "normal", Skill: "Blizzard", Casts: 1, Duration: time.Second, Damage: 100, Killed: true})
+
+	a.Merge(b)
+
+	entries := a.Entries()
+	if len(entries) != 2 || entries[0].Monster != "normal" || entries[1].Monster != "unique" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	if entries[1].Kills != 2 || entries[1].TimeToKill() != 4*time.Second {
+		t.Errorf("unexpected merged entry %+v", entries[1])
+	}
+	if len(b) != 2 || b[key("unique", "Blizzard")].Kills != 1 {
+		t.Errorf("merge should not modify the merged summary")
+	}
+}
+
+func TestEmptyEntry(t *testing.T) {
+	e := Entry{Abandoned: 2, Casts: 5}
+	if e.TimeToKill() != 0 || e.CastsPerKill() != 0 || e.DPS() != 0 {
+		t.Errorf("expected zero values without kills")
+	}
+}
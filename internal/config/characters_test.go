@@ -0,0 +1,35 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestCharactersConcurrentLoad is meant to be run with -race, configs are replaced on Load while the supervisors,
+// the scheduler and the UI read them
+func TestCharactersConcurrentLoad(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			setCharacters(map[string]*CharacterCfg{"sorc": {}}, map[string]InvalidCharacter{"pala": {}})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			for name, cfg := range Characters() {
+				if cfg == nil {
+					t.Errorf("%s: nil config", name)
+				}
+			}
+			_ = InvalidCharacters()["pala"]
+		}
+	}()
+	wg.Wait()
+
+	if _, found := Characters()["sorc"]; !found {
+		t.Error("expected the loaded characters")
+	}
+}
@@ -3,10 +3,20 @@ package config
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/character/build"
+	"github.com/hectorgimenez/koolo/internal/character/weapon"
+	"github.com/hectorgimenez/koolo/internal/config/profile"
+	"github.com/hectorgimenez/koolo/internal/corpse"
+	"github.com/hectorgimenez/koolo/internal/gamble"
+	"github.com/hectorgimenez/koolo/internal/health/curse"
+	"github.com/hectorgimenez/koolo/internal/target"
 	"github.com/hectorgimenez/koolo/internal/utils"
 
 	"os"
@@ -24,23 +34,62 @@ import (
 )
 
 var (
-	Koolo      *KooloCfg
-	Characters map[string]*CharacterCfg
-	Version    = "dev"
+	Koolo   *KooloCfg
+	Version = "dev"
+
+	// Character configs are replaced as a whole on Load while supervisors, the scheduler and the UI read them, the
+	// maps are never modified once loaded
+	charactersMu      sync.RWMutex
+	characters        map[string]*CharacterCfg
+	invalidCharacters map[string]InvalidCharacter
 )
 
+// Characters returns the loaded character configs, it's a snapshot replaced on every Load and must not be modified
+func Characters() map[string]*CharacterCfg {
+	charactersMu.RLock()
+	defer charactersMu.RUnlock()
+
+	return characters
+}
+
+// InvalidCharacters returns the character configs that failed to load, they can't be started until fixed
+func InvalidCharacters() map[string]InvalidCharacter {
+	charactersMu.RLock()
+	defer charactersMu.RUnlock()
+
+	return invalidCharacters
+}
+
+func setCharacters(valid map[string]*CharacterCfg, invalid map[string]InvalidCharacter) {
+	charactersMu.Lock()
+	defer charactersMu.Unlock()
+
+	characters = valid
+	invalidCharacters = invalid
+}
+
+// InvalidCharacter is a character config that failed to load, Config is nil when the file couldn't be read
+type InvalidCharacter struct {
+	Config *CharacterCfg
+	Err    error
+}
+
 type KooloCfg struct {
-	Debug struct {
+	// SchemaVersion is the version of the file format, older files are upgraded on Load, see kooloMigrations
+	SchemaVersion int `yaml:"schemaVersion"`
+	Debug         struct {
 		Log         bool `yaml:"log"`
 		Screenshots bool `yaml:"screenshots"`
 		RenderMap   bool `yaml:"renderMap"`
 	} `yaml:"debug"`
-	FirstRun              bool   `yaml:"firstRun"`
+	FirstRun bool `yaml:"firstRun"`
+	// Headless runs Koolo without the window, same as the -headless flag
+	Headless              bool   `yaml:"headless"`
 	UseCustomSettings     bool   `yaml:"useCustomSettings"`
 	GameWindowArrangement bool   `yaml:"gameWindowArrangement"`
 	LogSaveDirectory      string `yaml:"logSaveDirectory"`
-	D2LoDPath             string `yaml:"D2LoDPath"`
-	D2RPath               string `yaml:"D2RPath"`
+	D2LoDPath             string `yaml:"d2LoDPath"`
+	D2RPath               string `yaml:"d2RPath"`
 	CentralizedPickitPath string `yaml:"centralizedPickitPath"`
 	Discord               struct {
 		Enabled                      bool     `yaml:"enabled"`
@@ -49,6 +98,7 @@ type KooloCfg struct {
 		EnableRunFinishMessages      bool     `yaml:"enableRunFinishMessages"`
 		EnableDiscordChickenMessages bool     `yaml:"enableDiscordChickenMessages"`
 		EnableDiscordErrorMessages   bool     `yaml:"enableDiscordErrorMessages"`
+		EnableDiscordMercMessages    bool     `yaml:"enableDiscordMercMessages"`
 		BotAdmins                    []string `yaml:"botAdmins"`
 		ChannelID                    string   `yaml:"channelId"`
 		Token                        string   `yaml:"token"`
@@ -58,6 +108,20 @@ type KooloCfg struct {
 		ChatID  int64  `yaml:"chatId"`
 		Token   string `yaml:"token"`
 	}
+	// Fleet limits the supervisors started by the scheduler, see schedule.FleetPolicy
+	Fleet struct {
+		MaxConcurrent int `yaml:"maxConcurrent"`
+		// RotateAfter is the continuous play time in minutes after which a supervisor leaves its slot to a waiting one
+		RotateAfter int `yaml:"rotateAfter"`
+		// StartDelay is the min time in seconds between two supervisor starts
+		StartDelay int `yaml:"startDelay"`
+	} `yaml:"fleet"`
+	// Secrets configures the vault holding the secrets referenced as vault:{name} in the config files
+	Secrets struct {
+		VaultFile string `yaml:"vaultFile"`
+		// KeyFile unlocks the vault on startup, its content is used as passphrase
+		KeyFile string `yaml:"keyFile"`
+	} `yaml:"secrets"`
 }
 
 type Day struct {
@@ -68,6 +132,28 @@ type Day struct {
 type Scheduler struct {
 	Enabled bool  `yaml:"enabled"`
 	Days    []Day `yaml:"days"`
+	// Priority decides which supervisors run first when the fleet is limited, higher goes first
+	Priority int `yaml:"priority"`
+	// DailyBudget is the max play time in minutes for each day of the week (Sunday first), 0 means no limit
+	DailyBudget []int `yaml:"dailyBudget"`
+	// Jitter is the max random delay in minutes applied to starts and stops, play windows are never extended
+	Jitter int `yaml:"jitter"`
+	// Breaks pause the bot for a random time every random amount of continuous play, all values in minutes
+	Breaks struct {
+		EveryMin  int `yaml:"everyMin"`
+		EveryMax  int `yaml:"everyMax"`
+		LengthMin int `yaml:"lengthMin"`
+		LengthMax int `yaml:"lengthMax"`
+	} `yaml:"breaks"`
+	// Exceptions replace the weekly time ranges for a specific date, no time ranges means a day off
+	Exceptions []DateException `yaml:"exceptions"`
+	// Windows are one-off play windows, start and end are full dates in local time
+	Windows []TimeRange `yaml:"windows"`
+}
+
+type DateException struct {
+	Date       string      `yaml:"date"`
+	TimeRanges []TimeRange `yaml:"timeRange"`
 }
 
 type TimeRange struct {
@@ -76,19 +162,24 @@ type TimeRange struct {
 }
 
 type CharacterCfg struct {
-	MaxGameLength        int    `yaml:"maxGameLength"`
-	Username             string `yaml:"username"`
-	Password             string `yaml:"password"`
-	AuthMethod           string `yaml:"authMethod"`
-	AuthToken            string `yaml:"authToken"`
-	Realm                string `yaml:"realm"`
-	CharacterName        string `yaml:"characterName"`
-	CommandLineArgs      string `yaml:"commandLineArgs"`
-	KillD2OnStop         bool   `yaml:"killD2OnStop"`
-	ClassicMode          bool   `yaml:"classicMode"`
-	CloseMiniPanel       bool   `yaml:"closeMiniPanel"`
-	UseCentralizedPickit bool   `yaml:"useCentralizedPickit"`
-	HidePortraits        bool   `yaml:"hidePortraits"`
+	// SchemaVersion is the version of the file format, older files are upgraded on Load, see characterMigrations
+	SchemaVersion int `yaml:"schemaVersion"`
+	// Profiles are the shared profiles this config inherits from, see ProfilesDir. Later ones and the values in this
+	// config take precedence.
+	Profiles             []string `yaml:"profiles,omitempty"`
+	MaxGameLength        int      `yaml:"maxGameLength"`
+	Username             string   `yaml:"username"`
+	Password             string   `yaml:"password"`
+	AuthMethod           string   `yaml:"authMethod"`
+	AuthToken            string   `yaml:"authToken"`
+	Realm                string   `yaml:"realm"`
+	CharacterName        string   `yaml:"characterName"`
+	CommandLineArgs      string   `yaml:"commandLineArgs"`
+	KillD2OnStop         bool     `yaml:"killD2OnStop"`
+	ClassicMode          bool     `yaml:"classicMode"`
+	CloseMiniPanel       bool     `yaml:"closeMiniPanel"`
+	UseCentralizedPickit bool     `yaml:"useCentralizedPickit"`
+	HidePortraits        bool     `yaml:"hidePortraits"`
 
 	Scheduler Scheduler `yaml:"scheduler"`
 	Health    struct {
@@ -100,16 +191,49 @@ type CharacterCfg struct {
 		MercRejuvPotionAt   int `yaml:"mercRejuvPotionAt"`
 		ChickenAt           int `yaml:"chickenAt"`
 		MercChickenAt       int `yaml:"mercChickenAt"`
+		// DamageRate predicts the time to death from the recent HP history, all durations in milliseconds
+		DamageRate struct {
+			Enabled         bool                `yaml:"enabled"`
+			Window          int                 `yaml:"window"`
+			ChickenHorizon  int                 `yaml:"chickenHorizon"`
+			RejuvHorizon    int                 `yaml:"rejuvHorizon"`
+			AreaMultipliers map[area.ID]float64 `yaml:"areaMultipliers"`
+		} `yaml:"damageRate"`
 	} `yaml:"health"`
+	// Curses defines the reactions to curses and hostile auras, see curse.Policy
+	Curses    curse.Policy `yaml:"curses"`
 	Inventory struct {
 		InventoryLock [][]int     `yaml:"inventoryLock"`
 		BeltColumns   BeltColumns `yaml:"beltColumns"`
+		// PotionRefillThreshold is the belt fill percentage below which potions are bought
+		PotionRefillThreshold int `yaml:"potionRefillThreshold"`
+		PotionOverflow        struct {
+			Healing      int `yaml:"healing"`
+			Mana         int `yaml:"mana"`
+			Rejuvenation int `yaml:"rejuvenation"`
+		} `yaml:"potionOverflow"`
 	} `yaml:"inventory"`
 	Character struct {
-		Class         string `yaml:"class"`
-		UseMerc       bool   `yaml:"useMerc"`
-		StashToShared bool   `yaml:"stashToShared"`
-		UseTeleport   bool   `yaml:"useTeleport"`
+		Class   string `yaml:"class"`
+		UseMerc bool   `yaml:"useMerc"`
+		Merc    struct {
+			MaxReviveCost  int  `yaml:"maxReviveCost"`
+			GoldFloor      int  `yaml:"goldFloor"`
+			Rehire         bool `yaml:"rehire"`
+			EquipFromStash bool `yaml:"equipFromStash"`
+		} `yaml:"merc"`
+		StashToShared bool `yaml:"stashToShared"`
+		UseTeleport   bool `yaml:"useTeleport"`
+		// WeaponSets records what each weapon slot holds, used to swap for buffs, Find Item or build attacks
+		WeaponSets weapon.Sets `yaml:"weaponSets"`
+		// BuildFile is the build definition used by the "custom" class, relative to the character config directory
+		BuildFile string `yaml:"buildFile"`
+		// ResistReduction values are used when the monster is affected by Conviction or Lower Resist, the skill
+		// level is unknown so they should match the ones used by the party
+		ResistReduction struct {
+			Conviction  int `yaml:"conviction"`
+			LowerResist int `yaml:"lowerResist"`
+		} `yaml:"resistReduction"`
 		BerserkerBarb struct {
 			FindItemSwitch              bool `yaml:"find_item_switch"`
 			SkipPotionPickupInTravincal bool `yaml:"skip_potion_pickup_in_travincal"`
@@ -137,7 +261,9 @@ type CharacterCfg struct {
 		Runs                   []Run                 `yaml:"runs"`
 		CreateLobbyGames       bool                  `yaml:"createLobbyGames"`
 		PublicGameCounter      int                   `yaml:"-"`
-		Pindleskin             struct {
+		// Targeting selects the next monster to attack when clearing areas, policies can be overridden per run
+		Targeting  target.Config `yaml:"targeting"`
+		Pindleskin struct {
 			SkipOnImmunities []stat.Resist `yaml:"skipOnImmunities"`
 		} `yaml:"pindleskin"`
 		Cows struct {
@@ -207,7 +333,7 @@ type CharacterCfg struct {
 		} `yaml:"eldritch"`
 		LowerKurastChest struct {
 			OpenRacks bool `yaml:"openRacks"`
-		} `yaml:"lowerkurastchests"`
+		} `yaml:"lower_kurast_chest"`
 		TerrorZone struct {
 			FocusOnElitePacks bool          `yaml:"focusOnElitePacks"`
 			SkipOnImmunities  []stat.Resist `yaml:"skipOnImmunities"`
@@ -231,6 +357,11 @@ type CharacterCfg struct {
 			RescueAnya     bool `yaml:"rescueAnya"`
 			KillAncients   bool `yaml:"killAncients"`
 		} `yaml:"quests"`
+		Shopping struct {
+			Vendors   []string `yaml:"vendors"`
+			GoldFloor int      `yaml:"goldFloor"`
+			Refreshes int      `yaml:"refreshes"`
+		} `yaml:"shopping"`
 	} `yaml:"game"`
 	Companion struct {
 		Leader           bool   `yaml:"leader"`
@@ -239,14 +370,21 @@ type CharacterCfg struct {
 		GamePassword     string `yaml:"gamePassword"`
 	} `yaml:"companion"`
 	Gambling struct {
-		Enabled bool        `yaml:"enabled"`
-		Items   []item.Name `yaml:"items"`
+		Enabled    bool          `yaml:"enabled"`
+		Items      []item.Name   `yaml:"items"`
+		StartGold  int           `yaml:"startGold"`
+		GoldFloor  int           `yaml:"goldFloor"`
+		Budget     int           `yaml:"budget"`
+		TargetHits int           `yaml:"targetHits"`
+		Targets    []gamble.Item `yaml:"targets"`
 	} `yaml:"gambling"`
 	CubeRecipes struct {
 		Enabled              bool     `yaml:"enabled"`
 		EnabledRecipes       []string `yaml:"enabledRecipes"`
 		SkipPerfectAmethysts bool     `yaml:"skipPerfectAmethysts"`
 		SkipPerfectRubies    bool     `yaml:"skipPerfectRubies"`
+		// GoldFloor is the gold kept after buying crafting bases from vendors
+		GoldFloor int `yaml:"goldFloor"`
 	} `yaml:"cubing"`
 	BackToTown struct {
 		NoHpPotions     bool `yaml:"noHpPotions"`
@@ -254,9 +392,15 @@ type CharacterCfg struct {
 		MercDied        bool `yaml:"mercDied"`
 		EquipmentBroken bool `yaml:"equipmentBroken"`
 	} `yaml:"backtotown"`
-	Runtime struct {
-		Rules nip.Rules   `yaml:"-"`
-		Drops []data.Item `yaml:"-"`
+	DeathRecovery corpse.Policy `yaml:"deathRecovery"`
+	Runtime       struct {
+		Rules         nip.Rules    `yaml:"-"`
+		ShoppingRules nip.Rules    `yaml:"-"`
+		MercRules     nip.Rules    `yaml:"-"`
+		Build         *build.Build `yaml:"-"`
+		Drops         []data.Item  `yaml:"-"`
+		// Values are the effective config values and the profile they come from
+		Values []profile.Value `yaml:"-"`
 	} `yaml:"-"`
 }
 
@@ -285,8 +429,6 @@ func (bm BeltColumns) Total(potionType data.PotionType) int {
 
 // Load reads the config.ini file and returns a Config struct filled with data from the ini file
 func Load() error {
-	Characters = make(map[string]*CharacterCfg)
-
 	// Get the absolute path of the current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -299,6 +441,9 @@ func Load() error {
 	}
 
 	kooloPath := getAbsPath("config/koolo.yaml")
+	if err = migrateFile(kooloPath, kooloMigrations, false); err != nil {
+		return err
+	}
 	r, err := os.Open(kooloPath)
 	if err != nil {
 		return fmt.Errorf("error loading koolo.yaml: %w", err)
@@ -309,6 +454,13 @@ func Load() error {
 	if err = d.Decode(&Koolo); err != nil {
 		return fmt.Errorf("error reading config %s: %w", kooloPath, err)
 	}
+	if err = loadVault(); err != nil {
+		return err
+	}
+
+	if err = loadRecipes(getAbsPath("config/cube_recipes.yaml")); err != nil {
+		return err
+	}
 
 	configDir := getAbsPath("config")
 	entries, err := os.ReadDir(configDir)
@@ -316,71 +468,134 @@ func Load() error {
 		return fmt.Errorf("error reading config directory %s: %w", configDir, err)
 	}
 
-	// Read character configs
+	if err = migrateProfiles(getAbsPath(filepath.Join("config", ProfilesDir))); err != nil {
+		return err
+	}
+
+	// Read character configs, an invalid one is kept out of Characters so the rest can still be started
+	valid := make(map[string]*CharacterCfg)
+	invalid := make(map[string]InvalidCharacter)
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if !entry.IsDir() || entry.Name() == ProfilesDir {
 			continue
 		}
 
-		charCfg := CharacterCfg{}
-
-		// Load character config from the current working directory/config/{charName}/config.yaml
-		charConfigPath := getAbsPath(filepath.Join("config", entry.Name(), "config.yaml"))
-		r, err = os.Open(charConfigPath)
+		charCfg, err := readCharacter(entry.Name(), getAbsPath)
+		if err == nil {
+			charCfg.normalize()
+			err = charCfg.Validate()
+		}
 		if err != nil {
-			return fmt.Errorf("error loading config.yaml: %w", err)
+			invalid[entry.Name()] = InvalidCharacter{Config: charCfg, Err: fmt.Errorf("invalid %s character config:\n%w", entry.Name(), err)}
+			continue
 		}
-		defer r.Close()
 
-		// Load character config
-		d := yaml.NewDecoder(r)
-		if err = d.Decode(&charCfg); err != nil {
-			return fmt.Errorf("error reading %s character config: %w", charConfigPath, err)
-		}
+		valid[entry.Name()] = charCfg
+	}
 
-		var pickitPath string
+	setCharacters(valid, invalid)
 
-		if Koolo.CentralizedPickitPath != "" && charCfg.UseCentralizedPickit {
-			// Validate centralized pickit path
-			if _, err := os.Stat(Koolo.CentralizedPickitPath); os.IsNotExist(err) {
-				utils.ShowDialog("Error loading pickit rules for "+entry.Name(), "The centralized pickit path does not exist: "+Koolo.CentralizedPickitPath+"\nPlease check your Koolo settings.\nFalling back to local pickit.")
+	return nil
+}
+
+// CharacterErrors returns the errors of every character config that failed to load, sorted by name
+func CharacterErrors() error {
+	invalid := InvalidCharacters()
+	errs := make([]error, 0, len(invalid))
+	for _, name := range slices.Sorted(maps.Keys(invalid)) {
+		errs = append(errs, invalid[name].Err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// readCharacter loads the config, pickit rules and build file of the character, the config is returned along with
+// the error when the file could be read
+func readCharacter(name string, getAbsPath func(string) string) (*CharacterCfg, error) {
+	charCfg := CharacterCfg{}
+
+	// Load character config from the current working directory/config/{charName}/config.yaml, merged over the
+	// profiles it inherits from
+	charConfigPath := getAbsPath(filepath.Join("config", name, "config.yaml"))
+	if err := migrateFile(charConfigPath, characterMigrations, false); err != nil {
+		return nil, err
+	}
+	if err := loadCharacter(charConfigPath, getAbsPath(filepath.Join("config", ProfilesDir)), &charCfg); err != nil {
+		return nil, fmt.Errorf("error reading %s character config: %w", charConfigPath, err)
+	}
+
+	var pickitPath string
+
+	if Koolo.CentralizedPickitPath != "" && charCfg.UseCentralizedPickit {
+		// Validate centralized pickit path
+		if _, err := os.Stat(Koolo.CentralizedPickitPath); os.IsNotExist(err) {
+			utils.ShowDialog("Error loading pickit rules for "+name, "The centralized pickit path does not exist: "+Koolo.CentralizedPickitPath+"\nPlease check your Koolo settings.\nFalling back to local pickit.")
 
-				// Set the pickit path to the current dir/config/{charName}/pickit
-				pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + "\\"
-			} else {
-				pickitPath = Koolo.CentralizedPickitPath + "\\"
-			}
-		} else {
 			// Set the pickit path to the current dir/config/{charName}/pickit
-			pickitPath = getAbsPath(filepath.Join("config", entry.Name(), "pickit")) + "\\"
+			pickitPath = getAbsPath(filepath.Join("config", name, "pickit")) + "\\"
+		} else {
+			pickitPath = Koolo.CentralizedPickitPath + "\\"
 		}
+	} else {
+		// Set the pickit path to the current dir/config/{charName}/pickit
+		pickitPath = getAbsPath(filepath.Join("config", name, "pickit")) + "\\"
+	}
 
-		// Load the pickit rules from the directory
-		rules, err := nip.ReadDir(pickitPath)
+	// Load the pickit rules from the directory
+	rules, err := nip.ReadDir(pickitPath)
+	if err != nil {
+		return &charCfg, fmt.Errorf("error reading pickit directory %s: %w", pickitPath, err)
+	}
+
+	// Load the leveling pickit rules
+	if len(charCfg.Game.Runs) > 0 && charCfg.Game.Runs[0] == "leveling" {
+		levelingPickitPath := getAbsPath(filepath.Join("config", name, "pickit_leveling")) + "\\"
+		levelingRules, err := nip.ReadDir(levelingPickitPath)
 		if err != nil {
-			return fmt.Errorf("error reading pickit directory %s: %w", pickitPath, err)
+			return &charCfg, fmt.Errorf("error reading pickit_leveling directory %s: %w", levelingPickitPath, err)
 		}
+		rules = append(rules, levelingRules...)
+	}
 
-		// Load the leveling pickit rules
-		if len(charCfg.Game.Runs) > 0 && charCfg.Game.Runs[0] == "leveling" {
-			levelingPickitPath := getAbsPath(filepath.Join("config", entry.Name(), "pickit_leveling")) + "\\"
-			levelingRules, err := nip.ReadDir(levelingPickitPath)
-			if err != nil {
-				return fmt.Errorf("error reading pickit_leveling directory %s: %w", levelingPickitPath, err)
-			}
-			rules = append(rules, levelingRules...)
+	charCfg.Runtime.Rules = rules
+
+	// Shopping rules are kept apart from pickit rules, they are only used to buy items from vendors
+	shoppingFile := getAbsPath(filepath.Join("config", name, "shopping.nip"))
+	if _, err := os.Stat(shoppingFile); err == nil {
+		shoppingRules, err := nip.ParseNIPFile(shoppingFile)
+		if err != nil {
+			return &charCfg, fmt.Errorf("error reading shopping file %s: %w", shoppingFile, err)
 		}
+		charCfg.Runtime.ShoppingRules = shoppingRules
+	}
 
-		charCfg.Runtime.Rules = rules
-		Characters[entry.Name()] = &charCfg
+	// Merc rules are only used to choose the stash items equipped on the mercenary
+	mercFile := getAbsPath(filepath.Join("config", name, "merc.nip"))
+	if _, err := os.Stat(mercFile); err == nil {
+		mercRules, err := nip.ParseNIPFile(mercFile)
+		if err != nil {
+			return &charCfg, fmt.Errorf("error reading merc file %s: %w", mercFile, err)
+		}
+		charCfg.Runtime.MercRules = mercRules
 	}
 
-	// Validate configs
-	for _, charCfg := range Characters {
-		charCfg.Validate()
+	// Custom class is fully defined by the build file, it's loaded here so errors are shown on startup
+	if strings.EqualFold(charCfg.Character.Class, "custom") {
+		buildFile := charCfg.Character.BuildFile
+		if buildFile == "" {
+			buildFile = "build.yaml"
+		}
+		if !filepath.IsAbs(buildFile) {
+			buildFile = getAbsPath(filepath.Join("config", name, buildFile))
+		}
+		bld, err := build.Load(buildFile)
+		if err != nil {
+			return &charCfg, err
+		}
+		charCfg.Runtime.Build = bld
 	}
 
-	return nil
+	return &charCfg, nil
 }
 
 func CreateFromTemplate(name string) error {
@@ -414,6 +629,11 @@ func ValidateAndSaveConfig(config KooloCfg) error {
 		return errors.New("D2RPath is not valid")
 	}
 
+	config.SchemaVersion = kooloMigrations.Latest()
+	if config.Fleet.MaxConcurrent < 0 || config.Fleet.RotateAfter < 0 || config.Fleet.StartDelay < 0 {
+		return errors.New("fleet limits can not be negative")
+	}
+
 	text, err := yaml.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("error parsing koolo config: %w", err)
@@ -429,8 +649,18 @@ func ValidateAndSaveConfig(config KooloCfg) error {
 
 func SaveSupervisorConfig(supervisorName string, config *CharacterCfg) error {
 	filePath := filepath.Join("config", supervisorName, "config.yaml")
-	d, err := yaml.Marshal(config)
-	config.Validate()
+	config.SchemaVersion = characterMigrations.Latest()
+	config.normalize()
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	// Only the values not inherited from the profiles are written
+	overrides, err := config.overrides()
+	if err != nil {
+		return err
+	}
+	d, err := yaml.Marshal(overrides)
 	if err != nil {
 		return err
 	}
@@ -440,10 +670,37 @@ func SaveSupervisorConfig(supervisorName string, config *CharacterCfg) error {
 		return fmt.Errorf("error writing supervisor config: %w", err)
 	}
 
-	return Load()
+	if err = Load(); err != nil {
+		return err
+	}
+	// Other characters may still be invalid, only the errors of the saved one are returned
+	if invalid, found := InvalidCharacters()[supervisorName]; found {
+		return invalid.Err
+	}
+
+	return nil
+}
+
+// GamblingStrategy returns the gambling strategy, when no targets are defined the items list is used with same weight
+func (c *CharacterCfg) GamblingStrategy() gamble.Strategy {
+	items := c.Gambling.Targets
+	if len(items) == 0 {
+		for _, name := range c.Gambling.Items {
+			items = append(items, gamble.Item{Name: string(name)})
+		}
+	}
+
+	return gamble.Strategy{
+		StartGold:  c.Gambling.StartGold,
+		GoldFloor:  c.Gambling.GoldFloor,
+		Budget:     c.Gambling.Budget,
+		TargetHits: c.Gambling.TargetHits,
+		Items:      items,
+	}.WithDefaults()
 }
 
-func (c *CharacterCfg) Validate() {
+// normalize replaces the values out of range that have a sensible default
+func (c *CharacterCfg) normalize() {
 	if c.Character.Class == "nova" || c.Character.Class == "lightsorc" {
 		minThreshold := 65 // Default
 		switch c.Game.Difficulty {
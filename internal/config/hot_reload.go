@@ -0,0 +1,42 @@
+package config
+
+import "github.com/hectorgimenez/koolo/internal/config/reload"
+
+// ReloadPolicy decides when a config change is applied to a running supervisor. Anything used to launch or log into
+// the game needs a restart, game and run settings wait for the next game and the rest is read on every use.
+var ReloadPolicy = reload.Policy{
+	Classes: map[string]reload.Apply{
+		"username":             reload.Restart,
+		"password":             reload.Restart,
+		"authMethod":           reload.Restart,
+		"authToken":            reload.Restart,
+		"realm":                reload.Restart,
+		"characterName":        reload.Restart,
+		"commandLineArgs":      reload.Restart,
+		"classicMode":          reload.Restart,
+		"closeMiniPanel":       reload.Restart,
+		"hidePortraits":        reload.Restart,
+		"character.class":      reload.Restart,
+		"character.buildFile":  reload.Restart,
+		"maxGameLength":        reload.NextGame,
+		"game":                 reload.NextGame,
+		"companion":            reload.NextGame,
+		"character":            reload.NextGame,
+		"killD2OnStop":         reload.Now,
+		"useCentralizedPickit": reload.Now,
+		"scheduler":            reload.Now,
+		"health":               reload.Now,
+		"curses":               reload.Now,
+		"inventory":            reload.Now,
+		"gambling":             reload.Now,
+		"cubing":               reload.Now,
+		"backtotown":           reload.Now,
+		"deathRecovery":        reload.Now,
+		"character.merc":       reload.Now,
+		"character.weaponSets": reload.Now,
+		"game.targeting":       reload.Now,
+		"game.shopping":        reload.Now,
+	},
+	Default: reload.NextGame,
+	Secrets: []string{"password", "authToken"},
+}
@@ -0,0 +1,200 @@
+package migration
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VersionKey is the yaml key holding the schema version of a config file, files without it are version 0
+const VersionKey = "schemaVersion"
+
+// Migration upgrades a config file from Version-1 to Version
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func(d *Doc) error
+}
+
+// Chain are the migrations of a config file type, sorted by version
+type Chain []Migration
+
+// Result describes the migrations applied to a file
+type Result struct {
+	From    int
+	To      int
+	Applied []string
+}
+
+// Changed returns true if the file was upgraded
+func (r Result) Changed() bool {
+	return r.From != r.To
+}
+
+// Latest returns the current schema version
+func (c Chain) Latest() int {
+	if len(c) == 0 {
+		return 0
+	}
+
+	return c[len(c)-1].Version
+}
+
+// Migrate upgrades the file content to the latest version. Partial files, like profiles, only hold some of the keys
+// so no defaults are added to them.
+func (c Chain) Migrate(data []byte, partial bool) ([]byte, Result, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, Result{}, err
+	}
+	if len(doc.Content) == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, Result{}, fmt.Errorf("expected a mapping of config keys")
+	}
+
+	d := &Doc{root: root, Partial: partial}
+	version := 0
+	if n, found := d.Get(VersionKey); found {
+		v, err := strconv.Atoi(n.Value)
+		if err != nil {
+			return nil, Result{}, fmt.Errorf("invalid %s %q", VersionKey, n.Value)
+		}
+		version = v
+	}
+
+	result := Result{From: version, To: version, Applied: make([]string, 0)}
+	if version > c.Latest() {
+		return nil, result, fmt.Errorf("config version %d is newer than the supported %d, it was written by a newer Koolo version", version, c.Latest())
+	}
+	if version == c.Latest() {
+		return data, result, nil
+	}
+
+	for _, m := range c {
+		if m.Version <= version {
+			continue
+		}
+		if err := m.Apply(d); err != nil {
+			return nil, result, fmt.Errorf("error migrating config to version %d (%s): %w", m.Version, m.Description, err)
+		}
+		result.To = m.Version
+		result.Applied = append(result.Applied, m.Description)
+	}
+
+	if n, found := d.Get(VersionKey); found {
+		n.SetString(strconv.Itoa(result.To))
+		n.Tag = "!!int"
+	} else {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: VersionKey, LineComment: "Config format version, upgraded automatically"},
+			{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(result.To)},
+		}, root.Content...)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, result, err
+	}
+
+	return buf.Bytes(), result, enc.Close()
+}
+
+// Doc is a config file being migrated, it's edited through its yaml nodes to keep the comments. Paths are made of the
+// yaml keys separated by dots.
+type Doc struct {
+	root *yaml.Node
+	// Partial docs only contain some of the keys
+	Partial bool
+}
+
+// Get returns the value at the given path
+func (d *Doc) Get(path string) (*yaml.Node, bool) {
+	parent, i, found := d.find(path, false)
+	if !found {
+		return nil, false
+	}
+
+	return parent.Content[i+1], true
+}
+
+// Rename moves the value to a new path, the value already at the new path is kept if both are set
+func (d *Doc) Rename(from, to string) {
+	parent, i, found := d.find(from, false)
+	if !found {
+		return
+	}
+
+	key, value := parent.Content[i], parent.Content[i+1]
+	parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+	if _, found = d.Get(to); found {
+		return
+	}
+
+	dst, _, _ := d.find(to, true)
+	key.Value = to[strings.LastIndex(to, ".")+1:]
+	dst.Content = append(dst.Content, key, value)
+}
+
+// Delete removes the value at the given path
+func (d *Doc) Delete(path string) {
+	if parent, i, found := d.find(path, false); found {
+		parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+	}
+}
+
+// SetDefault sets the value if the path is not set yet, partial docs are not changed
+func (d *Doc) SetDefault(path string, value any) error {
+	if _, found := d.Get(path); found || d.Partial {
+		return nil
+	}
+
+	n := &yaml.Node{}
+	if err := n.Encode(value); err != nil {
+		return err
+	}
+	parent, _, _ := d.find(path, true)
+	parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[strings.LastIndex(path, ".")+1:]}, n)
+
+	return nil
+}
+
+// find returns the mapping holding the last key of the path and the key index, the missing mappings are created when
+// create is true
+func (d *Doc) find(path string, create bool) (*yaml.Node, int, bool) {
+	keys := strings.Split(path, ".")
+	n := d.root
+	for depth, key := range keys {
+		index := -1
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				index = i
+				break
+			}
+		}
+		if depth == len(keys)-1 {
+			return n, index, index >= 0
+		}
+
+		if index < 0 || n.Content[index+1].Kind != yaml.MappingNode {
+			if !create {
+				return nil, -1, false
+			}
+			if index >= 0 {
+				n.Content = append(n.Content[:index], n.Content[index+2:]...)
+			}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+			index = len(n.Content) - 2
+		}
+		n = n.Content[index+1]
+	}
+
+	return nil, -1, false
+}
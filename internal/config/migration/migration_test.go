@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+)
+
+var chain = Chain{
+	{Version: 1, Description: "move enableCubeRecipes to cubing.enabled", Apply: func(d *Doc) error {
+		d.Rename("enableCubeRecipes", "cubing.enabled")
+		return nil
+	}},
+	{Version: 2, Description: "rename lowerkurastchests", Apply: func(d *Doc) error {
+		d.Rename("game.lowerkurastchests", "game.lower_kurast_chest")
+		d.Delete("game.legacy")
+		return d.SetDefault("game.difficulty", "normal")
+	}},
+}
+
+func TestMigrate(t *testing.T) {
+	data := `maxGameLength: 500 # Max game length
+enableCubeRecipes: true # Enable cubing
+game:
+  legacy: true
+  lowerkurastchests:
+    openRacks: true # Open weapon racks
+  runs: [ pit, cows ]
+`
+
+	out, result, err := chain.Migrate([]byte(data), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Changed() || result.From != 0 || result.To != 2 || len(result.Applied) != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	expected := `schemaVersion: 2 # Config format version, upgraded automatically
+maxGameLength: 500 # Max game length
+game:
+  runs: [pit, cows]
+  lower_kurast_chest:
+    openRacks: true # Open weapon racks
+  difficulty: normal
+cubing:
+  enabled: true # Enable cubing
+`
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	// Already migrated files are kept as they are
+	again, result, err := chain.Migrate(out, false)
+	if err != nil || result.Changed() || string(again) != string(out) {
+		t.Errorf("expected no changes, got %+v, %v:\n%s", result, err, again)
+	}
+}
+
+func TestMigratePartial(t *testing.T) {
+	out, result, err := chain.Migrate([]byte("schemaVersion: 1\ngame:\n  lowerkurastchests:\n    openRacks: true\n"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.From != 1 || result.To != 2 || len(result.Applied) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if string(out) != "schemaVersion: 2\ngame:\n  lower_kurast_chest:\n    openRacks: true\n" {
+		t.Errorf("defaults should not be added to partial configs, got:\n%s", out)
+	}
+
+	// Empty files are valid partial configs
+	if out, _, err = chain.Migrate(nil, true); err != nil || strings.TrimSpace(string(out)) != "schemaVersion: 2 # Config format version, upgraded automatically" {
+		t.Errorf("unexpected empty file migration: %v\n%s", err, out)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	if _, _, err := chain.Migrate([]byte("schemaVersion: 3\n"), false); err == nil {
+		t.Error("expected an error for configs newer than the latest version")
+	}
+	if _, _, err := chain.Migrate([]byte("schemaVersion: two\n"), false); err == nil {
+		t.Error("expected an error for invalid versions")
+	}
+}
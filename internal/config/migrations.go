@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/config/migration"
+)
+
+// kooloMigrations upgrade koolo.yaml, add a migration with the next version when renaming or moving a field
+var kooloMigrations = migration.Chain{
+	{Version: 1, Description: "use camel case for the game paths", Apply: func(d *migration.Doc) error {
+		d.Rename("D2LoDPath", "d2LoDPath")
+		d.Rename("D2RPath", "d2RPath")
+		return nil
+	}},
+}
+
+// characterMigrations upgrade the character configs and the profiles, add a migration with the next version when
+// renaming or moving a field
+var characterMigrations = migration.Chain{
+	{Version: 1, Description: "move enableCubeRecipes to cubing.enabled and rename lowerkurastchests to lower_kurast_chest", Apply: func(d *migration.Doc) error {
+		d.Rename("enableCubeRecipes", "cubing.enabled")
+		d.Rename("game.lowerkurastchests", "game.lower_kurast_chest")
+		return nil
+	}},
+}
+
+// migrateFile upgrades the config file to the latest schema version, the original file is kept as
+// {file}.v{version}.bak
+func migrateFile(path string, chain migration.Chain, partial bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	upgraded, result, err := chain.Migrate(data, partial)
+	if err != nil {
+		return fmt.Errorf("error upgrading %s: %w", path, err)
+	}
+	if !result.Changed() {
+		return nil
+	}
+
+	if err = os.WriteFile(fmt.Sprintf("%s.v%d.bak", path, result.From), data, 0644); err != nil {
+		return fmt.Errorf("error backing up %s: %w", path, err)
+	}
+	if err = os.WriteFile(path, upgraded, 0644); err != nil {
+		return fmt.Errorf("error writing upgraded %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// migrateProfiles upgrades all the profiles, they use the same migrations as the character configs
+func migrateProfiles(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") {
+			if err = migrateFile(filepath.Join(dir, entry.Name()), characterMigrations, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
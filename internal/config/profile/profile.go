@@ -0,0 +1,262 @@
+package profile
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Character is the name of the layer read from the character config.yaml, always merged last
+const Character = "character"
+
+// Key is the yaml key listing the profiles a layer inherits from, the first ones are merged first
+const Key = "profiles"
+
+// Layer is a partial config, its values replace the ones of the previous layers
+type Layer struct {
+	Name string
+	Node *yaml.Node
+}
+
+// Value is an effective config leaf and the layer it comes from, Path is made of the yaml keys
+type Value struct {
+	Path  string
+	Layer string
+	Value string
+}
+
+// Parse reads a layer, an empty file is a valid layer without values
+func Parse(name string, data []byte) (Layer, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return Layer{}, fmt.Errorf("error reading %s: %w", name, err)
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(doc.Content) > 0 {
+		node = doc.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return Layer{}, fmt.Errorf("error reading %s: expected a mapping of config keys", name)
+	}
+
+	return Layer{Name: name, Node: node}, nil
+}
+
+// Profiles returns the profiles the layer inherits from
+func (l Layer) Profiles() []string {
+	profiles := make([]string, 0)
+	if n := child(l.Node, Key); n != nil && n.Kind == yaml.SequenceNode {
+		for _, p := range n.Content {
+			profiles = append(profiles, p.Value)
+		}
+	}
+
+	return profiles
+}
+
+// Resolve returns the layers to merge for the character layer: the profiles it inherits from, including the ones
+// inherited by those profiles, followed by the character itself. Each profile is only merged once.
+func Resolve(character Layer, read func(name string) ([]byte, error)) ([]Layer, error) {
+	layers := make([]Layer, 0)
+	var visit func(l Layer, chain []string) error
+	visit = func(l Layer, chain []string) error {
+		for _, name := range l.Profiles() {
+			if name == Character {
+				return fmt.Errorf("%s is a reserved profile name", Character)
+			}
+			if slices.Contains(chain, name) {
+				return fmt.Errorf("profile inheritance loop: %s -> %s", strings.Join(chain, " -> "), name)
+			}
+			if slices.ContainsFunc(layers, func(m Layer) bool { return m.Name == name }) {
+				continue
+			}
+
+			data, err := read(name)
+			if err != nil {
+				return fmt.Errorf("%s inherits from %s: %w", l.Name, name, err)
+			}
+			p, err := Parse(name, data)
+			if err != nil {
+				return err
+			}
+			if err = visit(p, append(slices.Clone(chain), name)); err != nil {
+				return err
+			}
+			layers = append(layers, p)
+		}
+
+		return nil
+	}
+
+	if err := visit(character, []string{character.Name}); err != nil {
+		return nil, err
+	}
+
+	return append(layers, character), nil
+}
+
+// Merge merges the layers in order, mappings are merged key by key while lists and values are replaced as a whole.
+// Only the profiles of the last layer are kept. It returns the merged mapping and the effective leaves in file order.
+func Merge(layers []Layer) (*yaml.Node, []Value) {
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	sources := make(map[string]string)
+	for i, l := range layers {
+		merge(merged, l.Node, "", l.Name, sources, i == len(layers)-1)
+	}
+
+	values := make([]Value, 0)
+	walk(merged, "", func(path string, n *yaml.Node) {
+		values = append(values, Value{Path: path, Layer: sources[path], Value: display(n)})
+	})
+
+	return merged, values
+}
+
+// Overrides returns the leaves of full that need to be written in a layer merged over base: the ones differing from
+// base and the ones kept on purpose, like the values already present in the layer
+func Overrides(base, full *yaml.Node, keep func(path string) bool) *yaml.Node {
+	overrides := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(full.Content); i += 2 {
+		k, v := full.Content[i], full.Content[i+1]
+		p := k.Value
+		if v.Kind == yaml.MappingNode && len(v.Content) > 0 {
+			if m := Overrides(child(base, k.Value), v, func(path string) bool { return keep(join(p, path)) }); len(m.Content) > 0 {
+				overrides.Content = append(overrides.Content, k, m)
+			}
+			continue
+		}
+
+		if b := child(base, k.Value); b == nil || !equal(b, v) || keep(p) {
+			overrides.Content = append(overrides.Content, k, v)
+		}
+	}
+
+	return overrides
+}
+
+// Remove deletes the value at the given path from the layer, the mappings left empty are removed too
+func (l Layer) Remove(path string) bool {
+	return remove(l.Node, strings.Split(path, "."))
+}
+
+func remove(n *yaml.Node, keys []string) bool {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != keys[0] {
+			continue
+		}
+
+		v := n.Content[i+1]
+		if len(keys) > 1 {
+			if v.Kind != yaml.MappingNode || !remove(v, keys[1:]) {
+				return false
+			}
+			if len(v.Content) > 0 {
+				return true
+			}
+		}
+		n.Content = append(n.Content[:i], n.Content[i+2:]...)
+
+		return true
+	}
+
+	return false
+}
+
+func merge(dst, src *yaml.Node, path, layer string, sources map[string]string, last bool) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		k, v := src.Content[i], src.Content[i+1]
+		if path == "" && k.Value == Key && !last {
+			continue
+		}
+
+		p := join(path, k.Value)
+		existing := child(dst, k.Value)
+		switch {
+		case existing != nil && existing.Kind == yaml.MappingNode && v.Kind == yaml.MappingNode:
+			merge(existing, v, p, layer, sources, last)
+			continue
+		case existing != nil:
+			*existing = *clone(v)
+		default:
+			dst.Content = append(dst.Content, clone(k), clone(v))
+		}
+
+		walk(v, p, func(leaf string, _ *yaml.Node) {
+			sources[leaf] = layer
+		})
+	}
+}
+
+// walk calls fn for every leaf, mappings are walked while lists and values are leaves
+func walk(n *yaml.Node, path string, fn func(path string, n *yaml.Node)) {
+	if n.Kind != yaml.MappingNode || (len(n.Content) == 0 && path != "") {
+		fn(path, n)
+		return
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		walk(n.Content[i+1], join(path, n.Content[i].Value), fn)
+	}
+}
+
+func child(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// clone copies the node without comments, they belong to the layer files
+func clone(n *yaml.Node) *yaml.Node {
+	c := *n
+	c.HeadComment, c.LineComment, c.FootComment = "", "", ""
+	c.Content = make([]*yaml.Node, 0, len(n.Content))
+	for _, sub := range n.Content {
+		c.Content = append(c.Content, clone(sub))
+	}
+
+	return &c
+}
+
+// equal compares the decoded values, the quoting and layout of the files don't matter
+func equal(a, b *yaml.Node) bool {
+	var va, vb any
+	if a.Decode(&va) != nil || b.Decode(&vb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
+
+func display(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+
+	c := clone(n)
+	c.Style = yaml.FlowStyle
+	out, err := yaml.Marshal(c)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
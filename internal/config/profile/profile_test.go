@@ -0,0 +1,160 @@
+package profile
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var profiles = map[string]string{
+	"base": `
+health:
+  healingPotionAt: 75
+  chickenAt: 30
+game:
+  runs: [ pindleskin ]
+`,
+	"sorceress": `
+profiles: [ base ]
+character:
+  class: nova
+health:
+  chickenAt: 40 # Sorceresses die fast
+`,
+	"loop":  `profiles: [ other ]`,
+	"other": `profiles: [ loop ]`,
+}
+
+func read(name string) ([]byte, error) {
+	if p, found := profiles[name]; found {
+		return []byte(p), nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func parse(t *testing.T, name, data string) Layer {
+	t.Helper()
+	l, err := Parse(name, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return l
+}
+
+func TestMerge(t *testing.T) {
+	character := parse(t, Character, `
+profiles: [ base, sorceress ]
+health:
+  healingPotionAt: 80
+game:
+  runs: [ mephisto, pit ]
+  difficulty: hell
+`)
+
+	layers, err := Resolve(character, read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, l := range layers {
+		names = append(names, l.Name)
+	}
+	if strings.Join(names, ",") != "base,sorceress,character" {
+		t.Fatalf("unexpected layers order: %v", names)
+	}
+
+	merged, values := Merge(layers)
+	expected := []Value{
+		{Path: "health.healingPotionAt", Layer: Character, Value: "80"},
+		{Path: "health.chickenAt", Layer: "sorceress", Value: "40"},
+		{Path: "game.runs", Layer: Character, Value: "[mephisto, pit]"},
+		{Path: "game.difficulty", Layer: Character, Value: "hell"},
+		{Path: "character.class", Layer: "sorceress", Value: "nova"},
+		{Path: "profiles", Layer: Character, Value: "[base, sorceress]"},
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d values, got %+v", len(expected), values)
+	}
+	for i, e := range expected {
+		if values[i] != e {
+			t.Errorf("expected %+v, got %+v", e, values[i])
+		}
+	}
+
+	var cfg struct {
+		Profiles []string `yaml:"profiles"`
+		Health   struct {
+			HealingPotionAt int `yaml:"healingPotionAt"`
+			ChickenAt       int `yaml:"chickenAt"`
+		} `yaml:"health"`
+	}
+	if err = merged.Decode(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Health.ChickenAt != 40 || cfg.Health.HealingPotionAt != 80 || len(cfg.Profiles) != 2 {
+		t.Errorf("unexpected merged config: %+v", cfg)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	if _, err := Resolve(parse(t, Character, `profiles: [ loop ]`), read); err == nil || !strings.Contains(err.Error(), "loop") {
+		t.Errorf("expected an inheritance loop error, got %v", err)
+	}
+	if _, err := Resolve(parse(t, Character, `profiles: [ missing ]`), read); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if _, err := Parse("list", []byte(`[ 1, 2 ]`)); err == nil {
+		t.Error("expected an error for a layer that is not a mapping")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	base, _ := Merge([]Layer{parse(t, "base", profiles["base"])})
+	full := parse(t, Character, `
+health:
+  healingPotionAt: 75
+  chickenAt: 35
+game:
+  runs: [ pindleskin ]
+  difficulty: hell
+`).Node
+
+	// healingPotionAt is written because it was already in the character file
+	overrides := Overrides(base, full, func(path string) bool { return path == "health.healingPotionAt" })
+	out, err := yaml.Marshal(overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "health:\n    healingPotionAt: 75\n    chickenAt: 35\ngame:\n    difficulty: hell\n"
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	l := parse(t, Character, `
+health:
+  chickenAt: 35
+game:
+  difficulty: hell
+  runs: [ pit ]
+`)
+
+	if !l.Remove("health.chickenAt") || !l.Remove("game.runs") {
+		t.Fatal("expected the values to be removed")
+	}
+	if l.Remove("game.unknown") || l.Remove("game.difficulty.level") {
+		t.Error("unknown paths should not be removed")
+	}
+
+	out, _ := yaml.Marshal(l.Node)
+	if string(out) != "game:\n    difficulty: hell\n" {
+		t.Errorf("unexpected layer:\n%s", out)
+	}
+}
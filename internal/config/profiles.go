@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/config/profile"
+	"gopkg.in/yaml.v3"
+)
+
+// ProfilesDir contains the profiles shared between characters, config/profiles/{name}.yaml. Characters list the
+// profiles they inherit from and only keep the values they override.
+const ProfilesDir = "profiles"
+
+var profileName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// readProfile returns the profile file reader used to resolve the profiles inherited by a character
+func readProfile(dir string) func(name string) ([]byte, error) {
+	return func(name string) ([]byte, error) {
+		if !profileName.MatchString(name) {
+			return nil, fmt.Errorf("invalid profile name %q, only letters, numbers, - and _ are allowed", name)
+		}
+
+		return os.ReadFile(filepath.Join(dir, name+".yaml"))
+	}
+}
+
+// loadCharacter reads the character config merged over the profiles it inherits from
+func loadCharacter(path, profilesDir string, cfg *CharacterCfg) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error loading config.yaml: %w", err)
+	}
+
+	layer, err := profile.Parse(profile.Character, data)
+	if err != nil {
+		return err
+	}
+	layers, err := profile.Resolve(layer, readProfile(profilesDir))
+	if err != nil {
+		return err
+	}
+
+	merged, values := profile.Merge(layers)
+	if err = merged.Decode(cfg); err != nil {
+		return err
+	}
+	for i, v := range values {
+		if slices.Contains(ReloadPolicy.Secrets, v.Path) {
+			values[i].Value = "******"
+		}
+	}
+	cfg.Runtime.Values = values
+
+	return nil
+}
+
+// overrides returns the values to write in the character config.yaml: the ones differing from its profiles and the
+// ones already written there
+func (c *CharacterCfg) overrides() (*yaml.Node, error) {
+	full := &yaml.Node{}
+	if err := full.Encode(c); err != nil {
+		return nil, err
+	}
+
+	// The profiles to inherit from may have been changed along with the values
+	character := profile.Layer{Name: profile.Character, Node: &yaml.Node{}}
+	if err := character.Node.Encode(map[string][]string{profile.Key: c.Profiles}); err != nil {
+		return nil, err
+	}
+	layers, err := profile.Resolve(character, readProfile(filepath.Join("config", ProfilesDir)))
+	if err != nil {
+		return nil, err
+	}
+	base, _ := profile.Merge(layers[:len(layers)-1])
+
+	return profile.Overrides(base, full, func(path string) bool {
+		return path == profile.Key || slices.ContainsFunc(c.Runtime.Values, func(v profile.Value) bool {
+			return v.Path == path && v.Layer == profile.Character
+		})
+	}), nil
+}
+
+// Profiles returns the names of the available profiles
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join("config", ProfilesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if name, found := strings.CutSuffix(entry.Name(), ".yaml"); found && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// ReadProfile returns the content of the profile file, plaintext secrets are replaced by SecretMask
+func ReadProfile(name string) (string, error) {
+	data, err := readProfile(filepath.Join("config", ProfilesDir))(name)
+	if err != nil {
+		return "", err
+	}
+	data, err = maskSecrets(data)
+
+	return string(data), err
+}
+
+// SaveProfile writes the profile and reloads the configs, nothing is changed if any character inheriting from it
+// becomes invalid. Masked secrets keep their saved value and new ones are stored in the vault, the paths kept in
+// plaintext because there is no vault are returned.
+func SaveProfile(name, content string) ([]string, error) {
+	if !profileName.MatchString(name) || name == profile.Character {
+		return nil, fmt.Errorf("invalid profile name %q, only letters, numbers, - and _ are allowed", name)
+	}
+	if _, err := profile.Parse(name, []byte(content)); err != nil {
+		return nil, err
+	}
+
+	previous, _ := readProfile(filepath.Join("config", ProfilesDir))(name)
+	data, plaintext, err := storeSecrets([]byte(content), previous, ProfilesDir+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Profiles written without a version use the latest format
+	data, _, err = characterMigrations.Migrate(data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join("config", ProfilesDir, name+".yaml")
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	return plaintext, writeAndLoad(path, data)
+}
+
+// ResetValue removes the value from the character config.yaml to use the one inherited from its profiles
+func ResetValue(supervisorName, path string) error {
+	filePath := filepath.Join("config", supervisorName, "config.yaml")
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	layer, err := profile.Parse(profile.Character, data)
+	if err != nil {
+		return err
+	}
+	if path == profile.Key || !layer.Remove(path) {
+		return fmt.Errorf("%s is not set in the %s config", path, supervisorName)
+	}
+
+	d, err := yaml.Marshal(layer.Node)
+	if err != nil {
+		return err
+	}
+
+	return writeAndLoad(filePath, d)
+}
+
+// writeAndLoad writes the file and reloads the configs, the previous content is restored if they become invalid
+func writeAndLoad(path string, content []byte) error {
+	invalidBefore := InvalidCharacters()
+	previous, readErr := os.ReadFile(path)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+
+	err := Load()
+	if err == nil {
+		// Characters already invalid before the change are not blamed on it
+		errs := make([]error, 0)
+		invalid := InvalidCharacters()
+		for _, name := range slices.Sorted(maps.Keys(invalid)) {
+			if _, found := invalidBefore[name]; !found {
+				errs = append(errs, invalid[name].Err)
+			}
+		}
+		err = errors.Join(errs...)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if readErr == nil {
+		os.WriteFile(path, previous, 0644)
+	} else {
+		os.Remove(path)
+	}
+	Load()
+
+	return err
+}
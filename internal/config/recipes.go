@@ -1,66 +1,22 @@
 package config
 
-var AvailableRecipes = []string{
-	// List out all the recipe names from cube_recipes.go
-	"Perfect Amethyst",
-	"Perfect Diamond",
-	"Perfect Emerald",
-	"Perfect Ruby",
-	"Perfect Sapphire",
-	"Perfect Topaz",
-	"Perfect Skull",
-	"Token of Absolution",
-	"Upgrade El",
-	"Upgrade Eld",
-	"Upgrade Tir",
-	"Upgrade Nef",
-	"Upgrade Eth",
-	"Upgrade Ith",
-	"Upgrade Tal",
-	"Upgrade Ral",
-	"Upgrade Ort",
-	"Upgrade Thul",
-	"Upgrade Amn",
-	"Upgrade Sol",
-	"Upgrade Shael",
-	"Upgrade Dol",
-	"Upgrade Hel",
-	"Upgrade Io",
-	"Upgrade Lum",
-	"Upgrade Ko",
-	"Upgrade Fal",
-	"Upgrade Lem",
-	"Upgrade Pul",
-	"Upgrade Um",
-	"Upgrade Mal",
-	"Upgrade Ist",
-	"Upgrade Gul",
-	"Upgrade Vex",
-	"Upgrade Ohm",
-	"Upgrade Lo",
-	"Upgrade Sur",
-	"Upgrade Ber",
-	"Upgrade Jah",
-	"Upgrade Cham",
-	"Reroll GrandCharms",
-	"Caster Amulet",
-	"Caster Ring",
-	"Blood Gloves",
-	"Blood Boots",
-	"Blood Belt",
-	"Blood Helm",
-	"Blood Armor",
-	"Blood Weapon",
-	"Safety Shield",
-	"Safety Armor",
-	"Safety Boots",
-	"Safety Gloves",
-	"Safety Belt",
-	"Safety Helm",
-	"Hitpower Gloves",
-	"Hitpower Boots",
-	"Hitpower Belt",
-	"Hitpower Helm",
-	"Hitpower Armor",
-	"Hitpower Shield",
+import "github.com/hectorgimenez/koolo/internal/recipe"
+
+var (
+	// Recipes holds the cube recipes, defaults merged with the ones in config/cube_recipes.yaml
+	Recipes = recipe.Defaults()
+	// AvailableRecipes are the names of all the recipes that can be enabled
+	AvailableRecipes = recipe.Names(Recipes)
+)
+
+func loadRecipes(path string) error {
+	recipes, err := recipe.Load(path)
+	if err != nil {
+		return err
+	}
+
+	Recipes = recipes
+	AvailableRecipes = recipe.Names(recipes)
+
+	return nil
 }
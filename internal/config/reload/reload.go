@@ -0,0 +1,196 @@
+package reload
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Apply is the moment a config change can take effect on a running supervisor
+type Apply int
+
+const (
+	// Now changes are applied right away, even in the middle of a game
+	Now Apply = iota
+	// NextGame changes are applied once the current game is finished
+	NextGame
+	// Restart changes are only applied when the supervisor is started again
+	Restart
+)
+
+func (a Apply) String() string {
+	switch a {
+	case Now:
+		return "now"
+	case NextGame:
+		return "next game"
+	}
+
+	return "restart"
+}
+
+func (a Apply) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Change is a config field that differs between two configs, Path is made of the yaml names
+type Change struct {
+	Path  string
+	Old   string
+	New   string
+	Apply Apply
+}
+
+const secretValue = "******"
+
+// Policy classifies the changes by their yaml path
+type Policy struct {
+	// Classes maps paths to the moment their changes can be applied, the longest matching path wins
+	Classes map[string]Apply
+	// Default is used for the paths not matching any class
+	Default Apply
+	// Secrets are paths whose values are never reported
+	Secrets []string
+}
+
+// Classify returns the moment a change on the given path can be applied
+func (p Policy) Classify(path string) Apply {
+	apply, longest := p.Default, -1
+	for prefix, a := range p.Classes {
+		if matches(path, prefix) && len(prefix) > longest {
+			apply, longest = a, len(prefix)
+		}
+	}
+
+	return apply
+}
+
+// Diff returns the changed fields between two configs of the same struct type, sorted by path. Fields tagged with
+// yaml:"-" are ignored, maps and slices are compared as a whole.
+func (p Policy) Diff(from, to any) []Change {
+	changes := make([]Change, 0)
+	walk(reflect.Indirect(reflect.ValueOf(from)), reflect.Indirect(reflect.ValueOf(to)), "", func(path string, o, n reflect.Value) {
+		c := Change{Path: path, Old: fmt.Sprint(o.Interface()), New: fmt.Sprint(n.Interface()), Apply: p.Classify(path)}
+		for _, secret := range p.Secrets {
+			if matches(path, secret) {
+				c.Old, c.New = secretValue, secretValue
+			}
+		}
+		changes = append(changes, c)
+	})
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes
+}
+
+// Copy sets the fields of the given changes from src into dst, dst must be a pointer to the src struct type
+func Copy(dst, src any, changes []Change) error {
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Pointer || d.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("destination must be a pointer to a struct, got %T", dst)
+	}
+	s := reflect.Indirect(reflect.ValueOf(src))
+	if s.Type() != d.Elem().Type() {
+		return fmt.Errorf("source type %s does not match the destination %s", s.Type(), d.Elem().Type())
+	}
+
+	for _, c := range changes {
+		df, sf, found := field(d.Elem(), s, strings.Split(c.Path, "."))
+		if !found {
+			return fmt.Errorf("unknown config field %s", c.Path)
+		}
+		df.Set(sf)
+	}
+
+	return nil
+}
+
+// Filter returns the changes applied at the given moment
+func Filter(changes []Change, apply Apply) []Change {
+	filtered := make([]Change, 0)
+	for _, c := range changes {
+		if c.Apply == apply {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered
+}
+
+func walk(o, n reflect.Value, path string, changed func(path string, o, n reflect.Value)) {
+	if !walkable(o.Type()) {
+		if !reflect.DeepEqual(o.Interface(), n.Interface()) {
+			changed(path, o, n)
+		}
+		return
+	}
+
+	for i := 0; i < o.NumField(); i++ {
+		name, ok := yamlName(o.Type().Field(i))
+		if !ok {
+			continue
+		}
+		walk(o.Field(i), n.Field(i), join(path, name), changed)
+	}
+}
+
+func field(d, s reflect.Value, names []string) (reflect.Value, reflect.Value, bool) {
+	if len(names) == 0 {
+		return d, s, true
+	}
+	if d.Kind() != reflect.Struct {
+		return reflect.Value{}, reflect.Value{}, false
+	}
+
+	for i := 0; i < d.NumField(); i++ {
+		if name, ok := yamlName(d.Type().Field(i)); ok && name == names[0] {
+			return field(d.Field(i), s.Field(i), names[1:])
+		}
+	}
+
+	return reflect.Value{}, reflect.Value{}, false
+}
+
+// walkable returns true for structs with only exported fields, others (like time.Time) are compared as a whole
+func walkable(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// yamlName returns the field name as written in the config file, false for ignored fields
+func yamlName(f reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return strings.ToLower(f.Name), true
+	}
+
+	return name, true
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// matches returns true when the path is the prefix path itself or any of its children
+func matches(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+".")
+}
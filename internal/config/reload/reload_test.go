@@ -0,0 +1,111 @@
+package reload
+
+import (
+	"testing"
+	"time"
+)
+
+type cfg struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Health   struct {
+		ChickenAt int `yaml:"chickenAt"`
+	} `yaml:"health"`
+	Game struct {
+		Runs    []string  `yaml:"runs"`
+		Counter int       `yaml:"-"`
+		Since   time.Time `yaml:"since"`
+		Cows    struct {
+			OpenChests bool
+		}
+	} `yaml:"game"`
+	Runtime struct {
+		Rules []string
+	} `yaml:"-"`
+}
+
+var policy = Policy{
+	Classes: map[string]Apply{
+		"health":   Now,
+		"username": Restart,
+		"password": Restart,
+		"game":     NextGame,
+		// More specific paths win
+		"game.cows": Now,
+	},
+	Default: NextGame,
+	Secrets: []string{"password"},
+}
+
+func TestDiff(t *testing.T) {
+	old, loaded := cfg{Username: "a", Password: "secret"}, cfg{Username: "b", Password: "other"}
+	old.Health.ChickenAt, loaded.Health.ChickenAt = 30, 40
+	loaded.Game.Runs = []string{"pindleskin"}
+	loaded.Game.Counter = 5
+	loaded.Game.Since = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	loaded.Game.Cows.OpenChests = true
+	loaded.Runtime.Rules = []string{"[name] == ring"}
+
+	expected := []Change{
+		{Path: "game.cows.openchests", Old: "false", New: "true", Apply: Now},
+		{Path: "game.runs", Old: "[]", New: "[pindleskin]", Apply: NextGame},
+		{Path: "game.since", Apply: NextGame},
+		{Path: "health.chickenAt", Old: "30", New: "40", Apply: Now},
+		{Path: "password", Old: secretValue, New: secretValue, Apply: Restart},
+		{Path: "username", Old: "a", New: "b", Apply: Restart},
+	}
+
+	changes := policy.Diff(old, &loaded)
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %+v", len(expected), changes)
+	}
+	for i, c := range changes {
+		e := expected[i]
+		if c.Path != e.Path || c.Apply != e.Apply {
+			t.Errorf("expected %s (%s), got %s (%s)", e.Path, e.Apply, c.Path, c.Apply)
+		}
+		if e.Old != "" && (c.Old != e.Old || c.New != e.New) {
+			t.Errorf("%s: expected %s -> %s, got %s -> %s", c.Path, e.Old, e.New, c.Old, c.New)
+		}
+	}
+}
+
+func TestNoChanges(t *testing.T) {
+	c := cfg{Username: "a"}
+	c.Game.Runs = []string{"mephisto"}
+	other := c
+	other.Game.Runs = []string{"mephisto"}
+
+	if changes := policy.Diff(c, other); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	live, loaded := cfg{Username: "a"}, cfg{Username: "b"}
+	live.Game.Counter = 3
+	loaded.Health.ChickenAt = 40
+	loaded.Game.Runs = []string{"pindleskin"}
+
+	changes := policy.Diff(live, loaded)
+	if err := Copy(&live, loaded, Filter(changes, Now)); err != nil {
+		t.Fatal(err)
+	}
+
+	if live.Health.ChickenAt != 40 {
+		t.Error("safe changes should be applied")
+	}
+	if live.Username != "a" || live.Game.Runs != nil {
+		t.Error("only the given changes should be applied")
+	}
+	if live.Game.Counter != 3 {
+		t.Error("runtime fields should be kept")
+	}
+
+	if err := Copy(&live, loaded, []Change{{Path: "game.unknown"}}); err == nil {
+		t.Error("expected an error for unknown fields")
+	}
+	if err := Copy(live, loaded, nil); err == nil {
+		t.Error("expected an error when the destination is not a pointer")
+	}
+}
@@ -0,0 +1,308 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/koolo/internal/config/profile"
+	"github.com/hectorgimenez/koolo/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultVaultFile is used when koolo.yaml doesn't set secrets.vaultFile
+	DefaultVaultFile = "config/secrets.vault"
+	// VaultPassphraseEnv is the environment variable used to unlock the vault on startup
+	VaultPassphraseEnv = "KOOLO_VAULT_PASSPHRASE"
+)
+
+var (
+	secretResolver = secrets.NewResolver()
+	// Vault holds the secrets referenced as vault:{name}. It's unlocked on Load with the KOOLO_VAULT_PASSPHRASE
+	// environment variable or the secrets.keyFile, otherwise from the settings page.
+	Vault *secrets.Vault
+)
+
+// The config values holding secrets, they can be replaced by a reference like ${KOOLO_ACCT1_PASS} or
+// vault:acct1/password
+// SecretMask replaces the plaintext secrets shown in the profile editor, saving it back keeps the stored value
+const SecretMask = "********"
+
+var (
+	kooloSecretPaths     = []string{"discord.token", "telegram.token"}
+	characterSecretPaths = []string{"password", "authToken"}
+)
+
+// loadVault opens the vault set in koolo.yaml, an unlocked vault stays unlocked while its file doesn't change
+func loadVault() error {
+	path := Koolo.Secrets.VaultFile
+	if path == "" {
+		path = DefaultVaultFile
+	}
+	if Vault == nil || Vault.Path() != path {
+		Vault = secrets.NewVault(path)
+		secretResolver.Register(secrets.VaultScheme, Vault)
+	}
+	if !Vault.Locked() || !Vault.Exists() {
+		return nil
+	}
+
+	if passphrase, found := os.LookupEnv(VaultPassphraseEnv); found {
+		if err := Vault.Unlock([]byte(passphrase)); err != nil {
+			return fmt.Errorf("error unlocking the secrets vault with %s: %w", VaultPassphraseEnv, err)
+		}
+		return nil
+	}
+	if Koolo.Secrets.KeyFile != "" {
+		if err := Vault.UnlockWithKeyFile(Koolo.Secrets.KeyFile); err != nil {
+			return fmt.Errorf("error unlocking the secrets vault: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ResolveSecret returns the secret a config value points to, plaintext values are returned as they are
+func ResolveSecret(value string) (string, error) {
+	return secretResolver.Resolve(value)
+}
+
+// IsSecretReference returns true if the config value points to a secret instead of holding it
+func IsSecretReference(value string) bool {
+	return secretResolver.IsReference(value)
+}
+
+// StoreSecret returns the value to write in a secret config field. Plaintext secrets are stored in the vault under the
+// given name and replaced by their reference. When no vault has been created they are kept as they are and plaintext
+// is true, so the caller can warn about it.
+func StoreSecret(name, value string) (stored string, plaintext bool, err error) {
+	if value == "" || IsSecretReference(value) {
+		return value, false, nil
+	}
+	if Vault == nil || !Vault.Exists() {
+		return value, true, nil
+	}
+	if err = Vault.Set(name, value); err != nil {
+		if errors.Is(err, secrets.ErrLocked) {
+			return "", false, errors.New("the secrets vault is locked, unlock it to save secrets")
+		}
+		return "", false, err
+	}
+
+	return secrets.Ref(secrets.VaultScheme, name), false, nil
+}
+
+// CreateVault creates the vault set in koolo.yaml protected by the passphrase, it's left unlocked
+func CreateVault(passphrase string) error {
+	if Vault == nil {
+		return errors.New("configs are not loaded yet")
+	}
+
+	return Vault.Create([]byte(passphrase))
+}
+
+// MoveSecretsToVault stores every plaintext secret of koolo.yaml, the character configs and the profiles in the vault
+// and replaces them by their references, it returns the number of secrets moved
+func MoveSecretsToVault() (int, error) {
+	if Vault == nil || !Vault.Exists() {
+		return 0, errors.New("create the secrets vault first")
+	}
+	if Vault.Locked() {
+		return 0, errors.New("the secrets vault is locked, unlock it to move the secrets")
+	}
+
+	moved, err := moveFileSecrets(filepath.Join("config", "koolo.yaml"), "koolo", kooloSecretPaths)
+	if err != nil {
+		return moved, err
+	}
+
+	entries, err := os.ReadDir("config")
+	if err != nil {
+		return moved, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == ProfilesDir {
+			continue
+		}
+		path := filepath.Join("config", entry.Name(), "config.yaml")
+		if _, err = os.Stat(path); err != nil {
+			continue
+		}
+		n, err := moveFileSecrets(path, entry.Name(), characterSecretPaths)
+		moved += n
+		if err != nil {
+			return moved, err
+		}
+	}
+
+	names, err := Profiles()
+	if err != nil {
+		return moved, err
+	}
+	for _, name := range names {
+		n, err := moveFileSecrets(filepath.Join("config", ProfilesDir, name+".yaml"), ProfilesDir+"/"+name, characterSecretPaths)
+		moved += n
+		if err != nil {
+			return moved, err
+		}
+	}
+
+	return moved, Load()
+}
+
+// PlaintextSecrets returns the paths of the secrets stored in plaintext in the loaded configs
+func PlaintextSecrets() []string {
+	found := make([]string, 0)
+	check := func(path, value string) {
+		if value != "" && !IsSecretReference(value) {
+			found = append(found, path)
+		}
+	}
+
+	if Koolo != nil {
+		check("koolo.discord.token", Koolo.Discord.Token)
+		check("koolo.telegram.token", Koolo.Telegram.Token)
+	}
+	characters := Characters()
+	for _, name := range slices.Sorted(maps.Keys(characters)) {
+		check(name+".password", characters[name].Password)
+		check(name+".authToken", characters[name].AuthToken)
+	}
+
+	return found
+}
+
+// maskSecrets replaces the plaintext secrets of the character config layer by SecretMask, so they are never shown in
+// the profile editor. References are kept as they are.
+func maskSecrets(data []byte) ([]byte, error) {
+	layer, err := profile.Parse("", data)
+	if err != nil {
+		return nil, err
+	}
+
+	masked := false
+	for _, p := range characterSecretPaths {
+		n := findValue(layer.Node, p)
+		if n == nil || n.Kind != yaml.ScalarNode || n.Value == "" || IsSecretReference(n.Value) {
+			continue
+		}
+		n.SetString(SecretMask)
+		masked = true
+	}
+	if !masked {
+		return data, nil
+	}
+
+	return yaml.Marshal(layer.Node)
+}
+
+// storeSecrets restores the masked secrets of the character config layer from its previous content and stores the
+// plaintext ones like StoreSecret does, named {prefix}/{path}. It returns the paths kept in plaintext.
+func storeSecrets(data, previous []byte, prefix string) ([]byte, []string, error) {
+	layer, err := profile.Parse(prefix, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var previousLayer profile.Layer
+	if len(previous) > 0 {
+		if previousLayer, err = profile.Parse(prefix, previous); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	changed := false
+	plaintext := make([]string, 0)
+	for _, p := range characterSecretPaths {
+		n := findValue(layer.Node, p)
+		if n == nil || n.Kind != yaml.ScalarNode || n.Value == "" {
+			continue
+		}
+		if n.Value == SecretMask {
+			old := findValue(previousLayer.Node, p)
+			if old == nil || old.Kind != yaml.ScalarNode {
+				return nil, nil, fmt.Errorf("%s is masked but there is no saved value, enter it again", p)
+			}
+			n.SetString(old.Value)
+			changed = true
+		}
+		stored, isPlaintext, err := StoreSecret(prefix+"/"+p, n.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isPlaintext {
+			plaintext = append(plaintext, prefix+"/"+p)
+		}
+		if stored != n.Value {
+			n.SetString(stored)
+			changed = true
+		}
+	}
+	if !changed {
+		return data, plaintext, nil
+	}
+
+	out, err := yaml.Marshal(layer.Node)
+
+	return out, plaintext, err
+}
+
+// moveFileSecrets replaces the plaintext secrets at the given paths of the file by vault references named
+// {prefix}/{path}, the comments of the file are kept
+func moveFileSecrets(path, prefix string, paths []string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	layer, err := profile.Parse(path, data)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, p := range paths {
+		n := findValue(layer.Node, p)
+		if n == nil || n.Kind != yaml.ScalarNode || n.Value == "" || IsSecretReference(n.Value) {
+			continue
+		}
+		ref, _, err := StoreSecret(prefix+"/"+p, n.Value)
+		if err != nil {
+			return moved, err
+		}
+		n.SetString(ref)
+		moved++
+	}
+	if moved == 0 {
+		return 0, nil
+	}
+
+	out, err := yaml.Marshal(layer.Node)
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, os.WriteFile(path, out, 0644)
+}
+
+// findValue returns the value at the dotted path of the mapping
+func findValue(n *yaml.Node, path string) *yaml.Node {
+	for _, key := range strings.Split(path, ".") {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return nil
+		}
+		var value *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == key {
+				value = n.Content[i+1]
+				break
+			}
+		}
+		n = value
+	}
+
+	return n
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
+	"github.com/hectorgimenez/koolo/internal/config/validation"
+	"github.com/hectorgimenez/koolo/internal/health/curse"
+	"github.com/hectorgimenez/koolo/internal/schedule"
+	"github.com/hectorgimenez/koolo/internal/target"
+)
+
+var (
+	// AvailableClasses are the values accepted by character.class
+	AvailableClasses = []string{
+		"sorceress", "fireballsorc", "nova", "hydraorb", "lightsorc", "necromancer", "poisonnovanecro", "hammerdin", "foh",
+		"trapsin", "mosaic", "winddruid", "javazon", "berserker", "custom",
+	}
+	// LevelingClasses are the values accepted by character.class when leveling is the first run
+	LevelingClasses = []string{"sorceress_leveling_lightning", "sorceress_leveling", "paladin"}
+)
+
+var (
+	authMethods  = []string{"None", "UsernamePassword", "TokenAuth"}
+	realms       = []string{"eu.actual.battle.net", "us.actual.battle.net", "kr.actual.battle.net"}
+	difficulties = []string{difficulty.Normal, difficulty.Nightmare, difficulty.Hell}
+	beltPotions  = []string{"healing", "mana", "rejuvenation"}
+	targetOrders = []string{string(target.OrderClosest), string(target.OrderElitesFirst), string(target.OrderLowestHP)}
+)
+
+const (
+	inventoryRows    = 4
+	inventoryColumns = 10
+)
+
+// Validate checks every field of the character config, the returned error is a validation.Errors with the path of
+// each invalid field
+func (c *CharacterCfg) Validate() error {
+	v := &validation.Validator{}
+
+	v.NotNegative("maxGameLength", c.MaxGameLength)
+	if c.AuthMethod != "" {
+		v.OneOf("authMethod", c.AuthMethod, authMethods)
+	}
+	switch c.AuthMethod {
+	case "TokenAuth":
+		v.Required("authToken", c.AuthToken)
+	case "UsernamePassword":
+		v.Required("username", c.Username)
+		v.Required("password", c.Password)
+	}
+	if c.Realm != "" {
+		v.OneOf("realm", c.Realm, realms)
+	}
+
+	c.validateScheduler(v)
+	c.validateHealth(v)
+	c.validateInventory(v)
+	c.validateCharacter(v)
+	c.validateGame(v)
+
+	v.NotNegative("gambling.startGold", c.Gambling.StartGold)
+	v.NotNegative("gambling.goldFloor", c.Gambling.GoldFloor)
+	v.NotNegative("gambling.budget", c.Gambling.Budget)
+	v.NotNegative("gambling.targetHits", c.Gambling.TargetHits)
+	for i, t := range c.Gambling.Targets {
+		if t.Weight != nil {
+			v.NotNegative(validation.Index("gambling.targets", i)+".weight", *t.Weight)
+		}
+	}
+
+	for i, r := range c.CubeRecipes.EnabledRecipes {
+		v.OneOf(validation.Index("cubing.enabledRecipes", i), r, AvailableRecipes)
+	}
+	v.NotNegative("cubing.goldFloor", c.CubeRecipes.GoldFloor)
+
+	v.NotNegative("deathRecovery.maxDeathsPerGame", c.DeathRecovery.MaxDeathsPerGame)
+	v.NotNegative("deathRecovery.maxMonstersNearby", c.DeathRecovery.MaxMonstersNearby)
+	v.NotNegative("deathRecovery.safeRadius", c.DeathRecovery.SafeRadius)
+	v.NotNegative("deathRecovery.maxNakedDistance", c.DeathRecovery.MaxNakedDistance)
+
+	return v.Err()
+}
+
+func (c *CharacterCfg) validateScheduler(v *validation.Validator) {
+	s := c.Scheduler
+	for i, day := range s.Days {
+		path := validation.Index("scheduler.days", i)
+		v.Range(path+".dayOfWeek", day.DayOfWeek, 0, 6)
+		validateTimeRanges(v, path+".timeRange", day.TimeRanges)
+	}
+
+	if len(s.DailyBudget) > 7 {
+		v.Add("scheduler.dailyBudget", "expected one value per day of the week, got %d", len(s.DailyBudget))
+	}
+	for i, budget := range s.DailyBudget {
+		v.NotNegative(validation.Index("scheduler.dailyBudget", i), budget)
+	}
+
+	v.NotNegative("scheduler.jitter", s.Jitter)
+	v.NotNegative("scheduler.breaks.everyMin", s.Breaks.EveryMin)
+	v.NotNegative("scheduler.breaks.lengthMin", s.Breaks.LengthMin)
+	if s.Breaks.EveryMax < s.Breaks.EveryMin {
+		v.Add("scheduler.breaks.everyMax", "must be greater than or equal to everyMin (%d), got %d", s.Breaks.EveryMin, s.Breaks.EveryMax)
+	}
+	if s.Breaks.LengthMax < s.Breaks.LengthMin {
+		v.Add("scheduler.breaks.lengthMax", "must be greater than or equal to lengthMin (%d), got %d", s.Breaks.LengthMin, s.Breaks.LengthMax)
+	}
+
+	for i, e := range s.Exceptions {
+		path := validation.Index("scheduler.exceptions", i)
+		if _, err := time.Parse(schedule.DateLayout, e.Date); err != nil {
+			v.Add(path+".date", "expected a date like 2024-12-24, got %q", e.Date)
+		}
+		validateTimeRanges(v, path+".timeRange", e.TimeRanges)
+	}
+	for i, w := range s.Windows {
+		if !w.End.After(w.Start) {
+			v.Add(validation.Index("scheduler.windows", i), "end must be after start")
+		}
+	}
+}
+
+// validateTimeRanges checks the time ranges of a day, in any order
+func validateTimeRanges(v *validation.Validator, path string, ranges []TimeRange) {
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b TimeRange) int {
+		return a.Start.Compare(b.Start)
+	})
+
+	for i, r := range ranges {
+		if !r.End.After(r.Start) {
+			v.Add(validation.Index(path, i), "end time %s must be after start time %s", r.End.Format("15:04"), r.Start.Format("15:04"))
+		}
+	}
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Start.Before(sorted[i-1].End) {
+			v.Add(path, "time ranges %s-%s and %s-%s overlap", sorted[i-1].Start.Format("15:04"), sorted[i-1].End.Format("15:04"), sorted[i].Start.Format("15:04"), sorted[i].End.Format("15:04"))
+		}
+	}
+}
+
+func (c *CharacterCfg) validateHealth(v *validation.Validator) {
+	h := c.Health
+	v.Percent("health.healingPotionAt", h.HealingPotionAt)
+	v.Percent("health.manaPotionAt", h.ManaPotionAt)
+	v.Percent("health.rejuvPotionAtLife", h.RejuvPotionAtLife)
+	v.Percent("health.rejuvPotionAtMana", h.RejuvPotionAtMana)
+	v.Percent("health.mercHealingPotionAt", h.MercHealingPotionAt)
+	v.Percent("health.mercRejuvPotionAt", h.MercRejuvPotionAt)
+	v.Percent("health.chickenAt", h.ChickenAt)
+	v.Percent("health.mercChickenAt", h.MercChickenAt)
+
+	v.NotNegative("health.damageRate.window", h.DamageRate.Window)
+	v.NotNegative("health.damageRate.chickenHorizon", h.DamageRate.ChickenHorizon)
+	v.NotNegative("health.damageRate.rejuvHorizon", h.DamageRate.RejuvHorizon)
+	for _, id := range slices.Sorted(maps.Keys(h.DamageRate.AreaMultipliers)) {
+		if multiplier := h.DamageRate.AreaMultipliers[id]; multiplier <= 0 {
+			v.Add("health.damageRate.areaMultipliers."+strconv.Itoa(int(id)), "must be greater than 0, got %g", multiplier)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Curses.ChickenAtIncrease)) {
+		path := "curses.chickenAtIncrease." + name
+		_, isCurse := curse.Curses[name]
+		_, isAura := curse.Auras[name]
+		if !isCurse && !isAura {
+			v.Add(path, "unknown curse or aura")
+		}
+		v.Percent(path, c.Curses.ChickenAtIncrease[name])
+	}
+	v.NotNegative("curses.auraRadius", c.Curses.AuraRadius)
+}
+
+func (c *CharacterCfg) validateInventory(v *validation.Validator) {
+	lock := c.Inventory.InventoryLock
+	if len(lock) != inventoryRows {
+		v.Add("inventory.inventoryLock", "expected %d rows of %d columns, got %d rows", inventoryRows, inventoryColumns, len(lock))
+	}
+	for i, row := range lock {
+		path := validation.Index("inventory.inventoryLock", i)
+		if len(row) != inventoryColumns {
+			v.Add(path, "expected %d columns, got %d", inventoryColumns, len(row))
+		}
+		for j, cell := range row {
+			if cell != 0 && cell != 1 {
+				v.Add(validation.Index(path, j), "must be 0 (locked) or 1 (unlocked), got %d", cell)
+			}
+		}
+	}
+
+	for i, column := range c.Inventory.BeltColumns {
+		v.OneOf(validation.Index("inventory.beltColumns", i), column, beltPotions)
+	}
+	v.Percent("inventory.potionRefillThreshold", c.Inventory.PotionRefillThreshold)
+	v.NotNegative("inventory.potionOverflow.healing", c.Inventory.PotionOverflow.Healing)
+	v.NotNegative("inventory.potionOverflow.mana", c.Inventory.PotionOverflow.Mana)
+	v.NotNegative("inventory.potionOverflow.rejuvenation", c.Inventory.PotionOverflow.Rejuvenation)
+}
+
+func (c *CharacterCfg) validateCharacter(v *validation.Validator) {
+	ch := c.Character
+	if len(c.Game.Runs) > 0 && c.Game.Runs[0] == LevelingRun {
+		v.OneOf("character.class", ch.Class, LevelingClasses)
+	} else {
+		v.OneOf("character.class", ch.Class, AvailableClasses)
+	}
+
+	v.NotNegative("character.merc.maxReviveCost", ch.Merc.MaxReviveCost)
+	v.NotNegative("character.merc.goldFloor", ch.Merc.GoldFloor)
+	v.Check("character.weaponSets", ch.WeaponSets.Validate())
+	v.Percent("character.resistReduction.conviction", ch.ResistReduction.Conviction)
+	v.Percent("character.resistReduction.lowerResist", ch.ResistReduction.LowerResist)
+	v.NotNegative("character.poison_nova_necro.minions", ch.PoisonNovaNecro.Minions)
+}
+
+func (c *CharacterCfg) validateGame(v *validation.Validator) {
+	g := c.Game
+	v.NotNegative("game.minGoldPickupThreshold", g.MinGoldPickupThreshold)
+	v.OneOf("game.difficulty", string(g.Difficulty), difficulties)
+
+	runs := make([]string, 0, len(AvailableRuns))
+	for r := range AvailableRuns {
+		runs = append(runs, string(r))
+	}
+	slices.Sort(runs)
+	for i, r := range g.Runs {
+		path := validation.Index("game.runs", i)
+		if _, found := AvailableRuns[r]; !found {
+			v.Add(path, "unknown run %q, allowed values: %s", r, strings.Join(runs, ", "))
+		}
+		if r == LevelingRun && i > 0 {
+			v.Add(path, "leveling must be the first run")
+		}
+	}
+
+	validatePolicy(v, "game.targeting.default", g.Targeting.Default)
+	for _, name := range slices.Sorted(maps.Keys(g.Targeting.Runs)) {
+		path := "game.targeting.runs." + name
+		if _, found := AvailableRuns[Run(name)]; !found {
+			v.Add(path, "unknown run %q", name)
+		}
+		validatePolicy(v, path, g.Targeting.Runs[name])
+	}
+
+	for i, id := range g.TerrorZone.Areas {
+		if !id.CanBeTerrorized() {
+			v.Add(validation.Index("game.terror_zone.areas", i), "%s (%d) can not be terrorized", id.Area().Name, id)
+		}
+	}
+
+	v.NotNegative("game.diablo.attackFromDistance", g.Diablo.AttackFromDistance)
+	v.NotNegative("game.shopping.goldFloor", g.Shopping.GoldFloor)
+	v.NotNegative("game.shopping.refreshes", g.Shopping.Refreshes)
+}
+
+func validatePolicy(v *validation.Validator, path string, p target.Policy) {
+	if p.Order != target.OrderNone {
+		v.OneOf(path+".order", string(p.Order), targetOrders)
+	}
+}
@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FieldError is an invalid config value, Path is made of the yaml names, ex: health.chickenAt or game.runs[2]
+type FieldError struct {
+	Path    string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// Errors are all the invalid values found in a config
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Validator collects the errors of a config, all the fields are checked instead of stopping at the first error
+type Validator struct {
+	errs Errors
+}
+
+// Err returns the collected errors, nil when the config is valid
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return v.errs
+}
+
+// Errors returns the collected errors
+func (v *Validator) Errors() Errors {
+	return v.errs
+}
+
+func (v *Validator) Add(path, format string, args ...any) {
+	v.errs = append(v.errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// Check adds the error returned by a nested validation, if any
+func (v *Validator) Check(path string, err error) {
+	if err != nil {
+		v.Add(path, "%s", err.Error())
+	}
+}
+
+// Range checks min <= value <= max
+func (v *Validator) Range(path string, value, min, max int) {
+	if value < min || value > max {
+		v.Add(path, "must be between %d and %d, got %d", min, max, value)
+	}
+}
+
+// Percent checks the value is a percentage
+func (v *Validator) Percent(path string, value int) {
+	v.Range(path, value, 0, 100)
+}
+
+// NotNegative checks value >= 0
+func (v *Validator) NotNegative(path string, value int) {
+	if value < 0 {
+		v.Add(path, "can not be negative, got %d", value)
+	}
+}
+
+// Required checks the value is not empty
+func (v *Validator) Required(path, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.Add(path, "is required")
+	}
+}
+
+// OneOf checks the value is one of the allowed ones, case insensitive
+func (v *Validator) OneOf(path, value string, allowed []string) {
+	if !slices.ContainsFunc(allowed, func(a string) bool { return strings.EqualFold(a, value) }) {
+		v.Add(path, "unknown value %q, allowed values: %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+// Index returns the path of a list element
+func Index(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
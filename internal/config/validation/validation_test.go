@@ -0,0 +1,49 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	v := &Validator{}
+	v.Percent("health.chickenAt", 30)
+	v.NotNegative("maxGameLength", 0)
+	v.OneOf("game.difficulty", "Hell", []string{"normal", "nightmare", "hell"})
+	v.Required("username", "koolo")
+	v.Check("character.weaponSets", nil)
+	if err := v.Err(); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+
+	v.Percent("health.chickenAt", 130)
+	v.NotNegative("maxGameLength", -1)
+	v.OneOf(Index("game.runs", 2), "cow", []string{"cows", "pit"})
+	v.Required("authToken", " ")
+	v.Check("character.weaponSets", errors.New("unknown weapon set holding shield"))
+
+	expected := Errors{
+		{Path: "health.chickenAt", Message: "must be between 0 and 100, got 130"},
+		{Path: "maxGameLength", Message: "can not be negative, got -1"},
+		{Path: "game.runs[2]", Message: `unknown value "cow", allowed values: cows, pit`},
+		{Path: "authToken", Message: "is required"},
+		{Path: "character.weaponSets", Message: "unknown weapon set holding shield"},
+	}
+
+	var errs Errors
+	if !errors.As(v.Err(), &errs) {
+		t.Fatalf("expected validation errors, got %v", v.Err())
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i, e := range expected {
+		if errs[i] != e {
+			t.Errorf("expected %q, got %q", e, errs[i])
+		}
+	}
+
+	if errs.Error() != "health.chickenAt: must be between 0 and 100, got 130\nmaxGameLength: can not be negative, got -1\ngame.runs[2]: unknown value \"cow\", allowed values: cows, pit\nauthToken: is required\ncharacter.weaponSets: unknown weapon set holding shield" {
+		t.Errorf("unexpected message: %s", errs.Error())
+	}
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/buff"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
@@ -50,9 +52,11 @@ type Context struct {
 	BeltManager       *health.BeltManager
 	HealthManager     *health.Manager
 	Char              Character
-	LastBuffAt        time.Time
+	Buffs             *buff.Scheduler
 	ContextDebug      map[Priority]*Debug
 	CurrentGame       *CurrentGameHelper
+	// VendorPrices keeps the gold paid for the items bought from vendors, the memory reader doesn't expose prices
+	VendorPrices map[item.Name]int
 }
 
 type Debug struct {
@@ -68,6 +72,10 @@ type CurrentGameHelper struct {
 		ExpectedArea area.ID
 	}
 	PickupItems bool
+	// Deaths recovered in the current game, see corpse.Policy
+	Deaths int
+	// CurrentRun is the name of the run being executed
+	CurrentRun string
 }
 
 func NewContext(name string) *Status {
@@ -82,7 +90,9 @@ func NewContext(name string) *Status {
 			PriorityPause:      {},
 			PriorityStop:       {},
 		},
-		CurrentGame: NewGameHelper(),
+		CurrentGame:  NewGameHelper(),
+		Buffs:        &buff.Scheduler{},
+		VendorPrices: make(map[item.Name]int),
 	}
 	botContexts[getGoroutineID()] = &Status{Priority: PriorityNormal, Context: ctx}
 
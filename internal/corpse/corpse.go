@@ -0,0 +1,113 @@
+package corpse
+
+import (
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/utils"
+)
+
+const (
+	// DefaultSafeRadius is the distance to the corpse where monsters are counted, when not configured
+	DefaultSafeRadius = 15
+	// DefaultMaxNakedDistance is the max distance to the corpse without gear equipped, when not configured
+	DefaultMaxNakedDistance = 30
+)
+
+// Route is the way back to the area where the character died
+type Route int
+
+const (
+	RouteNone Route = iota
+	RoutePortal
+	RouteWaypoint
+)
+
+func (r Route) String() string {
+	switch r {
+	case RoutePortal:
+		return "portal"
+	case RouteWaypoint:
+		return "waypoint"
+	}
+
+	return "none"
+}
+
+// Policy defines when the corpse is recovered after a death instead of finishing the game, configured per character
+type Policy struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxDeathsPerGame is the max number of deaths recovered in the same game, 1 if not set
+	MaxDeathsPerGame int `yaml:"maxDeathsPerGame"`
+	// MaxMonstersNearby is the max number of monsters close to the corpse to consider it safe to recover
+	MaxMonstersNearby int `yaml:"maxMonstersNearby"`
+	// SafeRadius is the distance to the corpse where monsters are counted
+	SafeRadius int `yaml:"safeRadius"`
+	// MaxNakedDistance is the max distance from the arrival point to the corpse when no gear is equipped
+	MaxNakedDistance int `yaml:"maxNakedDistance"`
+}
+
+// ShouldRecover returns true if the death (1 for the first death in the game) should be recovered
+func (p Policy) ShouldRecover(deaths int) bool {
+	return p.Enabled && deaths <= max(p.MaxDeathsPerGame, 1)
+}
+
+// ChooseRoute returns how to go back to the death area, our own town portal is preferred because it's usually closer
+// to the corpse
+func ChooseRoute(deathArea area.ID, portalFound bool) Route {
+	if deathArea.IsTown() {
+		return RouteNone
+	}
+	if portalFound {
+		return RoutePortal
+	}
+	if _, found := area.WPAddresses[deathArea]; found {
+		return RouteWaypoint
+	}
+
+	return RouteNone
+}
+
+// Safe returns true if there are not too many monsters close to the corpse, the character is naked until the corpse
+// is recovered
+func (p Policy) Safe(corpse data.Position, monsters []data.Monster) bool {
+	radius := p.SafeRadius
+	if radius <= 0 {
+		radius = DefaultSafeRadius
+	}
+
+	nearby := 0
+	for _, m := range monsters {
+		if utils.DistanceFromPoint(corpse, m.Position) <= radius {
+			nearby++
+		}
+	}
+
+	return nearby <= p.MaxMonstersNearby
+}
+
+// Geared returns true if a weapon or shield is equipped, all the equipped items are left on the corpse after a death
+func Geared(equipped []data.Item) bool {
+	for _, itm := range equipped {
+		if itm.Location.BodyLocation == item.LocLeftArm || itm.Location.BodyLocation == item.LocRightArm {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reachable returns true if the corpse can be recovered from the given distance, without gear the character can't
+// fight its way to the corpse so it has to be close
+func (p Policy) Reachable(geared bool, distance int) bool {
+	if geared {
+		return true
+	}
+
+	maxDistance := p.MaxNakedDistance
+	if maxDistance <= 0 {
+		maxDistance = DefaultMaxNakedDistance
+	}
+
+	return distance <= maxDistance
+}
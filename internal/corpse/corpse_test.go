@@ -0,0 +1,101 @@
+package corpse
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+func TestShouldRecover(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		deaths   int
+		expected bool
+	}{
+		{name: "disabled", policy: Policy{}, deaths: 1, expected: false},
+		{name: "default limit", policy: Policy{Enabled: true}, deaths: 1, expected: true},
+		{name: "default limit reached", policy: Policy{Enabled: true}, deaths: 2, expected: false},
+		{name: "custom limit", policy: Policy{Enabled: true, MaxDeathsPerGame: 3}, deaths: 3, expected: true},
+		{name: "custom limit reached", policy: Policy{Enabled: true, MaxDeathsPerGame: 3}, deaths: 4, expected: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.ShouldRecover(tc.deaths); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestChooseRoute(t *testing.T) {
+	tests := []struct {
+		name     string
+		area     area.ID
+		portal   bool
+		expected Route
+	}{
+		{name: "portal preferred", area: area.CatacombsLevel2, portal: true, expected: RoutePortal},
+		{name: "waypoint", area: area.CatacombsLevel2, expected: RouteWaypoint},
+		{name: "no waypoint", area: area.ChaosSanctuary, expected: RouteNone},
+		{name: "portal without waypoint", area: area.ChaosSanctuary, portal: true, expected: RoutePortal},
+		{name: "town", area: area.RogueEncampment, portal: true, expected: RouteNone},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ChooseRoute(tc.area, tc.portal); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSafe(t *testing.T) {
+	corpse := data.Position{X: 100, Y: 100}
+	monsters := []data.Monster{
+		{Position: data.Position{X: 105, Y: 100}},
+		{Position: data.Position{X: 100, Y: 110}},
+		{Position: data.Position{X: 200, Y: 200}},
+	}
+
+	if !(Policy{MaxMonstersNearby: 2}).Safe(corpse, monsters) {
+		t.Error("two monsters nearby should be safe")
+	}
+	if (Policy{MaxMonstersNearby: 1}).Safe(corpse, monsters) {
+		t.Error("two monsters nearby should not be safe")
+	}
+	if !(Policy{MaxMonstersNearby: 1, SafeRadius: 7}).Safe(corpse, monsters) {
+		t.Error("only monsters inside the radius should be counted")
+	}
+}
+
+func TestReachable(t *testing.T) {
+	weapon := data.Item{Location: item.Location{LocationType: item.LocationEquipped, BodyLocation: item.LocRightArm}}
+	helm := data.Item{Location: item.Location{LocationType: item.LocationEquipped, BodyLocation: item.LocHead}}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		equipped []data.Item
+		distance int
+		expected bool
+	}{
+		{name: "geared far away", equipped: []data.Item{weapon}, distance: 200, expected: true},
+		{name: "naked close", distance: 20, expected: true},
+		{name: "naked out of reach", distance: 40, expected: false},
+		{name: "helm is not enough", equipped: []data.Item{helm}, distance: 40, expected: false},
+		{name: "custom distance", policy: Policy{MaxNakedDistance: 50}, distance: 40, expected: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Reachable(Geared(tc.equipped), tc.distance); got != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
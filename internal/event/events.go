@@ -2,6 +2,8 @@ package event
 
 import (
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/koolo/internal/combat"
 )
 
 const (
@@ -10,6 +12,7 @@ const (
 	FinishedChicken     FinishReason = "chicken"
 	FinishedMercChicken FinishReason = "merc chicken"
 	FinishedError       FinishReason = "error"
+	FinishedCorpseRun   FinishReason = "corpse run"
 
 	InteractionTypeEntrance InteractionType = "entrance"
 	InteractionTypeNPC      InteractionType = "npc"
@@ -151,3 +154,97 @@ func GamePaused(be BaseEvent, paused bool) GamePausedEvent {
 		Paused:    paused,
 	}
 }
+
+type ItemCraftedEvent struct {
+	BaseEvent
+	Recipe string
+	Item   data.Item
+	Kept   bool
+}
+
+func ItemCrafted(be BaseEvent, recipe string, itm data.Item, kept bool) ItemCraftedEvent {
+	return ItemCraftedEvent{
+		BaseEvent: be,
+		Recipe:    recipe,
+		Item:      itm,
+		Kept:      kept,
+	}
+}
+
+type ItemGambledEvent struct {
+	BaseEvent
+	Item data.Item
+	Cost int
+	Kept bool
+}
+
+func ItemGambled(be BaseEvent, itm data.Item, cost int, kept bool) ItemGambledEvent {
+	return ItemGambledEvent{
+		BaseEvent: be,
+		Item:      itm,
+		Cost:      cost,
+		Kept:      kept,
+	}
+}
+
+type MercDiedEvent struct {
+	BaseEvent
+	Area area.ID
+}
+
+func MercDied(be BaseEvent, a area.ID) MercDiedEvent {
+	return MercDiedEvent{
+		BaseEvent: be,
+		Area:      a,
+	}
+}
+
+type MercRevivedEvent struct {
+	BaseEvent
+	Cost    int
+	Rehired bool
+}
+
+func MercRevived(be BaseEvent, cost int, rehired bool) MercRevivedEvent {
+	return MercRevivedEvent{
+		BaseEvent: be,
+		Cost:      cost,
+		Rehired:   rehired,
+	}
+}
+
+type MercEquippedEvent struct {
+	BaseEvent
+	Item data.Item
+}
+
+func MercEquipped(be BaseEvent, itm data.Item) MercEquippedEvent {
+	return MercEquippedEvent{
+		BaseEvent: be,
+		Item:      itm,
+	}
+}
+
+type MonsterAttackedEvent struct {
+	BaseEvent
+	Attack combat.Attack
+}
+
+func MonsterAttacked(be BaseEvent, a combat.Attack) MonsterAttackedEvent {
+	return MonsterAttackedEvent{
+		BaseEvent: be,
+		Attack:    a,
+	}
+}
+
+type SupervisorDisabledEvent struct {
+	BaseEvent
+	Crashes int
+}
+
+func SupervisorDisabled(be BaseEvent, crashes int) SupervisorDisabledEvent {
+	return SupervisorDisabledEvent{
+		BaseEvent: be,
+		Crashes:   crashes,
+	}
+}
@@ -0,0 +1,227 @@
+package gamble
+
+import (
+	"math/rand"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+const (
+	DefaultStartGold = 2480000
+	DefaultGoldFloor = 500000
+
+	// Gambled items get a random item level between clvl-5 and clvl+4
+	itemLevelBelowCharacter = 5
+	itemLevelAboveCharacter = 4
+)
+
+type Strategy struct {
+	// StartGold is the amount of stashed gold required to start gambling
+	StartGold int `yaml:"startGold,omitempty"`
+	// GoldFloor stops gambling when total gold goes below it, 0 uses DefaultGoldFloor so set it to 1 to spend everything
+	GoldFloor int `yaml:"goldFloor,omitempty"`
+	// Budget is the maximum amount of gold spent per gambling session, 0 means no limit
+	Budget int `yaml:"budget,omitempty"`
+	// TargetHits stops gambling after getting this amount of rare or unique items, 0 means no limit
+	TargetHits int    `yaml:"targetHits,omitempty"`
+	Items      []Item `yaml:"items,omitempty"`
+}
+
+// Item is an item to be gambled, items with higher weight are bought more often when available at the vendor.
+// Weight defaults to 1 when not set, 0 disables the item. QualityLevel is the base item quality level, used with MinAffixLevel to skip items when the character level is
+// too low to roll the wanted affixes.
+type Item struct {
+	Name          string `yaml:"name"`
+	Weight        *int   `yaml:"weight,omitempty"`
+	QualityLevel  int    `yaml:"qualityLevel,omitempty"`
+	MinAffixLevel int    `yaml:"minAffixLevel,omitempty"`
+}
+
+type Outcome struct {
+	Item    string
+	Quality item.Quality
+	Cost    int
+	Kept    bool
+}
+
+// Session keeps track of a single gambling session, it decides what to buy and when to stop
+type Session struct {
+	strategy Strategy
+	clvl     int
+	spent    int
+	hits     int
+	outcomes []Outcome
+	rnd      *rand.Rand
+}
+
+// WithDefaults returns the strategy filling the missing values with the default ones
+func (s Strategy) WithDefaults() Strategy {
+	if s.StartGold == 0 {
+		s.StartGold = DefaultStartGold
+	}
+	if s.GoldFloor == 0 {
+		s.GoldFloor = DefaultGoldFloor
+	}
+
+	return s
+}
+
+func (it Item) weight() int {
+	if it.Weight == nil {
+		return 1
+	}
+
+	return *it.Weight
+}
+
+// ShouldStart returns true when there is enough stashed gold to start a session
+func (s Strategy) ShouldStart(stashedGold int) bool {
+	return len(s.Items) > 0 && stashedGold >= s.WithDefaults().StartGold
+}
+
+func NewSession(s Strategy, clvl int, rnd *rand.Rand) *Session {
+	return &Session{
+		strategy: s.WithDefaults(),
+		clvl:     clvl,
+		rnd:      rnd,
+	}
+}
+
+// Next chooses the item to buy between the ones available at the vendor, false is returned when none of them is
+// wanted and the gambling window should be refreshed.
+func (s *Session) Next(available []string) (string, bool) {
+	type candidate struct {
+		name   string
+		weight float64
+	}
+
+	candidates := make([]candidate, 0)
+	total := 0.0
+	for _, it := range s.strategy.Items {
+		if !containsName(available, it.Name) {
+			continue
+		}
+
+		w := float64(it.weight()) * AffixLevelChance(s.clvl, it.QualityLevel, it.MinAffixLevel)
+		if w <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{name: it.Name, weight: w})
+		total += w
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	roll := s.rnd.Float64() * total
+	for _, c := range candidates {
+		if roll < c.weight {
+			return c.name, true
+		}
+		roll -= c.weight
+	}
+
+	return candidates[len(candidates)-1].name, true
+}
+
+// Wanted returns the items that can be bought with the current character level
+func (s *Session) Wanted() []string {
+	names := make([]string, 0)
+	for _, it := range s.strategy.Items {
+		if it.weight() > 0 && AffixLevelChance(s.clvl, it.QualityLevel, it.MinAffixLevel) > 0 {
+			names = append(names, it.Name)
+		}
+	}
+
+	return names
+}
+
+func (s *Session) Record(o Outcome) {
+	s.spent += o.Cost
+	if o.Quality == item.QualityRare || o.Quality == item.QualityUnique {
+		s.hits++
+	}
+	s.outcomes = append(s.outcomes, o)
+}
+
+// Finished returns true and the reason when the session should stop
+func (s *Session) Finished(totalGold int) (bool, string) {
+	switch {
+	case len(s.Wanted()) == 0:
+		return true, "no items to gamble for current character level"
+	case totalGold < s.strategy.GoldFloor:
+		return true, "gold below floor"
+	case s.strategy.Budget > 0 && s.spent >= s.strategy.Budget:
+		return true, "budget spent"
+	case s.strategy.TargetHits > 0 && s.hits >= s.strategy.TargetHits:
+		return true, "target hits reached"
+	}
+
+	return false, ""
+}
+
+func (s *Session) Spent() int {
+	return s.spent
+}
+
+func (s *Session) Hits() int {
+	return s.hits
+}
+
+func (s *Session) Outcomes() []Outcome {
+	return s.outcomes
+}
+
+// AffixLevel returns the affix level for the given item level and base quality level
+func AffixLevel(ilvl, qlvl int) int {
+	if ilvl > 99 {
+		ilvl = 99
+	}
+	// Item level is raised to the base quality level, ex: low level gambles of high level bases
+	if qlvl > ilvl {
+		ilvl = qlvl
+	}
+	if ilvl < 99-qlvl/2 {
+		return ilvl - qlvl/2
+	}
+
+	return 2*ilvl - 99
+}
+
+// AffixLevelChance returns the chance for a gambled item to reach the given affix level, item level is random
+// between clvl-5 and clvl+4, so the chance is the portion of that range reaching minAffixLevel.
+func AffixLevelChance(clvl, qlvl, minAffixLevel int) float64 {
+	if minAffixLevel <= 0 {
+		return 1
+	}
+
+	matching := 0
+	total := 0
+	for ilvl := clvl - itemLevelBelowCharacter; ilvl <= clvl+itemLevelAboveCharacter; ilvl++ {
+		if ilvl < 1 {
+			continue
+		}
+		total++
+		if AffixLevel(ilvl, qlvl) >= minAffixLevel {
+			matching++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(matching) / float64(total)
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,117 @@
+package gamble
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+func TestAffixLevel(t *testing.T) {
+	tests := []struct {
+		ilvl, qlvl, want int
+	}{
+		{ilvl: 80, qlvl: 1, want: 80},
+		{ilvl: 90, qlvl: 52, want: 81},
+		{ilvl: 95, qlvl: 52, want: 91},
+		{ilvl: 120, qlvl: 1, want: 99},
+		{ilvl: 30, qlvl: 52, want: 26},
+	}
+
+	for _, tt := range tests {
+		if got := AffixLevel(tt.ilvl, tt.qlvl); got != tt.want {
+			t.Errorf("AffixLevel(%d, %d) = %d, want %d", tt.ilvl, tt.qlvl, got, tt.want)
+		}
+	}
+}
+
+func TestAffixLevelChance(t *testing.T) {
+	if c := AffixLevelChance(50, 52, 0); c != 1 {
+		t.Errorf("Expected chance 1 without affix level target, got %f", c)
+	}
+	if c := AffixLevelChance(80, 52, 90); c != 0 {
+		t.Errorf("Expected chance 0 for low character level, got %f", c)
+	}
+	// Coronet at clvl 93: ilvl 88..97, alvl 90 is reached from ilvl 95
+	if c := AffixLevelChance(93, 52, 90); c != 0.3 {
+		t.Errorf("Expected chance 0.3, got %f", c)
+	}
+}
+
+func TestSessionNext(t *testing.T) {
+	s := NewSession(Strategy{Items: []Item{
+		{Name: "Coronet", QualityLevel: 52, MinAffixLevel: 90},
+		{Name: "Amulet", Weight: weight(3)},
+		{Name: "Ring"},
+		{Name: "Boots", Weight: weight(0)},
+	}}, 80, rand.New(rand.NewSource(1)))
+
+	if _, found := s.Next([]string{"Coronet", "Boots"}); found {
+		t.Errorf("Expected coronet to be skipped at low character level")
+	}
+	if name, found := s.Next([]string{"Boots", "amulet"}); !found || name != "Amulet" {
+		t.Errorf("Expected amulet, got %s", name)
+	}
+	if name, found := s.Next([]string{"Boots"}); found {
+		t.Errorf("Expected boots with weight 0 to be skipped, got %s", name)
+	}
+
+	picked := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		name, _ := s.Next([]string{"Amulet", "Ring"})
+		picked[name]++
+	}
+	if picked["Amulet"] < picked["Ring"]*2 {
+		t.Errorf("Expected amulets to be picked more often, got %v", picked)
+	}
+}
+
+func TestSessionFinished(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		gold     int
+		outcomes []Outcome
+		want     bool
+	}{
+		{name: "Default gold floor", strategy: Strategy{Items: []Item{{Name: "Ring"}}}, gold: 400000, want: true},
+		{name: "Above gold floor", strategy: Strategy{Items: []Item{{Name: "Ring"}}}, gold: 600000},
+		{
+			name:     "Budget spent",
+			strategy: Strategy{Budget: 100000, Items: []Item{{Name: "Ring"}}},
+			gold:     1000000,
+			outcomes: []Outcome{{Cost: 60000}, {Cost: 60000}},
+			want:     true,
+		},
+		{
+			name:     "Target hits reached",
+			strategy: Strategy{TargetHits: 2, Items: []Item{{Name: "Ring"}}},
+			gold:     1000000,
+			outcomes: []Outcome{{Quality: item.QualityRare}, {Quality: item.QualityMagic}, {Quality: item.QualityUnique}},
+			want:     true,
+		},
+		{
+			name:     "Target hits not reached",
+			strategy: Strategy{TargetHits: 2, Items: []Item{{Name: "Ring"}}},
+			gold:     1000000,
+			outcomes: []Outcome{{Quality: item.QualityRare}, {Quality: item.QualityMagic}},
+		},
+		{name: "Nothing to gamble", strategy: Strategy{Items: []Item{{Name: "Coronet", QualityLevel: 52, MinAffixLevel: 90}}}, gold: 1000000, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSession(tt.strategy, 50, rand.New(rand.NewSource(1)))
+			for _, o := range tt.outcomes {
+				s.Record(o)
+			}
+			if got, reason := s.Finished(tt.gold); got != tt.want {
+				t.Errorf("Expected finished to be %v, got %v (%s)", tt.want, got, reason)
+			}
+		})
+	}
+}
+
+func weight(w int) *int {
+	return &w
+}
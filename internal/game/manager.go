@@ -84,8 +84,8 @@ func (gm *Manager) NewGame() error {
 		difficulty.Hell:      {X: 640, Y: 403},
 	}
 
-	createX := difficultyPosition[config.Characters[gm.supervisorName].Game.Difficulty].X
-	createY := difficultyPosition[config.Characters[gm.supervisorName].Game.Difficulty].Y
+	createX := difficultyPosition[config.Characters()[gm.supervisorName].Game.Difficulty].X
+	createY := difficultyPosition[config.Characters()[gm.supervisorName].Game.Difficulty].Y
 	gm.hid.Click(LeftButton, 600, 650)
 	utils.Sleep(250)
 	gm.hid.Click(LeftButton, createX, createY)
@@ -120,14 +120,14 @@ func (gm *Manager) CreateOnlineGame(gameCounter int) (string, error) {
 		difficulty.Hell:      {X: 1065, Y: 252},
 	}
 
-	difficultyPos := difficultyPosition[config.Characters[gm.supervisorName].Game.Difficulty]
+	difficultyPos := difficultyPosition[config.Characters()[gm.supervisorName].Game.Difficulty]
 	gm.hid.Click(LeftButton, difficultyPos.X, difficultyPos.Y)
 	utils.Sleep(200)
 
 	// Click the game name textbox, delete text and type new game name
 	gm.hid.Click(LeftButton, 1000, 116)
 	gm.clearGameNameOrPasswordField()
-	gameName := config.Characters[gm.supervisorName].Companion.GameNameTemplate + fmt.Sprintf("%d", gameCounter)
+	gameName := config.Characters()[gm.supervisorName].Companion.GameNameTemplate + fmt.Sprintf("%d", gameCounter)
 	for _, ch := range gameName {
 		gm.hid.PressKey(gm.hid.GetASCIICode(fmt.Sprintf("%c", ch)))
 	}
@@ -135,7 +135,7 @@ func (gm *Manager) CreateOnlineGame(gameCounter int) (string, error) {
 	// Same for password
 	gm.hid.Click(LeftButton, 1000, 161)
 	utils.Sleep(200)
-	gamePassword := config.Characters[gm.supervisorName].Companion.GamePassword
+	gamePassword := config.Characters()[gm.supervisorName].Companion.GamePassword
 	if gamePassword != "" {
 		gm.clearGameNameOrPasswordField()
 		for _, ch := range gamePassword {
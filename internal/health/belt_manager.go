@@ -3,9 +3,9 @@ package health
 import (
 	"fmt"
 	"log/slog"
-	"strings"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/belt"
 	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
 )
@@ -45,25 +45,41 @@ func (bm BeltManager) DrinkPotion(potionType data.PotionType, merc bool) bool {
 	return false
 }
 
-// ShouldBuyPotions will return true if more than 25% of belt is empty (ignoring rejuv)
-func (bm BeltManager) ShouldBuyPotions() bool {
-	targetHealingAmount := bm.data.CharacterCfg.Inventory.BeltColumns.Total(data.HealingPotion) * bm.data.Inventory.Belt.Rows()
-	targetManaAmount := bm.data.CharacterCfg.Inventory.BeltColumns.Total(data.ManaPotion) * bm.data.Inventory.Belt.Rows()
-	targetRejuvAmount := bm.data.CharacterCfg.Inventory.BeltColumns.Total(data.RejuvenationPotion) * bm.data.Inventory.Belt.Rows()
+// Plan returns the current belt and potion overflow plan
+func (bm BeltManager) Plan() belt.Plan {
+	cfg := bm.data.CharacterCfg.Inventory
+
+	return belt.NewPlan(bm.data.Inventory, belt.Config{
+		Columns:       cfg.BeltColumns,
+		InventoryLock: cfg.InventoryLock,
+		Overflow: map[data.PotionType]int{
+			data.HealingPotion:      cfg.PotionOverflow.Healing,
+			data.ManaPotion:         cfg.PotionOverflow.Mana,
+			data.RejuvenationPotion: cfg.PotionOverflow.Rejuvenation,
+		},
+		RefillThreshold: cfg.PotionRefillThreshold,
+	})
+}
 
-	currentHealing, currentMana, currentRejuv := bm.getCurrentPotions()
+// ShouldBuyPotions will return true if healing or mana potions are below the refill threshold (ignoring rejuv)
+// or the overflow stack is not complete
+func (bm BeltManager) ShouldBuyPotions() bool {
+	plan := bm.Plan()
 
 	bm.logger.Debug(fmt.Sprintf(
-		"Belt Stats Health: %d/%d healing, %d/%d mana, %d/%d rejuv.",
-		currentHealing,
-		targetHealingAmount,
-		currentMana,
-		targetManaAmount,
-		currentRejuv,
-		targetRejuvAmount,
+		"Belt Stats Health: %d/%d healing, %d/%d mana, %d/%d rejuv. Overflow: %d healing, %d mana, %d rejuv.",
+		plan.Current(data.HealingPotion),
+		plan.Target(data.HealingPotion),
+		plan.Current(data.ManaPotion),
+		plan.Target(data.ManaPotion),
+		plan.Current(data.RejuvenationPotion),
+		plan.Target(data.RejuvenationPotion),
+		len(plan.Overflow[data.HealingPotion]),
+		len(plan.Overflow[data.ManaPotion]),
+		len(plan.Overflow[data.RejuvenationPotion]),
 	))
 
-	if currentHealing < int(float32(targetHealingAmount)*0.75) || currentMana < int(float32(targetManaAmount)*0.75) {
+	if plan.ShouldBuy() {
 		bm.logger.Debug("Need more pots, let's buy them.")
 		return true
 	}
@@ -71,53 +87,12 @@ func (bm BeltManager) ShouldBuyPotions() bool {
 	return false
 }
 
-func (bm BeltManager) getCurrentPotions() (int, int, int) {
-	currentHealing := 0
-	currentMana := 0
-	currentRejuv := 0
-	for _, i := range bm.data.Inventory.Belt.Items {
-		if strings.Contains(string(i.Name), string(data.HealingPotion)) {
-			currentHealing++
-			continue
-		}
-		if strings.Contains(string(i.Name), string(data.ManaPotion)) {
-			currentMana++
-			continue
-		}
-		if strings.Contains(string(i.Name), string(data.RejuvenationPotion)) {
-			currentRejuv++
-		}
-	}
-
-	return currentHealing, currentMana, currentRejuv
-}
-
+// GetMissingCount returns the amount of potions required to fill the belt
 func (bm BeltManager) GetMissingCount(potionType data.PotionType) int {
-	currentHealing, currentMana, currentRejuv := bm.getCurrentPotions()
-
-	switch potionType {
-	case data.HealingPotion:
-		targetAmount := bm.data.CharacterCfg.Inventory.BeltColumns.Total(data.HealingPotion) * bm.data.Inventory.Belt.Rows()
-		missingPots := targetAmount - currentHealing
-		if missingPots < 0 {
-			return 0
-		}
-		return missingPots
-	case data.ManaPotion:
-		targetAmount := bm.data.CharacterCfg.Inventory.BeltColumns.Total(data.ManaPotion) * bm.data.Inventory.Belt.Rows()
-		missingPots := targetAmount - currentMana
-		if missingPots < 0 {
-			return 0
-		}
-		return missingPots
-	case data.RejuvenationPotion:
-		targetAmount := bm.data.CharacterCfg.Inventory.BeltColumns.Total(data.RejuvenationPotion) * bm.data.Inventory.Belt.Rows()
-		missingPots := targetAmount - currentRejuv
-		if missingPots < 0 {
-			return 0
-		}
-		return missingPots
-	}
+	return bm.Plan().Missing(potionType)
+}
 
-	return 0
+// GetBuyCount returns the amount of potions required to fill the belt and the overflow stack
+func (bm BeltManager) GetBuyCount(potionType data.PotionType) int {
+	return bm.Plan().ToBuy(potionType)
 }
@@ -0,0 +1,136 @@
+package curse
+
+import (
+	"slices"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+	"github.com/hectorgimenez/d2go/pkg/utils"
+)
+
+// DefaultAuraRadius is the distance to a monster carrying a hostile aura to consider the player affected by it
+const DefaultAuraRadius = 10
+
+// Curses are the debilitating curses that can be configured, they are read from the player states
+var Curses = map[string]state.State{
+	"amplifydamage": state.Amplifydamage,
+	"decrepify":     state.Decrepify,
+	"ironmaiden":    state.Ironmaiden,
+	"lowerresist":   state.Lowerresist,
+	"weaken":        state.Weaken,
+	"dimvision":     state.Dimvision,
+	"confuse":       state.Confuse,
+	"attract":       state.Attract,
+	"terror":        state.Terror,
+}
+
+// Auras are the hostile auras that can be configured, they are read from the states of the nearby monsters
+var Auras = map[string]state.State{
+	"conviction": state.Conviction,
+	"fanaticism": state.Fanaticism,
+	"might":      state.Might,
+	"holyfire":   state.Holyfire,
+	"holyfreeze": state.Holywindcold,
+	"holyshock":  state.Holyshock,
+}
+
+// Policy defines how the bot reacts to curses and hostile auras, configured per character
+type Policy struct {
+	// ChickenAtIncrease raises the chicken threshold while the curse or aura is active, by name, see Curses and Auras
+	ChickenAtIncrease map[string]int `yaml:"chickenAtIncrease"`
+	// AvoidMeleeUnderIronMaiden stops melee attacks while cursed with Iron Maiden
+	AvoidMeleeUnderIronMaiden bool `yaml:"avoidMeleeUnderIronMaiden"`
+	// AvoidConvictionPacks skips targets close to a monster with Conviction aura, useful for fragile builds
+	AvoidConvictionPacks bool `yaml:"avoidConvictionPacks"`
+	// AuraRadius is the distance to a monster to be affected by its aura
+	AuraRadius int `yaml:"auraRadius"`
+	// UseThawingPotions drinks a thawing potion from the inventory when frozen or chilled
+	UseThawingPotions bool `yaml:"useThawingPotions"`
+	// UseAntidotePotions drinks an antidote potion from the inventory when poisoned
+	UseAntidotePotions bool `yaml:"useAntidotePotions"`
+}
+
+// Situation is the player status evaluated by the policy
+type Situation struct {
+	States   state.States
+	Position data.Position
+	Monsters []data.Monster
+}
+
+// Active returns the names of the configured curses and auras affecting the player, sorted by name
+func (p Policy) Active(s Situation) []string {
+	active := make([]string, 0)
+	for name, st := range Curses {
+		if s.States.HasState(st) {
+			active = append(active, name)
+		}
+	}
+
+	for name, st := range Auras {
+		for _, m := range s.Monsters {
+			if m.States.HasState(st) && utils.DistanceFromPoint(s.Position, m.Position) <= p.auraRadius() {
+				active = append(active, name)
+				break
+			}
+		}
+	}
+	slices.Sort(active)
+
+	return active
+}
+
+// ChickenAt returns the chicken threshold raised by the highest increase of the active curses and auras
+func (p Policy) ChickenAt(base int, s Situation) int {
+	if len(p.ChickenAtIncrease) == 0 {
+		return base
+	}
+
+	increase := 0
+	for _, name := range p.Active(s) {
+		increase = max(increase, p.ChickenAtIncrease[name])
+	}
+
+	return min(base+increase, 99)
+}
+
+// CanMelee returns false when melee attacks would hurt the player, ex: Iron Maiden
+func (p Policy) CanMelee(states state.States) bool {
+	return !p.AvoidMeleeUnderIronMaiden || !states.HasState(state.Ironmaiden)
+}
+
+// AvoidTarget returns true when the target is inside the Conviction aura of any monster and the policy avoids them
+func (p Policy) AvoidTarget(target data.Monster, monsters []data.Monster) bool {
+	if !p.AvoidConvictionPacks {
+		return false
+	}
+
+	for _, m := range monsters {
+		if m.States.HasState(state.Conviction) && utils.DistanceFromPoint(target.Position, m.Position) <= p.auraRadius() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Potion returns the potion curing the player status (frozen, chilled or poisoned), if enabled. Nothing is returned
+// while the protection of a previous potion is still active, so potions are not wasted under sustained chill or poison.
+func (p Policy) Potion(states state.States) (item.Name, bool) {
+	if p.UseThawingPotions && !states.HasState(state.Thawing) && (states.HasState(state.Freeze) || states.HasState(state.Cold)) {
+		return "ThawingPotion", true
+	}
+	if p.UseAntidotePotions && !states.HasState(state.Antidote) && states.HasState(state.Poison) {
+		return "AntidotePotion", true
+	}
+
+	return "", false
+}
+
+func (p Policy) auraRadius() int {
+	if p.AuraRadius <= 0 {
+		return DefaultAuraRadius
+	}
+
+	return p.AuraRadius
+}
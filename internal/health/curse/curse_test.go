@@ -0,0 +1,89 @@
+package curse
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/state"
+)
+
+func monster(x, y int, states ...state.State) data.Monster {
+	return data.Monster{Position: data.Position{X: x, Y: y}, States: states}
+}
+
+func TestChickenAt(t *testing.T) {
+	policy := Policy{ChickenAtIncrease: map[string]int{"amplifydamage": 15, "decrepify": 10, "conviction": 20}}
+
+	tests := []struct {
+		name      string
+		situation Situation
+		expected  int
+	}{
+		{name: "no curses", situation: Situation{}, expected: 30},
+		{name: "amplify damage", situation: Situation{States: state.States{state.Amplifydamage}}, expected: 45},
+		{name: "highest increase is used", situation: Situation{States: state.States{state.Decrepify, state.Amplifydamage}}, expected: 45},
+		{name: "curse not configured", situation: Situation{States: state.States{state.Weaken}}, expected: 30},
+		{name: "conviction aura close", situation: Situation{Monsters: []data.Monster{monster(5, 5, state.Conviction)}}, expected: 50},
+		{name: "conviction aura far away", situation: Situation{Monsters: []data.Monster{monster(50, 50, state.Conviction)}}, expected: 30},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.ChickenAt(30, tc.situation); got != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, got)
+			}
+		})
+	}
+
+	if got := (Policy{ChickenAtIncrease: map[string]int{"ironmaiden": 90}}).ChickenAt(30, Situation{States: state.States{state.Ironmaiden}}); got != 99 {
+		t.Errorf("chicken threshold should be capped, got %d", got)
+	}
+}
+
+func TestCanMelee(t *testing.T) {
+	if !(Policy{}).CanMelee(state.States{state.Ironmaiden}) {
+		t.Error("melee should be allowed when not configured")
+	}
+	policy := Policy{AvoidMeleeUnderIronMaiden: true}
+	if policy.CanMelee(state.States{state.Ironmaiden}) || !policy.CanMelee(state.States{state.Amplifydamage}) {
+		t.Error("melee should only be stopped under Iron Maiden")
+	}
+}
+
+func TestAvoidTarget(t *testing.T) {
+	policy := Policy{AvoidConvictionPacks: true, AuraRadius: 5}
+	monsters := []data.Monster{monster(10, 10, state.Conviction), monster(12, 12), monster(30, 30)}
+
+	if !policy.AvoidTarget(monsters[1], monsters) {
+		t.Error("target inside the conviction aura should be avoided")
+	}
+	if policy.AvoidTarget(monsters[2], monsters) {
+		t.Error("target outside the conviction aura should not be avoided")
+	}
+	if (Policy{}).AvoidTarget(monsters[1], monsters) {
+		t.Error("targets should not be avoided when not configured")
+	}
+}
+
+func TestPotion(t *testing.T) {
+	policy := Policy{UseThawingPotions: true}
+	if p, found := policy.Potion(state.States{state.Cold}); !found || p != "ThawingPotion" {
+		t.Errorf("expected thawing potion, got %s", p)
+	}
+	if _, found := policy.Potion(state.States{state.Poison}); found {
+		t.Error("antidote potions are disabled")
+	}
+}
+
+func TestPotionWhileProtected(t *testing.T) {
+	policy := Policy{UseThawingPotions: true, UseAntidotePotions: true}
+	if _, found := policy.Potion(state.States{state.Cold, state.Thawing}); found {
+		t.Error("expected no potion while thawing is active")
+	}
+	if _, found := policy.Potion(state.States{state.Poison, state.Antidote}); found {
+		t.Error("expected no potion while antidote is active")
+	}
+	if p, found := policy.Potion(state.States{state.Poison, state.Cold, state.Thawing}); !found || p != "AntidotePotion" {
+		t.Errorf("expected antidote potion, got %s", p)
+	}
+}
@@ -0,0 +1,130 @@
+package damage
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// DefaultWindow is the HP history used to estimate the incoming damage when not configured
+	DefaultWindow = 2 * time.Second
+	// Damage taken is spread over at least this duration, a single reading drop is not extrapolated to 100ms
+	minSpan = 300 * time.Millisecond
+)
+
+// Action is the proactive reaction to the incoming damage
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionRejuv
+	ActionChicken
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionRejuv:
+		return "rejuv"
+	case ActionChicken:
+		return "chicken"
+	}
+
+	return "none"
+}
+
+type sample struct {
+	at time.Time
+	hp int
+}
+
+// Tracker estimates the incoming damage per second from the recent HP history. Only HP losses are accounted, so
+// healing from potions or leech doesn't hide the damage being taken.
+type Tracker struct {
+	// Window is the HP history used to estimate the damage rate
+	Window time.Duration
+	// ChickenHorizon triggers a chicken when the predicted time to death is shorter
+	ChickenHorizon time.Duration
+	// RejuvHorizon triggers a rejuvenation potion when the predicted time to death is shorter
+	RejuvHorizon time.Duration
+
+	samples []sample
+}
+
+// Add stores a new HP reading (in percent)
+func (t *Tracker) Add(hp int, now time.Time) {
+	t.samples = append(t.samples, sample{at: now, hp: hp})
+
+	window := t.window()
+	first := 0
+	for first < len(t.samples)-1 && now.Sub(t.samples[first].at) > window {
+		first++
+	}
+	t.samples = t.samples[first:]
+}
+
+// Reset clears the HP history, it should be called when the damage is not comparable anymore, ex: new game or town
+func (t *Tracker) Reset() {
+	t.samples = t.samples[:0]
+}
+
+// DPS returns the HP percent lost per second over the window
+func (t *Tracker) DPS() float64 {
+	if len(t.samples) < 2 {
+		return 0
+	}
+
+	lost := 0
+	for i := 1; i < len(t.samples); i++ {
+		if diff := t.samples[i-1].hp - t.samples[i].hp; diff > 0 {
+			lost += diff
+		}
+	}
+	if lost == 0 {
+		return 0
+	}
+
+	span := max(t.samples[len(t.samples)-1].at.Sub(t.samples[0].at), minSpan)
+
+	return float64(lost) / span.Seconds()
+}
+
+// TimeToDeath returns the predicted time until the HP reaches 0 at the current damage rate multiplied by the given
+// danger multiplier, math.MaxInt64 when no damage is being taken
+func (t *Tracker) TimeToDeath(multiplier float64) time.Duration {
+	if len(t.samples) == 0 {
+		return math.MaxInt64
+	}
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+
+	dps := t.DPS() * multiplier
+	if dps <= 0 {
+		return math.MaxInt64
+	}
+
+	hp := t.samples[len(t.samples)-1].hp
+
+	return time.Duration(float64(hp) / dps * float64(time.Second))
+}
+
+// Decide returns the action to take given the predicted time to death, chicken has priority over rejuv
+func (t *Tracker) Decide(multiplier float64) Action {
+	ttd := t.TimeToDeath(multiplier)
+	switch {
+	case t.ChickenHorizon > 0 && ttd < t.ChickenHorizon:
+		return ActionChicken
+	case t.RejuvHorizon > 0 && ttd < t.RejuvHorizon:
+		return ActionRejuv
+	}
+
+	return ActionNone
+}
+
+func (t *Tracker) window() time.Duration {
+	if t.Window <= 0 {
+		return DefaultWindow
+	}
+
+	return t.Window
+}
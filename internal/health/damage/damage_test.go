@@ -0,0 +1,89 @@
+package damage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const tick = 100 * time.Millisecond
+
+// trace feeds the HP readings to the tracker, one per tick, and returns the time of the last one
+func trace(t *Tracker, start time.Time, hps ...int) time.Time {
+	now := start
+	for i, hp := range hps {
+		now = start.Add(time.Duration(i) * tick)
+		t.Add(hp, now)
+	}
+
+	return now
+}
+
+func TestDPS(t *testing.T) {
+	tests := []struct {
+		name     string
+		hps      []int
+		expected float64
+	}{
+		{name: "no damage", hps: []int{100, 100, 100, 100}, expected: 0},
+		{name: "steady damage", hps: []int{100, 95, 90, 85, 80, 75, 70}, expected: 50},
+		{name: "healing does not hide damage", hps: []int{100, 90, 100, 90, 100, 90, 100}, expected: 50},
+		{name: "single drop is spread over the min span", hps: []int{100, 70}, expected: 100},
+		{name: "single reading", hps: []int{50}, expected: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &Tracker{}
+			trace(tr, time.Now(), tc.hps...)
+			if got := tr.DPS(); math.Abs(got-tc.expected) > 0.01 {
+				t.Errorf("expected %.2f, got %.2f", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tr := &Tracker{Window: 500 * time.Millisecond}
+	start := time.Now()
+
+	// Big hit, then nothing for a while: the hit leaves the window
+	trace(tr, start, 100, 60, 60, 60, 60, 60, 60, 60, 60, 60)
+	if tr.DPS() != 0 {
+		t.Errorf("old damage should leave the window, got %.2f", tr.DPS())
+	}
+
+	tr.Reset()
+	if tr.TimeToDeath(1) != math.MaxInt64 {
+		t.Errorf("expected no prediction after reset")
+	}
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name       string
+		hps        []int
+		multiplier float64
+		expected   Action
+	}{
+		{name: "idle", hps: []int{80, 80, 80, 80, 80}, expected: ActionNone},
+		// 5% per tick, 50%/s with 70% left: 1.4s to death
+		{name: "slow damage", hps: []int{100, 95, 90, 85, 80, 75, 70}, expected: ActionNone},
+		{name: "slow damage in dangerous area", hps: []int{100, 95, 90, 85, 80, 75, 70}, multiplier: 2, expected: ActionRejuv},
+		// Souls burst: 25% per tick, 250%/s with 50% left: 200ms to death
+		{name: "burst", hps: []int{100, 100, 75, 50}, expected: ActionChicken},
+		// 10% per tick, 100%/s with 60% left: 600ms to death
+		{name: "fast damage", hps: []int{100, 90, 80, 70, 60}, expected: ActionRejuv},
+		{name: "damage healed by leech", hps: []int{90, 80, 90, 80, 90, 80, 90}, expected: ActionNone},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &Tracker{ChickenHorizon: 500 * time.Millisecond, RejuvHorizon: 1200 * time.Millisecond}
+			trace(tr, time.Now(), tc.hps...)
+			if got := tr.Decide(tc.multiplier); got != tc.expected {
+				t.Errorf("expected %s, got %s (time to death %s)", tc.expected, got, tr.TimeToDeath(tc.multiplier))
+			}
+		})
+	}
+}
@@ -3,15 +3,21 @@ package health
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/koolo/internal/event"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/health/curse"
+	"github.com/hectorgimenez/koolo/internal/health/damage"
+	"github.com/hectorgimenez/koolo/internal/merc"
 )
 
 var ErrDied = errors.New("you died :(")
 var ErrChicken = errors.New("chicken")
 var ErrMercChicken = errors.New("mercenary chicken")
+var ErrCorpseLost = fmt.Errorf("%w: corpse could not be recovered", ErrDied)
 
 const (
 	healingInterval     = time.Second * 4
@@ -29,19 +35,29 @@ type Manager struct {
 	lastMercHeal  time.Time
 	beltManager   *BeltManager
 	data          *game.Data
+	merc          *merc.Tracker
+	damage        *damage.Tracker
 }
 
 func NewHealthManager(bm *BeltManager, data *game.Data) *Manager {
 	return &Manager{
 		beltManager: bm,
 		data:        data,
+		merc:        &merc.Tracker{},
+		damage:      &damage.Tracker{},
 	}
 }
 
+// Merc returns the mercenary status tracker
+func (hm *Manager) Merc() *merc.Tracker {
+	return hm.merc
+}
+
 func (hm *Manager) HandleHealthAndMana() error {
 	hpConfig := hm.data.CharacterCfg.Health
 	// Safe area, skipping
 	if hm.data.PlayerUnit.Area.IsTown() {
+		hm.damage.Reset()
 		return nil
 	}
 
@@ -49,11 +65,34 @@ func (hm *Manager) HandleHealthAndMana() error {
 		return ErrDied
 	}
 
-	// Player chicken check
-	if hm.data.PlayerUnit.HPPercent() <= hpConfig.ChickenAt {
+	// Player chicken check, threshold is raised while dangerous curses or auras are active
+	situation := curse.Situation{States: hm.data.PlayerUnit.States, Position: hm.data.PlayerUnit.Position, Monsters: hm.data.Monsters.Enemies()}
+	if chickenAt := hm.data.CharacterCfg.Curses.ChickenAt(hpConfig.ChickenAt, situation); hm.data.PlayerUnit.HPPercent() <= chickenAt {
+		if chickenAt != hpConfig.ChickenAt {
+			return fmt.Errorf("%w: Current Health: %d percent, active curses: %s", ErrChicken, hm.data.PlayerUnit.HPPercent(), strings.Join(hm.data.CharacterCfg.Curses.Active(situation), ", "))
+		}
 		return fmt.Errorf("%w: Current Health: %d percent", ErrChicken, hm.data.PlayerUnit.HPPercent())
 	}
 
+	// Predicted time to death check, reacts to burst damage before reaching the chicken threshold
+	if hpConfig.DamageRate.Enabled {
+		switch action := hm.damageRateAction(); action {
+		case damage.ActionChicken:
+			return fmt.Errorf("%w: Predicted death in %s, Current Health: %d percent", ErrChicken, hm.damage.TimeToDeath(hm.areaMultiplier()).Round(time.Millisecond), hm.data.PlayerUnit.HPPercent())
+		case damage.ActionRejuv:
+			if time.Since(hm.lastRejuv) > rejuvInterval && hm.beltManager.DrinkPotion(data.RejuvenationPotion, false) {
+				hm.beltManager.logger.Debug(fmt.Sprintf("Rejuvenation potion used, predicted death in %s", hm.damage.TimeToDeath(hm.areaMultiplier()).Round(time.Millisecond)))
+				hm.lastRejuv = time.Now()
+				return nil
+			}
+		}
+	}
+
+	if hm.data.CharacterCfg.Character.UseMerc && hm.merc.Update(hm.data.MercHPPercent(), time.Now()) {
+		hm.beltManager.logger.Info("Mercenary died")
+		event.Send(event.MercDied(event.Text(hm.beltManager.supervisor, fmt.Sprintf("Mercenary died in %s", hm.data.PlayerUnit.Area.Area().Name)), hm.data.PlayerUnit.Area))
+	}
+
 	// Mercenary chicken check
 	if hm.data.MercHPPercent() > 0 && hm.data.MercHPPercent() <= hpConfig.MercChickenAt {
 		return fmt.Errorf("%w: Current Merc Health: %d percent", ErrMercChicken, hm.data.MercHPPercent())
@@ -107,3 +146,22 @@ func (hm *Manager) HandleHealthAndMana() error {
 
 	return nil
 }
+
+func (hm *Manager) damageRateAction() damage.Action {
+	cfg := hm.data.CharacterCfg.Health.DamageRate
+	hm.damage.Window = time.Duration(cfg.Window) * time.Millisecond
+	hm.damage.ChickenHorizon = time.Duration(cfg.ChickenHorizon) * time.Millisecond
+	hm.damage.RejuvHorizon = time.Duration(cfg.RejuvHorizon) * time.Millisecond
+
+	hm.damage.Add(hm.data.PlayerUnit.HPPercent(), time.Now())
+
+	return hm.damage.Decide(hm.areaMultiplier())
+}
+
+func (hm *Manager) areaMultiplier() float64 {
+	if m, found := hm.data.CharacterCfg.Health.DamageRate.AreaMultipliers[hm.data.PlayerUnit.Area]; found && m > 0 {
+		return m
+	}
+
+	return 1
+}
@@ -0,0 +1,162 @@
+package merc
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+// Merc is not detected for a moment when changing areas, so it's only considered dead after this delay
+const deathDelay = 1500 * time.Millisecond
+
+type Decision int
+
+const (
+	DecisionNone Decision = iota
+	DecisionRevive
+	DecisionRehire
+)
+
+func (d Decision) String() string {
+	switch d {
+	case DecisionRevive:
+		return "revive"
+	case DecisionRehire:
+		return "rehire"
+	}
+
+	return "none"
+}
+
+// Policy decides what to do with a dead mercenary
+type Policy struct {
+	// MaxReviveCost is the max gold paid to revive the merc, a new one is hired when it's more expensive. 0 means no limit
+	MaxReviveCost int
+	// GoldFloor is the gold that should be kept after paying
+	GoldFloor int
+	// Rehire allows hiring a new merc when reviving is too expensive, equipped items are lost
+	Rehire bool
+}
+
+// Decide returns if the merc should be revived or re-hired given the current gold and the expected revive cost.
+// Cost is 0 when it's not known yet, the revive is tried then and the game refuses it if there isn't enough gold.
+func (p Policy) Decide(gold, reviveCost int) Decision {
+	if reviveCost == 0 {
+		if gold > p.GoldFloor {
+			return DecisionRevive
+		}
+
+		return DecisionNone
+	}
+
+	affordable := gold-reviveCost >= p.GoldFloor
+	if affordable && (p.MaxReviveCost == 0 || reviveCost <= p.MaxReviveCost) {
+		return DecisionRevive
+	}
+
+	// Hire cost is only known at the contractor, and it's lower than the revive cost for high level mercs
+	if p.Rehire && gold > p.GoldFloor {
+		return DecisionRehire
+	}
+
+	return DecisionNone
+}
+
+// Tracker keeps the mercenary status for a supervisor across games
+type Tracker struct {
+	mu             sync.Mutex
+	alive          bool
+	missingSince   time.Time
+	lastReviveCost int
+}
+
+// Update should be called periodically while the player is out of town, returns true once when the merc dies
+func (t *Tracker) Update(hpPercent int, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if hpPercent > 0 {
+		t.alive = true
+		t.missingSince = time.Time{}
+		return false
+	}
+
+	if !t.alive {
+		return false
+	}
+
+	if t.missingSince.IsZero() {
+		t.missingSince = now
+		return false
+	}
+
+	if now.Sub(t.missingSince) < deathDelay {
+		return false
+	}
+
+	t.alive = false
+	t.missingSince = time.Time{}
+
+	return true
+}
+
+// SetReviveCost stores the last paid revive cost, it's used as estimation for the next one
+func (t *Tracker) SetReviveCost(cost int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastReviveCost = cost
+}
+
+// ReviveCost returns the expected revive cost, 0 until the first revive since the cost is not known yet
+func (t *Tracker) ReviveCost() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.lastReviveCost
+}
+
+// Slot returns the mercenary body location used by the item
+func Slot(itm data.Item) item.LocationType {
+	t := itm.Desc().GetType()
+	switch {
+	case slices.Contains(t.BodyLocs, item.LocHead):
+		return item.LocHead
+	case slices.Contains(t.BodyLocs, item.LocTorso):
+		return item.LocTorso
+	case t.Code == item.TypeShield || t.Code == item.TypeAuricShields:
+		return item.LocLeftArm
+	case slices.Contains(t.BodyLocs, item.LocRightArm):
+		return item.LocRightArm
+	}
+
+	return item.LocNone
+}
+
+// Upgrades returns the items to equip on the mercenary, one per slot. Slots are only upgraded when empty or when the
+// equipped item doesn't match the rules, so the rules decide what is good enough for the merc.
+func Upgrades(candidates, equipped []data.Item, matches func(data.Item) bool) []data.Item {
+	upgrades := make([]data.Item, 0)
+	used := make(map[item.LocationType]bool)
+
+	for _, eq := range equipped {
+		if matches(eq) {
+			used[eq.Location.BodyLocation] = true
+		}
+	}
+
+	for _, c := range candidates {
+		slot := Slot(c)
+		if slot == item.LocNone || used[slot] || !matches(c) {
+			continue
+		}
+
+		used[slot] = true
+		upgrades = append(upgrades, c)
+	}
+
+	return upgrades
+}
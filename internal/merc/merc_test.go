@@ -0,0 +1,108 @@
+package merc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+)
+
+func TestDecide(t *testing.T) {
+	policy := Policy{MaxReviveCost: 30000, GoldFloor: 10000}
+	rehire := Policy{MaxReviveCost: 30000, GoldFloor: 10000, Rehire: true}
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		gold       int
+		reviveCost int
+		expected   Decision
+	}{
+		{name: "revive", policy: policy, gold: 100000, reviveCost: 20000, expected: DecisionRevive},
+		{name: "below gold floor", policy: policy, gold: 25000, reviveCost: 20000, expected: DecisionNone},
+		{name: "too expensive", policy: policy, gold: 100000, reviveCost: 40000, expected: DecisionNone},
+		{name: "too expensive rehire", policy: rehire, gold: 100000, reviveCost: 40000, expected: DecisionRehire},
+		{name: "not affordable rehire", policy: rehire, gold: 25000, reviveCost: 20000, expected: DecisionRehire},
+		{name: "no gold", policy: rehire, gold: 5000, reviveCost: 20000, expected: DecisionNone},
+		{name: "no limit", policy: Policy{}, gold: 50000, reviveCost: 50000, expected: DecisionRevive},
+		{name: "unknown cost low gold", policy: Policy{}, gold: 3000, reviveCost: 0, expected: DecisionRevive},
+		{name: "unknown cost above gold floor", policy: policy, gold: 15000, reviveCost: 0, expected: DecisionRevive},
+		{name: "unknown cost below gold floor", policy: rehire, gold: 8000, reviveCost: 0, expected: DecisionNone},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Decide(tc.gold, tc.reviveCost); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestTrackerDeath(t *testing.T) {
+	tr := &Tracker{}
+	now := time.Now()
+
+	if tr.Update(0, now) {
+		t.Fatal("merc never seen alive should not be reported as dead")
+	}
+	tr.Update(80, now)
+
+	// Area change, merc is not detected for a moment
+	if tr.Update(0, now.Add(100*time.Millisecond)) || tr.Update(75, now.Add(500*time.Millisecond)) {
+		t.Fatal("merc missing for a moment should not be reported as dead")
+	}
+
+	tr.Update(0, now.Add(time.Second))
+	if !tr.Update(0, now.Add(3*time.Second)) {
+		t.Fatal("merc should be reported as dead")
+	}
+	if tr.Update(0, now.Add(5*time.Second)) {
+		t.Fatal("death should be reported only once")
+	}
+}
+
+func TestReviveCost(t *testing.T) {
+	tr := &Tracker{}
+	if tr.ReviveCost() != 0 {
+		t.Errorf("expected unknown revive cost before the first revive")
+	}
+	if d := (Policy{}).Decide(3000, tr.ReviveCost()); d != DecisionRevive {
+		t.Errorf("expected revive with low gold and unknown cost, got %s", d)
+	}
+	tr.SetReviveCost(12000)
+	if tr.ReviveCost() != 12000 {
+		t.Errorf("expected last revive cost, got %d", tr.ReviveCost())
+	}
+}
+
+func TestUpgrades(t *testing.T) {
+	newItem := func(name string, loc item.LocationType, bodyLoc item.LocationType) data.Item {
+		return data.Item{ID: item.GetIDByName(name), Name: item.Name(name), Location: item.Location{LocationType: loc, BodyLocation: bodyLoc}}
+	}
+
+	matches := func(i data.Item) bool {
+		return i.Name == "Shako" || i.Name == "Partizan" || i.Name == "Thresher" || i.Name == "Monarch"
+	}
+
+	equipped := []data.Item{
+		newItem("Cap", item.LocationMercenary, item.LocHead),
+		newItem("Thresher", item.LocationMercenary, item.LocRightArm),
+	}
+	stash := []data.Item{
+		newItem("Partizan", item.LocationStash, ""),
+		newItem("Shako", item.LocationStash, ""),
+		newItem("Cap", item.LocationStash, ""),
+		newItem("Monarch", item.LocationStash, ""),
+	}
+
+	upgrades := Upgrades(stash, equipped, matches)
+	if len(upgrades) != 2 || upgrades[0].Name != "Shako" || upgrades[1].Name != "Monarch" {
+		t.Errorf("unexpected upgrades %v", upgrades)
+	}
+
+	if Slot(stash[0]) != item.LocRightArm || Slot(stash[1]) != item.LocHead || Slot(stash[3]) != item.LocLeftArm {
+		t.Errorf("unexpected slots")
+	}
+}
@@ -0,0 +1,173 @@
+package recipe
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+type MatchOptions struct {
+	// Rules are the pickit rules, used by ingredients with pickit mode set
+	Rules nip.Rules
+	// Exclude is a list of item names that won't be used to fill anyOf ingredients, ingredients requiring the item
+	// by name are not affected
+	Exclude []string
+}
+
+// Match looks for a combination of items satisfying all the recipe ingredients, every item is used only once.
+// Crafting recipes also require the base to be present in the given items.
+func (r Recipe) Match(items []data.Item, opts MatchOptions) ([]data.Item, bool) {
+	ingredients := r.ingredients()
+	if r.Base != nil {
+		ingredients = append(ingredients, &r.Base.Ingredient)
+	}
+
+	return match(ingredients, items, opts)
+}
+
+// MatchIngredients is like Match but ignores the base, used when the base is going to be bought
+func (r Recipe) MatchIngredients(items []data.Item, opts MatchOptions) ([]data.Item, bool) {
+	return match(r.ingredients(), items, opts)
+}
+
+func (r Recipe) ingredients() []*Ingredient {
+	ingredients := make([]*Ingredient, 0, len(r.Ingredients)+1)
+	for i := range r.Ingredients {
+		ingredients = append(ingredients, &r.Ingredients[i])
+	}
+
+	return ingredients
+}
+
+func match(ingredients []*Ingredient, items []data.Item, opts MatchOptions) ([]data.Item, bool) {
+	slots := make([]*Ingredient, 0)
+	candidates := make(map[*Ingredient][]int)
+	for _, ing := range ingredients {
+		for idx, itm := range items {
+			if ing.Matches(itm, opts) {
+				candidates[ing] = append(candidates[ing], idx)
+			}
+		}
+
+		// Fail fast, not enough items for this ingredient no matter the combination
+		if len(candidates[ing]) < ing.Count {
+			return nil, false
+		}
+		for c := 0; c < ing.Count; c++ {
+			slots = append(slots, ing)
+		}
+	}
+
+	used := make([]bool, len(items))
+	picked := make([]int, len(slots))
+	if !fillSlots(slots, candidates, used, picked, 0) {
+		return nil, false
+	}
+
+	result := make([]data.Item, 0, len(picked))
+	for _, idx := range picked {
+		result = append(result, items[idx])
+	}
+
+	return result, true
+}
+
+func fillSlots(slots []*Ingredient, candidates map[*Ingredient][]int, used []bool, picked []int, slot int) bool {
+	if slot == len(slots) {
+		return true
+	}
+
+	ing := slots[slot]
+	// Slots for the same ingredient are interchangeable, only try increasing indexes to avoid checking permutations
+	minIdx := -1
+	if slot > 0 && slots[slot-1] == ing {
+		minIdx = picked[slot-1]
+	}
+
+	for _, idx := range candidates[ing] {
+		if idx <= minIdx || used[idx] {
+			continue
+		}
+
+		used[idx] = true
+		picked[slot] = idx
+		if fillSlots(slots, candidates, used, picked, slot+1) {
+			return true
+		}
+		used[idx] = false
+	}
+
+	return false
+}
+
+// Matches returns true when the item satisfies all the ingredient conditions
+func (ing Ingredient) Matches(itm data.Item, opts MatchOptions) bool {
+	name := string(itm.Name)
+	if !strings.EqualFold(ing.Name, name) {
+		if !containsName(ing.AnyOf, name) || containsName(opts.Exclude, name) {
+			return false
+		}
+	}
+
+	if len(ing.Quality) > 0 && !slices.ContainsFunc(ing.Quality, func(q string) bool { return qualities[strings.ToLower(q)] == itm.Quality }) {
+		return false
+	}
+
+	if ing.Ethereal != nil && *ing.Ethereal != itm.Ethereal {
+		return false
+	}
+
+	if ing.MinLevel > 0 && itm.LevelReq < ing.MinLevel {
+		return false
+	}
+
+	if ing.MaxLevel > 0 && itm.LevelReq > ing.MaxLevel {
+		return false
+	}
+
+	if ing.Sockets != nil {
+		sockets, _ := itm.FindStat(stat.NumSockets, 0)
+		if sockets.Value != *ing.Sockets {
+			return false
+		}
+	}
+
+	if ing.statsRule != nil {
+		// Unidentified items return partial match, we can not know the stats so they are not valid
+		if res, err := ing.statsRule.Evaluate(itm); err != nil || res != nip.RuleResultFullMatch {
+			return false
+		}
+	}
+
+	switch ing.Pickit {
+	case PickitUnmatched:
+		if _, res := opts.Rules.EvaluateAll(itm); res == nip.RuleResultFullMatch {
+			return false
+		}
+	case PickitMatched:
+		if _, res := opts.Rules.EvaluateAll(itm); res != nip.RuleResultFullMatch {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Uses returns true if the item name can be used by any of the recipe ingredients. Crafting bases are left out,
+// they are common magic items and keeping every one of them would fill the stash.
+func (r Recipe) Uses(name string) bool {
+	for _, ing := range r.Ingredients {
+		if strings.EqualFold(ing.Name, name) || containsName(ing.AnyOf, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsName(names []string, name string) bool {
+	return slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, name) })
+}
@@ -0,0 +1,351 @@
+package recipe
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+)
+
+func TestDefaults(t *testing.T) {
+	recipes := Defaults()
+	if len(recipes) != 61 {
+		t.Errorf("Expected 61 default recipes, got %d", len(recipes))
+	}
+
+	for _, r := range recipes {
+		for _, ing := range r.Ingredients {
+			if ing.Count < 1 {
+				t.Errorf("Recipe %s has an ingredient without count", r.Name)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{name: "Missing recipe name", raw: "recipes: [{ingredients: [{name: ElRune}]}]"},
+		{name: "Duplicated recipe", raw: "recipes: [{name: a, ingredients: [{name: ElRune}]}, {name: a, ingredients: [{name: ElRune}]}]"},
+		{name: "No ingredients", raw: "recipes: [{name: a}]"},
+		{name: "Ingredient without name", raw: "recipes: [{name: a, ingredients: [{count: 2}]}]"},
+		{name: "Unknown quality", raw: "recipes: [{name: a, ingredients: [{name: Jewel, quality: [legendary]}]}]"},
+		{name: "Unknown pickit mode", raw: "recipes: [{name: a, ingredients: [{name: Jewel, pickit: maybe}]}]"},
+		{name: "Invalid stats", raw: "recipes: [{name: a, ingredients: [{name: Jewel, stats: '[fcr] >>= 2'}]}]"},
+		{name: "Unknown base source", raw: "recipes: [{name: a, ingredients: [{name: Jewel}], base: {name: Amulet, sources: [trade]}}]"},
+		{name: "Multiple bases", raw: "recipes: [{name: a, ingredients: [{name: Jewel}], base: {name: Amulet, count: 2}}]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.raw), "test.yaml"); err == nil {
+				t.Errorf("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	recipes, err := Parse([]byte(`{"recipes": [{"name": "Socket Armor", "ingredients": [{"name": "TalRune"}, {"name": "ThulRune"}, {"name": "PerfectTopaz"}, {"name": "MagePlate", "quality": ["normal"], "sockets": 0}]}]}`), "test.json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(recipes) != 1 || len(recipes[0].Ingredients) != 4 {
+		t.Fatalf("Unexpected recipes parsed: %+v", recipes)
+	}
+	if recipes[0].Ingredients[3].Sockets == nil || *recipes[0].Ingredients[3].Sockets != 0 {
+		t.Errorf("Expected sockets condition to be parsed")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := []Recipe{{Name: "a"}, {Name: "b"}}
+	merged := Merge(base, []Recipe{{Name: "b", Base: &Base{}}, {Name: "c"}})
+
+	if len(merged) != 3 {
+		t.Fatalf("Expected 3 recipes, got %d", len(merged))
+	}
+	if !merged[1].IsCrafting() {
+		t.Errorf("Expected recipe b to be overridden")
+	}
+	if merged[2].Name != "c" {
+		t.Errorf("Expected recipe c to be appended")
+	}
+	if base[1].IsCrafting() {
+		t.Errorf("Base recipes should not be modified")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	pickit := mustRules(t, "[name] == jewel && [quality] == magic # [fcr] >= 15")
+
+	tests := []struct {
+		name      string
+		recipe    string
+		items     []data.Item
+		opts      MatchOptions
+		wantFound bool
+		wantIDs   []data.UnitID
+	}{
+		{
+			name:      "Rune upgrade",
+			recipe:    "recipes: [{name: r, ingredients: [{name: ElRune, count: 3}]}]",
+			items:     []data.Item{newItem(1, "ElRune"), newItem(2, "EldRune"), newItem(3, "ElRune"), newItem(4, "ElRune")},
+			wantFound: true,
+			wantIDs:   []data.UnitID{1, 3, 4},
+		},
+		{
+			name:   "Not enough items",
+			recipe: "recipes: [{name: r, ingredients: [{name: ElRune, count: 3}]}]",
+			items:  []data.Item{newItem(1, "ElRune"), newItem(2, "ElRune")},
+		},
+		{
+			name:      "Item names are case insensitive",
+			recipe:    "recipes: [{name: r, ingredients: [{name: elrune}]}]",
+			items:     []data.Item{newItem(1, "ElRune")},
+			wantFound: true,
+			wantIDs:   []data.UnitID{1},
+		},
+		{
+			name:   "Quality and ethereal",
+			recipe: "recipes: [{name: r, ingredients: [{name: Jewel, quality: [magic, rare], ethereal: false}]}]",
+			items: []data.Item{
+				withQuality(newItem(1, "Jewel"), item.QualityUnique),
+				withEthereal(withQuality(newItem(2, "Jewel"), item.QualityRare)),
+				withQuality(newItem(3, "Jewel"), item.QualityRare),
+			},
+			wantFound: true,
+			wantIDs:   []data.UnitID{3},
+		},
+		{
+			name:   "Level range",
+			recipe: "recipes: [{name: r, ingredients: [{name: Amulet, minLevel: 20, maxLevel: 40}]}]",
+			items: []data.Item{
+				withLevel(newItem(1, "Amulet"), 12),
+				withLevel(newItem(2, "Amulet"), 55),
+				withLevel(newItem(3, "Amulet"), 30),
+			},
+			wantFound: true,
+			wantIDs:   []data.UnitID{3},
+		},
+		{
+			name:   "Socket count",
+			recipe: "recipes: [{name: r, ingredients: [{name: MagePlate, sockets: 0}]}]",
+			items: []data.Item{
+				withStats(newItem(1, "MagePlate"), stat.Data{ID: stat.NumSockets, Value: 3}),
+				newItem(2, "MagePlate"),
+			},
+			wantFound: true,
+			wantIDs:   []data.UnitID{2},
+		},
+		{
+			name:   "Stats expression",
+			recipe: "recipes: [{name: r, ingredients: [{name: SmallCharm, stats: '[maxhp] >= 15'}]}]",
+			items: []data.Item{
+				withStats(newItem(1, "SmallCharm"), stat.Data{ID: stat.MaxLife, Value: 10}),
+				withStats(newItem(2, "SmallCharm"), stat.Data{ID: stat.MaxLife, Value: 20}),
+			},
+			wantFound: true,
+			wantIDs:   []data.UnitID{2},
+		},
+		{
+			name:   "Unidentified items never match stats expressions",
+			recipe: "recipes: [{name: r, ingredients: [{name: SmallCharm, stats: '[maxhp] >= 15'}]}]",
+			items: []data.Item{
+				unidentified(withStats(newItem(1, "SmallCharm"), stat.Data{ID: stat.MaxLife, Value: 20})),
+			},
+		},
+		{
+			name:   "Pickit keepers are not used",
+			recipe: "recipes: [{name: r, ingredients: [{name: Jewel, pickit: unmatched}]}]",
+			items: []data.Item{
+				withStats(withQuality(newItem(1, "Jewel"), item.QualityMagic), stat.Data{ID: stat.FasterCastRate, Value: 15}),
+				withStats(withQuality(newItem(2, "Jewel"), item.QualityMagic), stat.Data{ID: stat.FasterCastRate, Value: 5}),
+			},
+			opts:      MatchOptions{Rules: pickit},
+			wantFound: true,
+			wantIDs:   []data.UnitID{2},
+		},
+		{
+			name:   "Only pickit keepers",
+			recipe: "recipes: [{name: r, ingredients: [{name: Jewel, pickit: matched}]}]",
+			items: []data.Item{
+				withStats(withQuality(newItem(1, "Jewel"), item.QualityMagic), stat.Data{ID: stat.FasterCastRate, Value: 5}),
+				withStats(withQuality(newItem(2, "Jewel"), item.QualityMagic), stat.Data{ID: stat.FasterCastRate, Value: 15}),
+			},
+			opts:      MatchOptions{Rules: pickit},
+			wantFound: true,
+			wantIDs:   []data.UnitID{2},
+		},
+		{
+			name:      "Excluded names",
+			recipe:    "recipes: [{name: r, ingredients: [{anyOf: [PerfectRuby, PerfectTopaz], count: 2}]}]",
+			items:     []data.Item{newItem(1, "PerfectRuby"), newItem(2, "PerfectTopaz"), newItem(3, "PerfectTopaz")},
+			opts:      MatchOptions{Exclude: []string{"PerfectRuby"}},
+			wantFound: true,
+			wantIDs:   []data.UnitID{2, 3},
+		},
+		{
+			name:      "Excluded names are still used when required by name",
+			recipe:    "recipes: [{name: r, ingredients: [{name: PerfectRuby}]}]",
+			items:     []data.Item{newItem(1, "PerfectRuby")},
+			opts:      MatchOptions{Exclude: []string{"PerfectRuby"}},
+			wantFound: true,
+			wantIDs:   []data.UnitID{1},
+		},
+		{
+			name:   "Overlapping ingredients are resolved",
+			recipe: "recipes: [{name: r, ingredients: [{anyOf: [PerfectRuby, PerfectTopaz], count: 2}, {name: PerfectRuby}]}]",
+			// A greedy match would take both rubies for the first ingredient
+			items:     []data.Item{newItem(1, "PerfectRuby"), newItem(2, "PerfectRuby"), newItem(3, "PerfectTopaz")},
+			wantFound: true,
+			wantIDs:   []data.UnitID{1, 3, 2},
+		},
+		{
+			name:   "Grand charm reroll from defaults",
+			recipe: "",
+			items: []data.Item{
+				withQuality(newItem(1, "GrandCharm"), item.QualityMagic),
+				newItem(2, "PerfectSkull"),
+				newItem(3, "FlawlessSkull"),
+				newItem(4, "PerfectAmethyst"),
+				newItem(5, "PerfectDiamond"),
+			},
+			wantFound: true,
+			wantIDs:   []data.UnitID{1, 2, 4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Recipe
+			if tt.recipe == "" {
+				r = findRecipe(t, Defaults(), "Reroll GrandCharms")
+			} else {
+				recipes, err := Parse([]byte(tt.recipe), "test.yaml")
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+				r = recipes[0]
+			}
+
+			items, found := r.Match(tt.items, tt.opts)
+			if found != tt.wantFound {
+				t.Fatalf("Expected found to be %v, got %v", tt.wantFound, found)
+			}
+			if len(items) != len(tt.wantIDs) {
+				t.Fatalf("Expected %d items, got %d", len(tt.wantIDs), len(items))
+			}
+			for i, itm := range items {
+				if itm.UnitID != tt.wantIDs[i] {
+					t.Errorf("Expected item #%d to be %d, got %d", i, tt.wantIDs[i], itm.UnitID)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchCraftingBase(t *testing.T) {
+	r := findRecipe(t, Defaults(), "Caster Amulet")
+	if !r.IsCrafting() || r.Base.Sources[0] != SourceStash {
+		t.Fatalf("Expected caster amulet to be a crafting recipe taking the base from stash first")
+	}
+
+	items := []data.Item{
+		newItem(1, "RalRune"),
+		newItem(2, "PerfectAmethyst"),
+		withQuality(newItem(3, "Jewel"), item.QualityMagic),
+	}
+	if _, found := r.Match(items, MatchOptions{}); found {
+		t.Errorf("Expected no match without base")
+	}
+	if matched, found := r.MatchIngredients(items, MatchOptions{}); !found || len(matched) != 3 {
+		t.Errorf("Expected ingredients to match without base")
+	}
+
+	items = append(items, withQuality(newItem(4, "Amulet"), item.QualityRare), withQuality(newItem(5, "Amulet"), item.QualityMagic))
+	matched, found := r.Match(items, MatchOptions{})
+	if !found || len(matched) != 4 || matched[3].UnitID != 5 {
+		t.Errorf("Expected magic amulet to be used as base, got %+v", matched)
+	}
+}
+
+func TestUses(t *testing.T) {
+	r := findRecipe(t, Defaults(), "Reroll GrandCharms")
+	if !r.Uses("grandcharm") || !r.Uses("PerfectSkull") {
+		t.Errorf("Expected recipe to use grand charms and perfect skulls")
+	}
+	if r.Uses("FlawlessSkull") {
+		t.Errorf("Expected recipe to not use flawless skulls")
+	}
+	caster := findRecipe(t, Defaults(), "Caster Amulet")
+	if !caster.Uses("Jewel") {
+		t.Errorf("Expected crafting ingredient to be used by the recipe")
+	}
+	if caster.Uses("Amulet") {
+		t.Errorf("Expected crafting base to not be kept by the recipe")
+	}
+}
+
+func findRecipe(t *testing.T, recipes []Recipe, name string) Recipe {
+	for _, r := range recipes {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("Recipe %s not found", name)
+
+	return Recipe{}
+}
+
+func mustRules(t *testing.T, lines ...string) nip.Rules {
+	rules := make(nip.Rules, 0, len(lines))
+	for i, l := range lines {
+		r, err := nip.NewRule(l, "test.nip", i+1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+func newItem(id data.UnitID, name string) data.Item {
+	return data.Item{
+		ID:         item.GetIDByName(name),
+		UnitID:     id,
+		Name:       item.Name(name),
+		Quality:    item.QualityNormal,
+		Identified: true,
+	}
+}
+
+func withQuality(itm data.Item, q item.Quality) data.Item {
+	itm.Quality = q
+	return itm
+}
+
+func withEthereal(itm data.Item) data.Item {
+	itm.Ethereal = true
+	return itm
+}
+
+func withLevel(itm data.Item, lvl int) data.Item {
+	itm.LevelReq = lvl
+	return itm
+}
+
+func withStats(itm data.Item, stats ...stat.Data) data.Item {
+	itm.Stats = append(itm.Stats, stats...)
+	return itm
+}
+
+func unidentified(itm data.Item) data.Item {
+	itm.Identified = false
+	return itm
+}
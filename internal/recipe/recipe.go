@@ -0,0 +1,251 @@
+package recipe
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default_recipes.yaml
+var defaultRecipes []byte
+
+const (
+	// PickitAny uses the item no matter what the pickit rules say about it
+	PickitAny = ""
+	// PickitUnmatched only uses items that don't fully match any pickit rule, so keepers are never consumed
+	PickitUnmatched = "unmatched"
+	// PickitMatched only uses items fully matching a pickit rule
+	PickitMatched = "matched"
+)
+
+const (
+	SourceStash  = "stash"
+	SourceVendor = "vendor"
+	SourceGamble = "gamble"
+)
+
+type Recipe struct {
+	Name        string       `yaml:"name" json:"name"`
+	Ingredients []Ingredient `yaml:"ingredients" json:"ingredients"`
+	// Base is the item being crafted, it can be taken from the stash or bought, so it's not a regular ingredient
+	Base *Base `yaml:"base,omitempty" json:"base,omitempty"`
+}
+
+// Base defines the item used as base for crafting recipes. Bought and gambled items get an item level close to
+// the character level, and the crafted item level depends on both, so MinCharacterLevel can be used to target
+// higher affix levels.
+type Base struct {
+	Ingredient        `yaml:",inline"`
+	Sources           []string `yaml:"sources,omitempty" json:"sources,omitempty"`
+	MinCharacterLevel int      `yaml:"minCharacterLevel,omitempty" json:"minCharacterLevel,omitempty"`
+}
+
+// Ingredient describes which items can be used for one (or Count) slots of a recipe, every defined condition must
+// match for the item to be used.
+type Ingredient struct {
+	Name     string   `yaml:"name,omitempty" json:"name,omitempty"`
+	AnyOf    []string `yaml:"anyOf,omitempty" json:"anyOf,omitempty"`
+	Count    int      `yaml:"count,omitempty" json:"count,omitempty"`
+	Quality  []string `yaml:"quality,omitempty" json:"quality,omitempty"`
+	Ethereal *bool    `yaml:"ethereal,omitempty" json:"ethereal,omitempty"`
+	// MinLevel and MaxLevel are compared against the item level requirement, item level itself is not exposed
+	// by the memory reader.
+	MinLevel int  `yaml:"minLevel,omitempty" json:"minLevel,omitempty"`
+	MaxLevel int  `yaml:"maxLevel,omitempty" json:"maxLevel,omitempty"`
+	Sockets  *int `yaml:"sockets,omitempty" json:"sockets,omitempty"`
+	// Stats is a NIP stats expression, the same syntax used after the # on pickit rules, ex: [fcr] >= 10
+	Stats  string `yaml:"stats,omitempty" json:"stats,omitempty"`
+	Pickit string `yaml:"pickit,omitempty" json:"pickit,omitempty"`
+
+	statsRule *nip.Rule
+}
+
+type file struct {
+	Recipes []Recipe `yaml:"recipes"`
+}
+
+// Defaults returns the recipes shipped with Koolo
+func Defaults() []Recipe {
+	recipes, err := Parse(defaultRecipes, "default_recipes.yaml")
+	if err != nil {
+		panic(fmt.Errorf("error parsing default cube recipes: %w", err))
+	}
+
+	return recipes
+}
+
+// Load returns the default recipes merged with the ones defined in the given file, recipes in the file override
+// the default ones with the same name. Missing file is not considered an error. YAML and JSON files are supported.
+func Load(path string) ([]Recipe, error) {
+	recipes := Defaults()
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return recipes, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cube recipes file %s: %w", path, err)
+	}
+
+	custom, err := Parse(b, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Merge(recipes, custom), nil
+}
+
+// Parse reads and validates recipe definitions, since JSON is valid YAML both formats are accepted
+func Parse(b []byte, source string) ([]Recipe, error) {
+	f := file{}
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("error parsing cube recipes %s: %w", source, err)
+	}
+
+	names := make(map[string]bool)
+	for i := range f.Recipes {
+		r := &f.Recipes[i]
+		if r.Name == "" {
+			return nil, fmt.Errorf("%s: recipe #%d has no name", source, i+1)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("%s: recipe %s is defined more than once", source, r.Name)
+		}
+		names[r.Name] = true
+
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("%s: recipe %s: %w", source, r.Name, err)
+		}
+	}
+
+	return f.Recipes, nil
+}
+
+// Merge returns base recipes with the ones in overrides replacing the ones with the same name, new recipes are
+// appended at the end keeping their order.
+func Merge(base, overrides []Recipe) []Recipe {
+	merged := make([]Recipe, len(base))
+	copy(merged, base)
+
+	for _, o := range overrides {
+		replaced := false
+		for i, r := range merged {
+			if r.Name == o.Name {
+				merged[i] = o
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, o)
+		}
+	}
+
+	return merged
+}
+
+// Names returns the recipe names keeping their order
+func Names(recipes []Recipe) []string {
+	names := make([]string, 0, len(recipes))
+	for _, r := range recipes {
+		names = append(names, r.Name)
+	}
+
+	return names
+}
+
+func (r *Recipe) compile() error {
+	if len(r.Ingredients) == 0 {
+		return errors.New("no ingredients defined")
+	}
+
+	for i := range r.Ingredients {
+		if err := r.Ingredients[i].compile(r.Name, i+1); err != nil {
+			return fmt.Errorf("ingredient #%d %w", i+1, err)
+		}
+	}
+
+	if r.Base != nil {
+		if err := r.Base.compile(r.Name, 0); err != nil {
+			return fmt.Errorf("base %w", err)
+		}
+		if r.Base.Count != 1 {
+			return errors.New("base count must be 1")
+		}
+		if len(r.Base.Sources) == 0 {
+			r.Base.Sources = []string{SourceStash, SourceGamble}
+		}
+		for _, src := range r.Base.Sources {
+			switch src {
+			case SourceStash, SourceVendor, SourceGamble:
+			default:
+				return fmt.Errorf("base has unknown source %s", src)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ing *Ingredient) compile(recipeName string, line int) error {
+	if ing.Name == "" && len(ing.AnyOf) == 0 {
+		return errors.New("requires name or anyOf")
+	}
+	if ing.Count == 0 {
+		ing.Count = 1
+	}
+	if ing.Count < 0 {
+		return errors.New("has negative count")
+	}
+	for _, q := range ing.Quality {
+		if _, found := qualities[strings.ToLower(q)]; !found {
+			return fmt.Errorf("has unknown quality %s", q)
+		}
+	}
+	switch ing.Pickit {
+	case PickitAny, PickitMatched, PickitUnmatched:
+	default:
+		return fmt.Errorf("has unknown pickit mode %s", ing.Pickit)
+	}
+	if ing.Stats != "" {
+		// Stage1 always matches, we only want to evaluate the stats part of the rule
+		rule, err := nip.NewRule("[quality] > 0 # "+ing.Stats, recipeName, line)
+		if err != nil {
+			return fmt.Errorf("has invalid stats expression: %w", err)
+		}
+		ing.statsRule = &rule
+	}
+
+	return nil
+}
+
+// Names returns all the item names accepted by the ingredient
+func (ing Ingredient) Names() []string {
+	if ing.Name == "" {
+		return ing.AnyOf
+	}
+
+	return append([]string{ing.Name}, ing.AnyOf...)
+}
+
+// IsCrafting returns true for recipes producing a new item from a base
+func (r Recipe) IsCrafting() bool {
+	return r.Base != nil
+}
+
+var qualities = map[string]item.Quality{
+	"lowquality": item.QualityLowQuality,
+	"normal":     item.QualityNormal,
+	"superior":   item.QualitySuperior,
+	"magic":      item.QualityMagic,
+	"set":        item.QualitySet,
+	"rare":       item.QualityRare,
+	"unique":     item.QualityUnique,
+	"crafted":    item.QualityCrafted,
+}
@@ -29,11 +29,12 @@ func (b *Bot) handleStartRequest(s *discordgo.Session, m *discordgo.MessageCreat
 				continue
 			}
 
-			// Attempt to start the specified supervisor
-			b.manager.Start(supervisor, false)
-
-			// Wait for the supervisor to start
-			time.Sleep(1 * time.Second)
+			// Attempt to start the specified supervisor, enabling it again if it was disabled after too many crashes
+			b.manager.Enable(supervisor)
+			if err := b.manager.Start(supervisor, false); err != nil {
+				s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Supervisor '%s' could not be started: %s", supervisor, err))
+				continue
+			}
 
 			// Send a confirmation message
 			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Supervisor '%s' has been started.", supervisor))
@@ -19,7 +19,7 @@ func (b *Bot) Handle(_ context.Context, e event.Event) error {
 			message := fmt.Sprintf("%s\nGame: %s\nPassword: %s", evt.Message(), evt.Name, evt.Password)
 			_, err := b.discordSession.ChannelMessageSend(b.channelID, message)
 			return err
-		case event.GameFinishedEvent, event.RunStartedEvent, event.RunFinishedEvent:
+		case event.GameFinishedEvent, event.RunStartedEvent, event.RunFinishedEvent, event.MercDiedEvent, event.MercRevivedEvent, event.MercEquippedEvent, event.SupervisorDisabledEvent:
 			_, err := b.discordSession.ChannelMessageSend(b.channelID, e.Message())
 			return err
 		default:
@@ -63,6 +63,10 @@ func (b *Bot) shouldPublish(e event.Event) bool {
 		return config.Koolo.Discord.EnableNewRunMessages
 	case event.RunFinishedEvent:
 		return config.Koolo.Discord.EnableRunFinishMessages
+	case event.MercDiedEvent, event.MercRevivedEvent, event.MercEquippedEvent:
+		return config.Koolo.Discord.EnableDiscordMercMessages
+	case event.SupervisorDisabledEvent:
+		return config.Koolo.Discord.EnableDiscordErrorMessages
 	default:
 		break
 	}
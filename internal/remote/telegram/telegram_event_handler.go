@@ -28,6 +28,11 @@ func (b *Bot) Handle(_ context.Context, e event.Event) error {
 		return err
 	}
 
+	// Telemetry events don't have any message to publish
+	if e.Message() == "" {
+		return nil
+	}
+
 	_, err := b.bot.Send(tgbotapi.NewMessage(b.chatID, e.Message()))
 
 	return err
@@ -0,0 +1,81 @@
+package run
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/corpse"
+	"github.com/hectorgimenez/koolo/internal/health"
+)
+
+// CorpseRecovery goes back to the area where the character died to pick up the corpse, equipped items are restored
+// when the corpse is recovered. Spare gear is not equipped from the stash, the character goes naked so the corpse is
+// only recovered when it's close. It's added by the supervisor before the remaining runs after a death, see corpse.Policy
+type CorpseRecovery struct {
+	ctx       *context.Status
+	deathArea area.ID
+}
+
+func NewCorpseRecovery(deathArea area.ID) *CorpseRecovery {
+	return &CorpseRecovery{
+		ctx:       context.Get(),
+		deathArea: deathArea,
+	}
+}
+
+func (c CorpseRecovery) Name() string {
+	return "corpse_recovery"
+}
+
+func (c CorpseRecovery) Run() error {
+	portalFound := false
+	for _, obj := range c.ctx.Data.Objects {
+		if obj.IsPortal() && obj.Owner == c.ctx.Data.PlayerUnit.Name && obj.PortalData.DestArea == c.deathArea {
+			portalFound = true
+		}
+	}
+
+	route := corpse.ChooseRoute(c.deathArea, portalFound)
+	c.ctx.Logger.Info("Going back to recover the corpse", slog.String("area", c.deathArea.Area().Name), slog.String("route", route.String()))
+
+	var err error
+	switch route {
+	case corpse.RoutePortal:
+		err = action.UsePortalFrom(c.ctx.Data.PlayerUnit.Name)
+	case corpse.RouteWaypoint:
+		err = action.WayPoint(c.deathArea)
+	default:
+		return fmt.Errorf("%w: no way back to %s", health.ErrCorpseLost, c.deathArea.Area().Name)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", health.ErrCorpseLost, err)
+	}
+
+	c.ctx.RefreshGameData()
+	if !c.ctx.Data.Corpse.Found {
+		return fmt.Errorf("%w: corpse not found in %s", health.ErrCorpseLost, c.deathArea.Area().Name)
+	}
+
+	if !c.ctx.CharacterCfg.DeathRecovery.Safe(c.ctx.Data.Corpse.Position, c.ctx.Data.Monsters.Enemies()) {
+		return fmt.Errorf("%w: too many monsters close to the corpse", health.ErrCorpseLost)
+	}
+
+	// The gear is on the corpse, a naked character only goes for it when it's close
+	geared := corpse.Geared(c.ctx.Data.Inventory.ByLocation(item.LocationEquipped))
+	if !c.ctx.CharacterCfg.DeathRecovery.Reachable(geared, c.ctx.PathFinder.DistanceFromMe(c.ctx.Data.Corpse.Position)) {
+		return fmt.Errorf("%w: no gear equipped and the corpse is too far", health.ErrCorpseLost)
+	}
+
+	if err = action.MoveToCoords(c.ctx.Data.Corpse.Position); err != nil {
+		return fmt.Errorf("%w: %w", health.ErrCorpseLost, err)
+	}
+	if err = action.RecoverCorpse(); err != nil {
+		return fmt.Errorf("%w: %w", health.ErrCorpseLost, err)
+	}
+
+	return action.ReturnTown()
+}
@@ -0,0 +1,180 @@
+package run
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/area"
+	"github.com/hectorgimenez/d2go/pkg/data/item"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/action/step"
+	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/context"
+	"github.com/hectorgimenez/koolo/internal/town"
+	"github.com/hectorgimenez/koolo/internal/utils"
+	"github.com/lxn/win"
+)
+
+const vendorTabs = 4
+
+type shoppingVendor struct {
+	npc  npc.ID
+	town area.ID
+}
+
+var shoppingVendors = map[string]shoppingVendor{
+	"akara":   {npc: npc.Akara, town: area.RogueEncampment},
+	"charsi":  {npc: npc.Charsi, town: area.RogueEncampment},
+	"fara":    {npc: npc.Fara, town: area.LutGholein},
+	"drognan": {npc: npc.Drognan, town: area.LutGholein},
+	"elzix":   {npc: npc.Elzix, town: area.LutGholein},
+	"ormus":   {npc: npc.Ormus, town: area.KurastDocks},
+	"hratli":  {npc: npc.Hratli, town: area.KurastDocks},
+	"asheara": {npc: npc.Asheara, town: area.KurastDocks},
+	"jamella": {npc: npc.Jamella, town: area.ThePandemoniumFortress},
+	"halbu":   {npc: npc.Halbu, town: area.ThePandemoniumFortress},
+	"larzuk":  {npc: npc.Larzuk, town: area.Harrogath},
+	"malah":   {npc: npc.Malah, town: area.Harrogath},
+	"anya":    {npc: npc.Drehya, town: area.Harrogath},
+}
+
+// Shopping buys the vendor items matching the shopping.nip rules. Vendors get a new stock every game, so the run
+// shops once per game by default, extra passes in the same game are done when game.shopping.refreshes is set.
+type Shopping struct {
+	ctx *context.Status
+}
+
+func NewShopping() *Shopping {
+	return &Shopping{
+		ctx: context.Get(),
+	}
+}
+
+func (s Shopping) Name() string {
+	return string(config.ShoppingRun)
+}
+
+func (s Shopping) Run() error {
+	if len(s.ctx.CharacterCfg.Runtime.ShoppingRules) == 0 {
+		return errors.New("shopping run requires rules defined in shopping.nip file")
+	}
+
+	cfg := s.ctx.CharacterCfg.Game.Shopping
+	for pass := 0; pass <= cfg.Refreshes; pass++ {
+		if pass > 0 {
+			if err := s.refreshVendors(); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range cfg.Vendors {
+			vendor, found := shoppingVendors[strings.ToLower(name)]
+			if !found {
+				s.ctx.Logger.Warn("Unknown shopping vendor, skipping", slog.String("vendor", name))
+				continue
+			}
+
+			if s.belowGoldFloor() {
+				s.ctx.Logger.Info("Gold below shopping floor, stopping", slog.Int("gold", s.ctx.Data.PlayerUnit.TotalPlayerGold()))
+				return action.Stash(false)
+			}
+
+			if s.ctx.Data.PlayerUnit.Area != vendor.town {
+				if err := action.WayPoint(vendor.town); err != nil {
+					return err
+				}
+			}
+
+			if err := s.shopAt(vendor); err != nil {
+				s.ctx.Logger.Warn("Error shopping at vendor", slog.String("vendor", name), slog.Any("error", err))
+			}
+		}
+
+		// Stash after every pass, otherwise the inventory could be full for the next one
+		if err := action.Stash(false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s Shopping) shopAt(vendor shoppingVendor) error {
+	// Same position fixes used when visiting these vendors for other purposes
+	switch vendor.npc {
+	case npc.Drehya:
+		_ = action.MoveToCoords(data.Position{X: 5107, Y: 5119})
+	case npc.Hratli:
+		_ = action.MoveToCoords(data.Position{X: 5224, Y: 5045})
+	}
+
+	if err := action.InteractNPC(vendor.npc); err != nil {
+		return err
+	}
+
+	// Jamella and Halbu trade button is the first one
+	if vendor.npc == npc.Jamella || vendor.npc == npc.Halbu {
+		s.ctx.HID.KeySequence(win.VK_HOME, win.VK_RETURN)
+	} else {
+		s.ctx.HID.KeySequence(win.VK_HOME, win.VK_DOWN, win.VK_RETURN)
+	}
+	utils.Sleep(500)
+	s.ctx.RefreshGameData()
+
+	if !s.ctx.Data.OpenMenus.NPCShop {
+		return errors.New("failed opening vendor window")
+	}
+
+	for tab := 1; tab <= vendorTabs; tab++ {
+		action.SwitchStashTab(tab)
+		s.ctx.RefreshGameData()
+
+		for _, itm := range s.ctx.Data.Inventory.ByLocation(item.LocationVendor) {
+			// Only items in the current tab can be clicked
+			if itm.Location.Page != tab-1 {
+				continue
+			}
+
+			rule, res := s.ctx.CharacterCfg.Runtime.ShoppingRules.EvaluateAll(itm)
+			if res != nip.RuleResultFullMatch {
+				continue
+			}
+
+			if s.belowGoldFloor() {
+				return step.CloseAllMenus()
+			}
+
+			s.ctx.Logger.Info(fmt.Sprintf("Buying %s [%s] from vendor", itm.Desc().Name, itm.Quality.ToString()),
+				slog.String("nipFile", fmt.Sprintf("%s:%d", rule.Filename, rule.LineNumber)),
+				slog.String("rawRule", rule.RawLine),
+			)
+			town.BuyItem(itm, 1)
+			s.ctx.RefreshGameData()
+		}
+	}
+
+	return step.CloseAllMenus()
+}
+
+// refreshVendors travels to a different act and back, vendors stock is generated again when coming back. It's only
+// used for the extra passes, the stock is refreshed between games anyway
+func (s Shopping) refreshVendors() error {
+	current := s.ctx.Data.PlayerUnit.Area
+	refreshTown := area.RogueEncampment
+	if current == area.RogueEncampment {
+		refreshTown = area.LutGholein
+	}
+
+	s.ctx.Logger.Debug("Refreshing vendors stock", slog.String("via", refreshTown.Area().Name))
+
+	return action.WayPoint(refreshTown)
+}
+
+func (s Shopping) belowGoldFloor() bool {
+	return s.ctx.Data.PlayerUnit.TotalPlayerGold() < s.ctx.CharacterCfg.Game.Shopping.GoldFloor
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/d2go/pkg/data/area"
 	"github.com/hectorgimenez/koolo/internal/action"
+	"github.com/hectorgimenez/koolo/internal/action/step"
 	"github.com/hectorgimenez/koolo/internal/character"
 	"github.com/hectorgimenez/koolo/internal/config"
 	"github.com/hectorgimenez/koolo/internal/context"
@@ -29,9 +30,9 @@ func (t *Travincal) Run() error {
 	}()
 
 	// Check if the character is a Berserker and swap to combat gear
-	if berserker, ok := t.ctx.Char.(*character.Berserker); ok {
+	if _, ok := t.ctx.Char.(*character.Berserker); ok {
 		if t.ctx.CharacterCfg.Character.BerserkerBarb.FindItemSwitch {
-			berserker.SwapToSlot(0) // Swap to combat gear (lowest Gold Find)
+			step.SwapToMainWeapon()
 		}
 	}
 
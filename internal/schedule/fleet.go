@@ -0,0 +1,165 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// FleetPolicy applies machine wide limits on top of the supervisor schedules
+type FleetPolicy struct {
+	// MaxConcurrent is the max amount of supervisors running at the same time, 0 means no limit
+	MaxConcurrent int
+	// RotateAfter is the continuous play time after which a supervisor leaves its slot to a waiting one, 0 disables rotation
+	RotateAfter time.Duration
+	// StartDelay is the min time between two supervisor starts, 0 starts them all at once
+	StartDelay time.Duration
+}
+
+// Candidate is the state of a supervisor for the fleet planning
+type Candidate struct {
+	Name string
+	// Higher priorities take the free slots first and can preempt running supervisors with lower priority
+	Priority int
+	// Wants is true when the supervisor schedule wants it running
+	Wants   bool
+	Running bool
+	// Pinned supervisors are not managed by the scheduler, they take a slot but are never stopped
+	Pinned bool
+	// Since is the time the supervisor was started
+	Since time.Time
+	// Played is the play time of the day, supervisors with less play time go first
+	Played time.Duration
+}
+
+// Change is a supervisor start, stop or wait with the reason behind it
+type Change struct {
+	Name   string
+	Reason string
+}
+
+type FleetPlan struct {
+	Start []Change
+	Stop  []Change
+	// Waiting supervisors want to run but there is no free slot or their start is delayed
+	Waiting []Change
+}
+
+// Plan decides which supervisors should be started or stopped to honor the policy. Supervisors not wanted by their
+// schedule are expected to be stopped by the caller, running ones still take a slot until they are.
+func (p FleetPolicy) Plan(now, lastStart time.Time, candidates []Candidate) FleetPlan {
+	plan := FleetPlan{}
+
+	running := make([]Candidate, 0)
+	waiting := make([]Candidate, 0)
+	for _, c := range candidates {
+		switch {
+		case c.Running:
+			running = append(running, c)
+		case c.Wants && !c.Pinned:
+			waiting = append(waiting, c)
+		}
+	}
+	sort.Slice(waiting, func(i, j int) bool {
+		return before(waiting[i], waiting[j])
+	})
+
+	slots := p.MaxConcurrent
+	if slots <= 0 {
+		slots = len(candidates)
+	}
+
+	stop := func(c Candidate, reason string) {
+		plan.Stop = append(plan.Stop, Change{Name: c.Name, Reason: reason})
+		for i := range running {
+			if running[i].Name == c.Name {
+				running = append(running[:i], running[i+1:]...)
+				return
+			}
+		}
+	}
+
+	for len(running) > slots {
+		worst, found := p.worst(running)
+		if !found {
+			break
+		}
+		stop(worst, fmt.Sprintf("more than %d supervisors running", slots))
+	}
+
+	// Higher priorities take the slot of the lowest priority running supervisor
+	for _, w := range waiting {
+		if len(running) < slots {
+			break
+		}
+		worst, found := p.worst(running)
+		if !found || worst.Priority >= w.Priority {
+			break
+		}
+		stop(worst, fmt.Sprintf("preempted by %s, which has a higher priority", w.Name))
+	}
+
+	// Rotation frees a single slot per plan, the longest running supervisor leaves it to the first one waiting
+	if p.RotateAfter > 0 && len(waiting) > 0 && len(running) >= slots {
+		var longest *Candidate
+		for i, r := range running {
+			if r.Pinned || now.Sub(r.Since) < p.RotateAfter {
+				continue
+			}
+			if longest == nil || r.Since.Before(longest.Since) {
+				longest = &running[i]
+			}
+		}
+		if longest != nil && waiting[0].Priority >= longest.Priority {
+			stop(*longest, fmt.Sprintf("rotated out after %s to let %s play", p.RotateAfter, waiting[0].Name))
+		}
+	}
+
+	free := slots - len(running)
+	for _, w := range waiting {
+		switch {
+		case free <= 0:
+			plan.Waiting = append(plan.Waiting, Change{Name: w.Name, Reason: "waiting for a free slot"})
+		case p.StartDelay > 0 && !lastStart.IsZero() && now.Sub(lastStart) < p.StartDelay:
+			plan.Waiting = append(plan.Waiting, Change{Name: w.Name, Reason: fmt.Sprintf("staggered start after %s", lastStart.Add(p.StartDelay).Format("15:04:05"))})
+		default:
+			plan.Start = append(plan.Start, Change{Name: w.Name, Reason: "free slot"})
+			free--
+			if p.StartDelay > 0 {
+				lastStart = now
+			}
+		}
+	}
+
+	return plan
+}
+
+// worst returns the running supervisor that should leave its slot first, pinned ones are never returned
+func (p FleetPolicy) worst(running []Candidate) (Candidate, bool) {
+	var worst *Candidate
+	for i, r := range running {
+		if r.Pinned {
+			continue
+		}
+		if worst == nil || before(*worst, r) {
+			worst = &running[i]
+		}
+	}
+	if worst == nil {
+		return Candidate{}, false
+	}
+
+	return *worst, true
+}
+
+// before returns true when a goes before b: higher priority, less play time and name as tie breaker
+func before(a, b Candidate) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if a.Played != b.Played {
+		return a.Played < b.Played
+	}
+
+	return a.Name < b.Name
+}
@@ -0,0 +1,171 @@
+package schedule
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func names(changes []Change) []string {
+	n := make([]string, 0, len(changes))
+	for _, c := range changes {
+		n = append(n, c.Name)
+	}
+
+	return n
+}
+
+func TestPlan(t *testing.T) {
+	now := at(12, 0)
+
+	tests := []struct {
+		name       string
+		policy     FleetPolicy
+		lastStart  time.Time
+		candidates []Candidate
+		start      []string
+		stop       []string
+		waiting    []string
+	}{
+		{
+			name: "no limits",
+			candidates: []Candidate{
+				{Name: "a", Wants: true},
+				{Name: "b", Wants: true},
+			},
+			start: []string{"a", "b"},
+		},
+		{
+			name:   "max concurrent by priority",
+			policy: FleetPolicy{MaxConcurrent: 2},
+			candidates: []Candidate{
+				{Name: "a", Wants: true},
+				{Name: "b", Wants: true, Priority: 2},
+				{Name: "c", Wants: true, Priority: 1},
+			},
+			start:   []string{"b", "c"},
+			waiting: []string{"a"},
+		},
+		{
+			name:   "less play time goes first",
+			policy: FleetPolicy{MaxConcurrent: 1},
+			candidates: []Candidate{
+				{Name: "a", Wants: true, Played: time.Hour},
+				{Name: "b", Wants: true, Played: 10 * time.Minute},
+			},
+			start:   []string{"b"},
+			waiting: []string{"a"},
+		},
+		{
+			name:   "pinned supervisors take a slot",
+			policy: FleetPolicy{MaxConcurrent: 2},
+			candidates: []Candidate{
+				{Name: "manual", Running: true, Pinned: true},
+				{Name: "a", Wants: true, Running: true, Since: at(11, 0)},
+				{Name: "b", Wants: true},
+			},
+			waiting: []string{"b"},
+		},
+		{
+			name:   "over the limit",
+			policy: FleetPolicy{MaxConcurrent: 1},
+			candidates: []Candidate{
+				{Name: "manual", Running: true, Pinned: true},
+				{Name: "a", Wants: true, Running: true, Priority: 5},
+			},
+			stop: []string{"a"},
+		},
+		{
+			name:   "higher priority preempts",
+			policy: FleetPolicy{MaxConcurrent: 1},
+			candidates: []Candidate{
+				{Name: "a", Wants: true, Running: true, Since: at(11, 50)},
+				{Name: "b", Wants: true, Priority: 1},
+			},
+			start: []string{"b"},
+			stop:  []string{"a"},
+		},
+		{
+			name:   "same priority does not preempt",
+			policy: FleetPolicy{MaxConcurrent: 1},
+			candidates: []Candidate{
+				{Name: "a", Wants: true, Running: true, Since: at(11, 50), Played: time.Hour},
+				{Name: "b", Wants: true},
+			},
+			waiting: []string{"b"},
+		},
+		{
+			name:   "rotation",
+			policy: FleetPolicy{MaxConcurrent: 2, RotateAfter: time.Hour},
+			candidates: []Candidate{
+				{Name: "a", Wants: true, Running: true, Since: at(10, 0)},
+				{Name: "b", Wants: true, Running: true, Since: at(10, 30)},
+				{Name: "c", Wants: true},
+				{Name: "d", Wants: true},
+			},
+			start:   []string{"c"},
+			stop:    []string{"a"},
+			waiting: []string{"d"},
+		},
+		{
+			name:   "rotation does not let lower priorities in",
+			policy: FleetPolicy{MaxConcurrent: 1, RotateAfter: time.Hour},
+			candidates: []Candidate{
+				{Name: "a", Wants: true, Running: true, Since: at(10, 0), Priority: 1},
+				{Name: "b", Wants: true},
+			},
+			waiting: []string{"b"},
+		},
+		{
+			name:   "rotation waits for the play time",
+			policy: FleetPolicy{MaxConcurrent: 1, RotateAfter: time.Hour},
+			candidates: []Candidate{
+				{Name: "a", Wants: true, Running: true, Since: at(11, 30)},
+				{Name: "b", Wants: true},
+			},
+			waiting: []string{"b"},
+		},
+		{
+			name:      "staggered starts",
+			policy:    FleetPolicy{StartDelay: time.Minute},
+			lastStart: at(11, 0),
+			candidates: []Candidate{
+				{Name: "a", Wants: true},
+				{Name: "b", Wants: true},
+			},
+			start:   []string{"a"},
+			waiting: []string{"b"},
+		},
+		{
+			name:      "start delay not elapsed",
+			policy:    FleetPolicy{StartDelay: time.Minute},
+			lastStart: now.Add(-30 * time.Second),
+			candidates: []Candidate{
+				{Name: "a", Wants: true},
+			},
+			waiting: []string{"a"},
+		},
+		{
+			name: "supervisors not wanted are left to the caller",
+			candidates: []Candidate{
+				{Name: "a", Running: true},
+				{Name: "b"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := tc.policy.Plan(now, tc.lastStart, tc.candidates)
+			if got := names(plan.Start); !slices.Equal(got, tc.start) && len(got)+len(tc.start) > 0 {
+				t.Errorf("expected start %v, got %v", tc.start, got)
+			}
+			if got := names(plan.Stop); !slices.Equal(got, tc.stop) && len(got)+len(tc.stop) > 0 {
+				t.Errorf("expected stop %v, got %v", tc.stop, got)
+			}
+			if got := names(plan.Waiting); !slices.Equal(got, tc.waiting) && len(got)+len(tc.waiting) > 0 {
+				t.Errorf("expected waiting %v, got %v", tc.waiting, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package schedule
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// DateLayout is the format used for the date exceptions
+const DateLayout = "2006-01-02"
+
+const noLimit = time.Duration(math.MaxInt64)
+
+type Action int
+
+const (
+	None Action = iota
+	Start
+	Stop
+)
+
+func (a Action) String() string {
+	switch a {
+	case Start:
+		return "start"
+	case Stop:
+		return "stop"
+	}
+
+	return "none"
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Range is a time of the day range, defined as offsets from midnight
+type Range struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Window is an absolute play window
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Breaks defines a pause of a random length every random amount of continuous play, disabled when empty
+type Breaks struct {
+	EveryMin  time.Duration
+	EveryMax  time.Duration
+	LengthMin time.Duration
+	LengthMax time.Duration
+}
+
+func (b Breaks) enabled() bool {
+	return b.EveryMin > 0 && b.LengthMin > 0
+}
+
+type Rules struct {
+	// Weekly ranges, indexed by time.Weekday
+	Weekly [7][]Range
+	// Exceptions replace the weekly ranges for a date (see DateLayout), no ranges means a day off
+	Exceptions map[string][]Range
+	// Windows are one-off play windows, added on top of the weekly ones
+	Windows []Window
+	// Budgets is the max play time per day, indexed by time.Weekday. 0 means no limit
+	Budgets [7]time.Duration
+	// Jitter is the max random delay for starts and advance for stops, windows are never extended
+	Jitter time.Duration
+	Breaks Breaks
+}
+
+// Decision is the result of a tick, Reason is the rule that decided the current state
+type Decision struct {
+	Action Action
+	// Run is true when the supervisor should be running
+	Run    bool
+	Reason string
+}
+
+// Transition is the next expected start or stop
+type Transition struct {
+	At     time.Time
+	Action Action
+	Reason string
+}
+
+// Engine decides when a supervisor should be running. It's not thread safe.
+type Engine struct {
+	rules Rules
+	now   func() time.Time
+	rnd   *rand.Rand
+
+	// Jitter is decided once per window, keyed by the configured window start
+	offsets    map[time.Time][2]time.Duration
+	played     map[string]time.Duration
+	running    bool
+	lastTick   time.Time
+	breakAt    time.Time
+	breakUntil time.Time
+}
+
+func New(rules Rules, now func() time.Time, rnd *rand.Rand) *Engine {
+	return &Engine{
+		rules:   rules,
+		now:     now,
+		rnd:     rnd,
+		offsets: make(map[time.Time][2]time.Duration),
+		played:  make(map[string]time.Duration),
+	}
+}
+
+// Rules returns the rules used by the engine
+func (e *Engine) Rules() Rules {
+	return e.rules
+}
+
+// SetRules replaces the rules keeping the play time and breaks, jitter is decided again
+func (e *Engine) SetRules(rules Rules) {
+	e.rules = rules
+	e.offsets = make(map[time.Time][2]time.Duration)
+}
+
+// Tick should be called periodically with the current supervisor state, play time is accounted between ticks when
+// the supervisor was running on any of them
+func (e *Engine) Tick(running bool) Decision {
+	now := e.now()
+	if (e.running || running) && !e.lastTick.IsZero() {
+		e.played[now.Format(DateLayout)] += now.Sub(e.lastTick)
+	}
+	e.lastTick = now
+	e.running = running
+
+	// Time to the next break is counted from the supervisor start
+	if !running {
+		e.breakAt = time.Time{}
+	}
+
+	run, reason := e.desired(now)
+	switch {
+	case run && !running:
+		return Decision{Action: Start, Run: true, Reason: reason}
+	case !run && running:
+		return Decision{Action: Stop, Reason: reason}
+	}
+
+	return Decision{Action: None, Run: run, Reason: reason}
+}
+
+// Played returns the play time accounted for the given date
+func (e *Engine) Played(day time.Time) time.Duration {
+	return e.played[day.Format(DateLayout)]
+}
+
+// Next returns the next expected transition, false if nothing is scheduled in the following week. Breaks and budgets
+// not started yet are unknown, so the transition can happen before the returned one.
+func (e *Engine) Next() (Transition, bool) {
+	now := e.now()
+	windows := e.windows(now, 7)
+
+	if e.running {
+		w, found := containing(windows, now)
+		if !found {
+			return Transition{At: now, Action: Stop, Reason: "outside of the play windows"}, true
+		}
+
+		t := Transition{At: w.End, Action: Stop, Reason: "end of the play window"}
+		if left := e.budgetLeft(now); left != noLimit && now.Add(left).Before(t.At) {
+			t = Transition{At: now.Add(max(left, 0)), Action: Stop, Reason: "daily play time budget"}
+		}
+		if !e.breakAt.IsZero() && e.breakAt.Before(t.At) {
+			t = Transition{At: e.breakAt, Action: Stop, Reason: "break"}
+		}
+
+		return t, true
+	}
+
+	for _, w := range windows {
+		at, reason := w.Start, "start of the play window"
+		if at.Before(now) {
+			at = now
+		}
+		if at.Before(e.breakUntil) {
+			at, reason = e.breakUntil, "end of the break"
+		}
+		if !at.Before(w.End) {
+			continue
+		}
+		if at.Format(DateLayout) == now.Format(DateLayout) && e.budgetLeft(now) <= 0 {
+			continue
+		}
+
+		return Transition{At: at, Action: Start, Reason: reason}, true
+	}
+
+	return Transition{}, false
+}
+
+func (e *Engine) desired(now time.Time) (bool, string) {
+	w, found := containing(e.windows(now, 1), now)
+	if !found {
+		return false, "outside of the play windows"
+	}
+
+	if e.budgetLeft(now) <= 0 {
+		return false, "daily play time budget reached"
+	}
+
+	if now.Before(e.breakUntil) {
+		return false, fmt.Sprintf("on a break until %s", e.breakUntil.Format("15:04"))
+	}
+
+	if e.rules.Breaks.enabled() && e.running {
+		if e.breakAt.IsZero() {
+			e.breakAt = now.Add(e.between(e.rules.Breaks.EveryMin, e.rules.Breaks.EveryMax))
+		}
+		if !now.Before(e.breakAt) {
+			e.breakAt = time.Time{}
+			e.breakUntil = now.Add(e.between(e.rules.Breaks.LengthMin, e.rules.Breaks.LengthMax))
+			return false, fmt.Sprintf("taking a break until %s", e.breakUntil.Format("15:04"))
+		}
+	}
+
+	return true, fmt.Sprintf("play window until %s", w.End.Format("15:04"))
+}
+
+func (e *Engine) budgetLeft(now time.Time) time.Duration {
+	budget := e.rules.Budgets[now.Weekday()]
+	if budget == 0 {
+		return noLimit
+	}
+
+	return budget - e.played[now.Format(DateLayout)]
+}
+
+// windows returns the play windows from now to the given amount of days, jitter applied and overlapping ones merged
+func (e *Engine) windows(now time.Time, days int) []Window {
+	candidates := make([]Window, 0)
+	for i := 0; i <= days; i++ {
+		candidates = append(candidates, e.dayWindows(now.AddDate(0, 0, i))...)
+	}
+	candidates = append(candidates, e.rules.Windows...)
+
+	for start := range e.offsets {
+		if start.Before(now.AddDate(0, 0, -2)) {
+			delete(e.offsets, start)
+		}
+	}
+
+	windows := make([]Window, 0, len(candidates))
+	for _, w := range candidates {
+		w = e.jitter(w)
+		if w.End.After(now) && w.End.After(w.Start) {
+			windows = append(windows, w)
+		}
+	}
+
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].Start.Before(windows[j].Start)
+	})
+
+	merged := make([]Window, 0, len(windows))
+	for _, w := range windows {
+		if len(merged) > 0 && !w.Start.After(merged[len(merged)-1].End) {
+			if w.End.After(merged[len(merged)-1].End) {
+				merged[len(merged)-1].End = w.End
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+
+	return merged
+}
+
+func (e *Engine) dayWindows(day time.Time) []Window {
+	y, m, d := day.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+
+	ranges, found := e.rules.Exceptions[midnight.Format(DateLayout)]
+	if !found {
+		ranges = e.rules.Weekly[midnight.Weekday()]
+	}
+
+	windows := make([]Window, 0, len(ranges))
+	for _, r := range ranges {
+		windows = append(windows, Window{Start: midnight.Add(r.Start), End: midnight.Add(r.End)})
+	}
+
+	return windows
+}
+
+// jitter delays the start and advances the end of the window, by a quarter of its length at most
+func (e *Engine) jitter(w Window) Window {
+	if e.rules.Jitter <= 0 {
+		return w
+	}
+
+	offsets, found := e.offsets[w.Start]
+	if !found {
+		limit := min(e.rules.Jitter, w.End.Sub(w.Start)/4)
+		offsets = [2]time.Duration{e.between(0, limit), e.between(0, limit)}
+		e.offsets[w.Start] = offsets
+	}
+
+	return Window{Start: w.Start.Add(offsets[0]), End: w.End.Add(-offsets[1])}
+}
+
+func (e *Engine) between(minimum, maximum time.Duration) time.Duration {
+	if maximum <= minimum {
+		return minimum
+	}
+
+	return minimum + time.Duration(e.rnd.Int63n(int64(maximum-minimum)+1))
+}
+
+func containing(windows []Window, t time.Time) (Window, bool) {
+	for _, w := range windows {
+		if !t.Before(w.Start) && t.Before(w.End) {
+			return w, true
+		}
+	}
+
+	return Window{}, false
+}
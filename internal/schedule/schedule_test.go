@@ -0,0 +1,281 @@
+package schedule
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Monday
+var day = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+func at(hour, minute int) time.Time {
+	return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func hours(start, end float64) Range {
+	return Range{Start: time.Duration(start * float64(time.Hour)), End: time.Duration(end * float64(time.Hour))}
+}
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+type tick struct {
+	at      time.Time
+	running bool
+	action  Action
+}
+
+func TestTick(t *testing.T) {
+	weekly := [7][]Range{}
+	weekly[time.Monday] = []Range{hours(10, 12), hours(18, 22)}
+
+	tests := []struct {
+		name  string
+		rules Rules
+		ticks []tick
+	}{
+		{
+			name:  "weekly ranges",
+			rules: Rules{Weekly: weekly},
+			ticks: []tick{
+				{at: at(9, 59), action: None},
+				{at: at(10, 0), action: Start},
+				{at: at(11, 0), running: true, action: None},
+				{at: at(12, 0), running: true, action: Stop},
+				{at: at(15, 0), action: None},
+				{at: at(18, 30), action: Start},
+			},
+		},
+		{
+			name:  "day off exception",
+			rules: Rules{Weekly: weekly, Exceptions: map[string][]Range{"2026-10-19": nil}},
+			ticks: []tick{
+				{at: at(10, 30), action: None},
+				{at: at(19, 0), running: true, action: Stop},
+			},
+		},
+		{
+			name:  "exception replaces weekly ranges",
+			rules: Rules{Weekly: weekly, Exceptions: map[string][]Range{"2026-10-19": {hours(14, 16)}}},
+			ticks: []tick{
+				{at: at(10, 30), action: None},
+				{at: at(14, 0), action: Start},
+				{at: at(16, 0), running: true, action: Stop},
+			},
+		},
+		{
+			name:  "one-off window",
+			rules: Rules{Weekly: weekly, Windows: []Window{{Start: at(13, 0), End: at(15, 0)}}},
+			ticks: []tick{
+				{at: at(13, 0), action: Start},
+				{at: at(15, 0), running: true, action: Stop},
+			},
+		},
+		{
+			name:  "overlapping one-off window extends the weekly range",
+			rules: Rules{Weekly: weekly, Windows: []Window{{Start: at(11, 0), End: at(13, 0)}}},
+			ticks: []tick{
+				{at: at(10, 0), action: Start},
+				{at: at(12, 30), running: true, action: None},
+				{at: at(13, 0), running: true, action: Stop},
+			},
+		},
+		{
+			name:  "daily budget",
+			rules: Rules{Weekly: weekly, Budgets: [7]time.Duration{time.Monday: 90 * time.Minute}},
+			ticks: []tick{
+				{at: at(10, 0), action: Start},
+				{at: at(11, 0), running: true, action: None},
+				{at: at(11, 30), running: true, action: Stop},
+				{at: at(18, 0), action: None},
+			},
+		},
+		{
+			name:  "budget is not consumed while waiting to start",
+			rules: Rules{Weekly: weekly, Budgets: [7]time.Duration{time.Monday: 30 * time.Minute}},
+			ticks: []tick{
+				{at: at(10, 0), action: Start},
+				{at: at(11, 0), action: Start},
+				{at: at(11, 20), running: true, action: None},
+				{at: at(11, 31), running: true, action: Stop},
+			},
+		},
+		{
+			name:  "breaks",
+			rules: Rules{Weekly: weekly, Breaks: Breaks{EveryMin: time.Hour, EveryMax: time.Hour, LengthMin: 15 * time.Minute, LengthMax: 15 * time.Minute}},
+			ticks: []tick{
+				{at: at(18, 0), action: Start},
+				{at: at(18, 1), running: true, action: None},
+				{at: at(19, 0), running: true, action: None},
+				{at: at(19, 1), running: true, action: Stop},
+				{at: at(19, 10), action: None},
+				{at: at(19, 16), action: Start},
+				{at: at(19, 17), running: true, action: None},
+				{at: at(20, 17), running: true, action: Stop},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &clock{}
+			e := New(tc.rules, c.now, rand.New(rand.NewSource(1)))
+			for _, tk := range tc.ticks {
+				c.t = tk.at
+				if d := e.Tick(tk.running); d.Action != tk.action {
+					t.Fatalf("at %s expected %s, got %s (%s)", tk.at.Format("15:04"), tk.action, d.Action, d.Reason)
+				}
+			}
+		})
+	}
+}
+
+func TestJitter(t *testing.T) {
+	weekly := [7][]Range{}
+	weekly[time.Monday] = []Range{hours(10, 12)}
+
+	for seed := int64(0); seed < 20; seed++ {
+		c := &clock{t: at(9, 0)}
+		e := New(Rules{Weekly: weekly, Jitter: 10 * time.Minute}, c.now, rand.New(rand.NewSource(seed)))
+
+		next, found := e.Next()
+		if !found || next.Action != Start {
+			t.Fatalf("expected a start transition, got %+v", next)
+		}
+		if next.At.Before(at(10, 0)) || next.At.After(at(10, 10)) {
+			t.Fatalf("start %s out of the jitter range", next.At.Format("15:04:05"))
+		}
+
+		// Jitter is decided once, ticks must agree with the preview
+		c.t = next.At.Add(-time.Second)
+		if d := e.Tick(false); d.Action != None {
+			t.Fatalf("expected no action before the jittered start, got %s", d.Action)
+		}
+		c.t = next.At
+		if d := e.Tick(false); d.Action != Start {
+			t.Fatalf("expected start at the jittered start, got %s", d.Action)
+		}
+		e.Tick(true)
+
+		stop, _ := e.Next()
+		if stop.Action != Stop || stop.At.After(at(12, 0)) || stop.At.Before(at(11, 50)) {
+			t.Fatalf("unexpected stop transition %+v", stop)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	weekly := [7][]Range{}
+	weekly[time.Monday] = []Range{hours(10, 12), hours(18, 22)}
+	weekly[time.Wednesday] = []Range{hours(8, 9)}
+
+	tests := []struct {
+		name    string
+		rules   Rules
+		now     time.Time
+		running bool
+		played  time.Duration
+		want    Transition
+	}{
+		{
+			name: "next window today",
+			now:  at(13, 0),
+			want: Transition{At: at(18, 0), Action: Start},
+		},
+		{
+			name: "next window another day",
+			now:  at(23, 0),
+			want: Transition{At: at(8, 0).AddDate(0, 0, 2), Action: Start},
+		},
+		{
+			name:    "end of the window",
+			now:     at(10, 30),
+			running: true,
+			want:    Transition{At: at(12, 0), Action: Stop},
+		},
+		{
+			name:    "budget ends before the window",
+			rules:   Rules{Budgets: [7]time.Duration{time.Monday: time.Hour}},
+			now:     at(10, 30),
+			running: true,
+			played:  30 * time.Minute,
+			want:    Transition{At: at(11, 0), Action: Stop},
+		},
+		{
+			name:   "budget reached today",
+			rules:  Rules{Budgets: [7]time.Duration{time.Monday: time.Hour}},
+			now:    at(13, 0),
+			played: time.Hour,
+			want:   Transition{At: at(8, 0).AddDate(0, 0, 2), Action: Start},
+		},
+		{
+			name:  "one-off window",
+			rules: Rules{Windows: []Window{{Start: at(14, 0), End: at(15, 0)}}},
+			now:   at(13, 0),
+			want:  Transition{At: at(14, 0), Action: Start},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := tc.rules
+			rules.Weekly = weekly
+			c := &clock{t: tc.now}
+			e := New(rules, c.now, rand.New(rand.NewSource(1)))
+			e.played[tc.now.Format(DateLayout)] = tc.played
+			e.Tick(tc.running)
+
+			next, found := e.Next()
+			if !found {
+				t.Fatal("expected a transition")
+			}
+			if !next.At.Equal(tc.want.At) || next.Action != tc.want.Action {
+				t.Errorf("expected %s at %s, got %s at %s (%s)", tc.want.Action, tc.want.At, next.Action, next.At, next.Reason)
+			}
+		})
+	}
+}
+
+func TestNextBreak(t *testing.T) {
+	weekly := [7][]Range{}
+	weekly[time.Monday] = []Range{hours(18, 22)}
+
+	c := &clock{t: at(18, 0)}
+	e := New(Rules{Weekly: weekly, Breaks: Breaks{EveryMin: time.Hour, LengthMin: 10 * time.Minute, EveryMax: 2 * time.Hour, LengthMax: 20 * time.Minute}}, c.now, rand.New(rand.NewSource(1)))
+	e.Tick(false)
+	c.t = at(18, 1)
+	e.Tick(true)
+
+	brk, _ := e.Next()
+	if brk.Action != Stop || brk.At.Before(at(19, 1)) || brk.At.After(at(20, 1)) {
+		t.Fatalf("unexpected break transition %+v", brk)
+	}
+
+	c.t = brk.At
+	if d := e.Tick(true); d.Action != Stop {
+		t.Fatalf("expected stop for the break, got %s", d.Action)
+	}
+	e.Tick(false)
+
+	resume, _ := e.Next()
+	if resume.Action != Start || resume.At.Before(brk.At.Add(10*time.Minute)) || resume.At.After(brk.At.Add(20*time.Minute)) {
+		t.Fatalf("unexpected resume transition %+v", resume)
+	}
+}
+
+func TestNothingScheduled(t *testing.T) {
+	e := New(Rules{}, func() time.Time { return at(10, 0) }, rand.New(rand.NewSource(1)))
+	if d := e.Tick(true); d.Action != Stop {
+		t.Errorf("expected stop without play windows, got %s", d.Action)
+	}
+	e.Tick(false)
+	if _, found := e.Next(); found {
+		t.Error("expected no transition without play windows")
+	}
+}
@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotFound is returned when a reference points to a missing secret
+	ErrNotFound = errors.New("secret not found")
+	// ErrLocked is returned when the vault holding a secret has not been unlocked yet
+	ErrLocked = errors.New("secrets vault is locked")
+)
+
+// Provider returns the secret stored under the given name
+type Provider interface {
+	Get(name string) (string, error)
+}
+
+// ProviderFunc adapts a function to a Provider
+type ProviderFunc func(name string) (string, error)
+
+func (f ProviderFunc) Get(name string) (string, error) {
+	return f(name)
+}
+
+// Env reads the secrets from the environment variables
+var Env = ProviderFunc(func(name string) (string, error) {
+	value, found := os.LookupEnv(name)
+	if !found {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrNotFound, name)
+	}
+
+	return value, nil
+})
+
+// EnvScheme is the scheme of the ${NAME} references
+const EnvScheme = "env"
+
+// Resolver turns the references stored in the config files into the secret values. References are written as
+// ${NAME} for environment variables or {scheme}:{name} for the other providers, like vault:acct1/password. Any other
+// value is a plaintext secret and is returned as is.
+type Resolver struct {
+	mu        sync.RWMutex
+	providers map[string]Provider
+}
+
+// NewResolver returns a resolver with the environment provider registered
+func NewResolver() *Resolver {
+	return &Resolver{providers: map[string]Provider{EnvScheme: Env}}
+}
+
+// Register adds the provider for the given scheme, replacing the previous one
+func (r *Resolver) Register(scheme string, p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[scheme] = p
+}
+
+// Resolve returns the secret value of the reference, plaintext values are returned unchanged
+func (r *Resolver) Resolve(value string) (string, error) {
+	scheme, name, isRef := r.Parse(value)
+	if !isRef {
+		return value, nil
+	}
+
+	r.mu.RLock()
+	p := r.providers[scheme]
+	r.mu.RUnlock()
+
+	secret, err := p.Get(name)
+	if err != nil {
+		return "", fmt.Errorf("error resolving secret %s: %w", value, err)
+	}
+
+	return secret, nil
+}
+
+// IsReference returns true if the value points to a secret instead of holding it
+func (r *Resolver) IsReference(value string) bool {
+	_, _, isRef := r.Parse(value)
+
+	return isRef
+}
+
+// Parse splits a reference into its scheme and name, only the schemes of the registered providers are references
+func (r *Resolver) Parse(value string) (scheme, name string, isRef bool) {
+	if name, found := strings.CutPrefix(value, "${"); found {
+		if name, found = strings.CutSuffix(name, "}"); found && name != "" {
+			return EnvScheme, name, true
+		}
+	}
+
+	scheme, name, found := strings.Cut(value, ":")
+	if !found || name == "" || scheme == EnvScheme {
+		return "", "", false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, registered := r.providers[scheme]; !registered {
+		return "", "", false
+	}
+
+	return scheme, name, true
+}
+
+// Ref returns the reference to the named secret of the scheme
+func Ref(scheme, name string) string {
+	if scheme == EnvScheme {
+		return "${" + name + "}"
+	}
+
+	return scheme + ":" + name
+}
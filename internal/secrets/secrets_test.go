@@ -0,0 +1,112 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	t.Setenv("KOOLO_TEST_PASS", "hunter2")
+
+	r := NewResolver()
+	r.Register(VaultScheme, ProviderFunc(func(name string) (string, error) {
+		if name == "acct1/password" {
+			return "from-vault", nil
+		}
+		return "", ErrNotFound
+	}))
+
+	cases := map[string]string{
+		"${KOOLO_TEST_PASS}":   "hunter2",
+		"vault:acct1/password": "from-vault",
+		"plain:text":           "plain:text",
+		"${}":                  "${}",
+		"env:KOOLO_TEST_PASS":  "env:KOOLO_TEST_PASS",
+		"":                     "",
+	}
+	for value, expected := range cases {
+		got, err := r.Resolve(value)
+		if err != nil || got != expected {
+			t.Errorf("Resolve(%q) = %q, %v, expected %q", value, got, err, expected)
+		}
+	}
+
+	if _, err := r.Resolve("${KOOLO_TEST_MISSING}"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing environment variables, got %v", err)
+	}
+	if _, err := r.Resolve("vault:missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing vault secrets, got %v", err)
+	}
+}
+
+func TestRef(t *testing.T) {
+	r := NewResolver()
+	r.Register(VaultScheme, NewVault(""))
+	for _, ref := range []string{Ref(EnvScheme, "KOOLO_PASS"), Ref(VaultScheme, "acct1/password")} {
+		if !r.IsReference(ref) {
+			t.Errorf("%s should be a reference", ref)
+		}
+	}
+	if Ref(EnvScheme, "KOOLO_PASS") != "${KOOLO_PASS}" {
+		t.Errorf("unexpected env reference %s", Ref(EnvScheme, "KOOLO_PASS"))
+	}
+}
+
+func TestVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.vault")
+	v := NewVault(path)
+	if v.Exists() || !v.Locked() {
+		t.Fatal("new vaults should not exist and be locked")
+	}
+	if _, err := v.Get("acct1/password"); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+
+	if err := v.Create([]byte("passphrase")); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Create([]byte("passphrase")); err == nil {
+		t.Error("expected an error creating an existing vault")
+	}
+	if err := v.Set("acct1/password", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "acct1") {
+		t.Errorf("the vault file should not contain the secrets in plaintext:\n%s", data)
+	}
+
+	v.Lock()
+	if _, err = v.Get("acct1/password"); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+	if err = v.Unlock([]byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "vault.key")
+	if err = os.WriteFile(keyFile, []byte("passphrase\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	reopened := NewVault(path)
+	if err = reopened.UnlockWithKeyFile(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	if secret, err := reopened.Get("acct1/password"); err != nil || secret != "hunter2" {
+		t.Errorf("expected the stored secret, got %q, %v", secret, err)
+	}
+
+	if err = reopened.Delete("acct1/password"); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := reopened.Names(); err != nil || len(names) != 0 {
+		t.Errorf("expected no secrets left, got %v, %v", names, err)
+	}
+}
@@ -0,0 +1,262 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// VaultScheme is the scheme of the references to the vault secrets, vault:{name}
+const VaultScheme = "vault"
+
+// ErrWrongPassphrase is returned when the vault can not be decrypted with the given passphrase or key file
+var ErrWrongPassphrase = errors.New("wrong vault passphrase or key file")
+
+// scrypt parameters recommended for interactive logins, stored in the file so they can be raised later
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+)
+
+// vaultFile is the on disk format, the secrets are a JSON object encrypted with AES-256-GCM using a key derived from
+// the passphrase with scrypt
+type vaultFile struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// Vault is an encrypted local file holding named secrets, it must be unlocked with its passphrase before use
+type Vault struct {
+	path    string
+	mu      sync.RWMutex
+	key     []byte
+	secrets map[string]string
+	// kdf holds the key derivation parameters of the unlocked file, they are kept when writing it again
+	kdf vaultFile
+}
+
+// NewVault returns the locked vault stored at the given path, the file is only read when unlocking
+func NewVault(path string) *Vault {
+	return &Vault{path: path}
+}
+
+// Path returns the vault file path
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// Exists returns true if the vault file has been created
+func (v *Vault) Exists() bool {
+	_, err := os.Stat(v.path)
+
+	return err == nil
+}
+
+// Locked returns true until the vault is unlocked
+func (v *Vault) Locked() bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	return v.key == nil
+}
+
+// Create writes a new empty vault protected by the passphrase and leaves it unlocked
+func (v *Vault) Create(passphrase []byte) error {
+	if len(passphrase) == 0 {
+		return errors.New("the vault passphrase can not be empty")
+	}
+	if v.Exists() {
+		return fmt.Errorf("vault %s already exists", v.path)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	key, err := scrypt.Key(passphrase, salt, scryptN, scryptR, scryptP, keyLen)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.key, v.secrets = key, make(map[string]string)
+	v.kdf = vaultFile{Version: 1, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: salt}
+
+	return v.save()
+}
+
+// Unlock decrypts the vault with the passphrase
+func (v *Vault) Unlock(passphrase []byte) error {
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		return fmt.Errorf("error reading vault: %w", err)
+	}
+
+	f := vaultFile{}
+	if err = json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("error reading vault %s: %w", v.path, err)
+	}
+	if f.Version != 1 || f.KDF != "scrypt" {
+		return fmt.Errorf("unsupported vault %s, version %d and kdf %q", v.path, f.Version, f.KDF)
+	}
+
+	key, err := scrypt.Key(passphrase, f.Salt, f.N, f.R, f.P, keyLen)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+
+	secrets := make(map[string]string)
+	if err = json.Unmarshal(plain, &secrets); err != nil {
+		return fmt.Errorf("error reading vault %s: %w", v.path, err)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.key, v.secrets = key, secrets
+	v.kdf = f
+	v.kdf.Nonce, v.kdf.Data = nil, nil
+
+	return nil
+}
+
+// UnlockWithKeyFile decrypts the vault using the key file content as passphrase, surrounding whitespace is ignored
+func (v *Vault) UnlockWithKeyFile(path string) error {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading vault key file: %w", err)
+	}
+
+	return v.Unlock(bytes.TrimSpace(key))
+}
+
+// Lock forgets the key and the decrypted secrets
+func (v *Vault) Lock() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.key, v.secrets, v.kdf = nil, nil, vaultFile{}
+}
+
+// Get returns the named secret, it implements Provider
+func (v *Vault) Get(name string) (string, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if v.key == nil {
+		return "", ErrLocked
+	}
+	secret, found := v.secrets[name]
+	if !found {
+		return "", fmt.Errorf("%w: %s is not in the vault", ErrNotFound, name)
+	}
+
+	return secret, nil
+}
+
+// Set stores the secret and writes the vault
+func (v *Vault) Set(name, secret string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key == nil {
+		return ErrLocked
+	}
+	v.secrets[name] = secret
+
+	return v.save()
+}
+
+// Delete removes the secret and writes the vault
+func (v *Vault) Delete(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.key == nil {
+		return ErrLocked
+	}
+	delete(v.secrets, name)
+
+	return v.save()
+}
+
+// Names returns the names of the stored secrets, sorted
+func (v *Vault) Names() ([]string, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if v.key == nil {
+		return nil, ErrLocked
+	}
+
+	return slices.Sorted(maps.Keys(v.secrets)), nil
+}
+
+// save encrypts the secrets with a new nonce and replaces the file, the caller holds the lock
+func (v *Vault) save() error {
+	plain, err := json.Marshal(v.secrets)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(v.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+
+	f := v.kdf
+	f.Nonce, f.Data = nonce, gcm.Seal(nil, nonce, plain, nil)
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return err
+	}
+	tmp := v.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("error writing vault: %w", err)
+	}
+
+	return os.Rename(tmp, v.path)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/bot/lifecycle"
+	"github.com/hectorgimenez/koolo/internal/schedule"
+)
+
+// DefaultURL is the address of the HTTP server of the running Koolo instance
+const DefaultURL = "http://localhost:8087"
+
+const (
+	requestTimeout = 10 * time.Second
+	// Starting waits until the game is launched, it takes a while
+	startTimeout = 3 * time.Minute
+)
+
+// Status is the state of every supervisor, as returned by /initial-data. Only the fields used outside the dashboard
+// are decoded.
+type Status struct {
+	Version string
+	Status  map[string]SupervisorStatus
+	// Schedule is the next scheduler transition of each supervisor
+	Schedule map[string]schedule.Transition
+}
+
+type SupervisorStatus struct {
+	SupervisorStatus lifecycle.State
+	Details          string
+	StartedAt        time.Time
+}
+
+// Summary are the totals of a supervisor returned by /api/stats
+type Summary struct {
+	Status    lifecycle.State `json:"status"`
+	StartedAt time.Time       `json:"startedAt"`
+	Games     int             `json:"games"`
+	Drops     int             `json:"drops"`
+	Deaths    int             `json:"deaths"`
+	Chickens  int             `json:"chickens"`
+	Errors    int             `json:"errors"`
+}
+
+// Client talks to the HTTP server of a running Koolo instance
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{},
+	}
+}
+
+// Start asks the instance to start the supervisor, it returns once the game is launched or failed to start
+func (c *Client) Start(supervisor string) error {
+	return c.do(http.MethodPost, "/start?characterName="+url.QueryEscape(supervisor), startTimeout, nil)
+}
+
+// Stop asks the instance to stop the supervisor
+func (c *Client) Stop(supervisor string) error {
+	return c.do(http.MethodPost, "/stop?characterName="+url.QueryEscape(supervisor), requestTimeout, nil)
+}
+
+// Status returns the state of every supervisor
+func (c *Client) Status() (Status, error) {
+	s := Status{}
+	err := c.do(http.MethodGet, "/initial-data", requestTimeout, &s)
+
+	return s, err
+}
+
+// Stats returns the totals of every supervisor
+func (c *Client) Stats() (map[string]Summary, error) {
+	stats := make(map[string]Summary)
+	err := c.do(http.MethodGet, "/api/stats", requestTimeout, &stats)
+
+	return stats, err
+}
+
+func (c *Client) do(method, path string, timeout time.Duration, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("koolo is not running or not reachable at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// WriteStatus prints a table with the state of every supervisor and its next scheduler transition
+func WriteStatus(w io.Writer, s Status) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUPERVISOR\tSTATUS\tNEXT SCHEDULE\tDETAILS")
+	for _, name := range slices.Sorted(maps.Keys(s.Status)) {
+		st := s.Status[name]
+		next := "-"
+		if t, found := s.Schedule[name]; found && t.Action != schedule.None {
+			next = fmt.Sprintf("%s at %s", t.Action, t.At.Format("Mon 15:04"))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, state(st.SupervisorStatus), next, orDefault(st.Details, "-"))
+	}
+
+	return tw.Flush()
+}
+
+// WriteStats prints a table with the totals of every supervisor, uptime is only shown for the running ones
+func WriteStats(w io.Writer, stats map[string]Summary, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUPERVISOR\tSTATUS\tUPTIME\tGAMES\tDROPS\tDEATHS\tCHICKENS\tERRORS")
+	for _, name := range slices.Sorted(maps.Keys(stats)) {
+		s := stats[name]
+		uptime := "-"
+		if s.Status.Running() && !s.StartedAt.IsZero() {
+			uptime = now.Sub(s.StartedAt).Truncate(time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n", name, state(s.Status), uptime, s.Games, s.Drops, s.Deaths, s.Chickens, s.Errors)
+	}
+
+	return tw.Flush()
+}
+
+// state returns the state to show, supervisors never started have none
+func state(s lifecycle.State) string {
+	return orDefault(string(s), string(lifecycle.NotStarted))
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
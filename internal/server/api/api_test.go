@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hectorgimenez/koolo/internal/bot/lifecycle"
+	"github.com/hectorgimenez/koolo/internal/schedule"
+)
+
+func TestClient(t *testing.T) {
+	started := make([]string, 0)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := r.URL.Query().Get("characterName")
+		if name == "running" {
+			http.Error(w, "supervisor is already running: running", http.StatusConflict)
+			return
+		}
+		if name != "my sorc" {
+			http.Error(w, "Character "+name+" not found", http.StatusNotFound)
+			return
+		}
+		started = append(started, name)
+	})
+	mux.HandleFunc("/api/stats", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]Summary{"my sorc": {Status: lifecycle.InGame, Games: 3}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := NewClient(srv.URL + "/")
+	if err := c.Start("my sorc"); err != nil || len(started) != 1 {
+		t.Errorf("expected the supervisor to start, got %v, %v", started, err)
+	}
+	if err := c.Start("missing"); err == nil || !strings.Contains(err.Error(), "Character missing not found") {
+		t.Errorf("expected the server error, got %v", err)
+	}
+	if err := c.Start("running"); err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("expected the start error, got %v", err)
+	}
+
+	stats, err := c.Stats()
+	if err != nil || stats["my sorc"].Games != 3 || stats["my sorc"].Status != lifecycle.InGame {
+		t.Errorf("unexpected stats %+v, %v", stats, err)
+	}
+
+	srv.Close()
+	if _, err = c.Status(); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("expected a not running error, got %v", err)
+	}
+}
+
+func TestWriteStatus(t *testing.T) {
+	at := time.Date(2024, 12, 23, 20, 30, 0, 0, time.Local)
+	s := Status{
+		Status: map[string]SupervisorStatus{
+			"sorc":    {SupervisorStatus: lifecycle.InGame, Details: "Running pindleskin"},
+			"hammers": {},
+		},
+		Schedule: map[string]schedule.Transition{"hammers": {At: at, Action: schedule.Start}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStatus(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `SUPERVISOR  STATUS       NEXT SCHEDULE       DETAILS
+hammers     Not Started  start at Mon 20:30  -
+sorc        In game      -                   Running pindleskin
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	now := time.Now()
+	stats := map[string]Summary{
+		"sorc":    {Status: lifecycle.InGame, StartedAt: now.Add(-90 * time.Minute), Games: 12, Drops: 4, Deaths: 1, Chickens: 2},
+		"hammers": {Status: lifecycle.NotStarted, StartedAt: now.Add(-time.Hour), Errors: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteStats(&buf, stats, now); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `SUPERVISOR  STATUS       UPTIME   GAMES  DROPS  DEATHS  CHICKENS  ERRORS
+hammers     Not Started  -        0      0      0       0         1
+sorc        In game      1h30m0s  12     4      1       2         0
+`
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
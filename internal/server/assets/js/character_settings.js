@@ -134,10 +134,12 @@ document.addEventListener('DOMContentLoaded', function () {
         const berserkerBarbOptions = document.querySelector('.berserker-barb-options');
         const novaSorceressOptions = document.querySelector('.nova-sorceress-options');
         const mosaicAssassinOptions = document.querySelector('.mosaic-assassin-options');
+        const customBuildOptions = document.querySelector('.custom-build-options');
         // Hide all options first
         berserkerBarbOptions.style.display = 'none';
         novaSorceressOptions.style.display = 'none';
         mosaicAssassinOptions.style.display = 'none';
+        customBuildOptions.style.display = 'none';
         noSettingsMessage.style.display = 'none';
         
         // Show relevant options based on class
@@ -148,6 +150,8 @@ document.addEventListener('DOMContentLoaded', function () {
             updateNovaSorceressOptions();
         } else if (selectedClass === 'mosaic') {
             mosaicAssassinOptions.style.display = 'block';
+        } else if (selectedClass === 'custom') {
+            customBuildOptions.style.display = 'block';
         } else {
             noSettingsMessage.style.display = 'block';
         }
@@ -255,4 +259,15 @@ function handleBossStaticThresholdChange() {
         value = 100;
     }
     input.value = value;
-}
\ No newline at end of file
+}
+
+// resetConfigValue removes the value from the character config to use the one inherited from its profiles
+async function resetConfigValue(supervisor, path) {
+    const response = await fetch(`/api/config/reset?supervisor=${encodeURIComponent(supervisor)}&path=${encodeURIComponent(path)}`, {method: 'POST'});
+    if (!response.ok) {
+        alert(await response.text());
+        return;
+    }
+
+    window.location.reload();
+}
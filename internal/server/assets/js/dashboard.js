@@ -48,6 +48,8 @@ let socket;
             }
         }
 
+        updateConfigErrors(data.ConfigErrors);
+
         const container = document.getElementById('characters-container');
         if (!container) return;
 
@@ -63,6 +65,8 @@ let socket;
                 container.appendChild(card);
             }
             updateCharacterCard(card, key, value, data.DropCount[key]);
+            updateNextSchedule(card, data.Schedule ? data.Schedule[key] : undefined);
+            updatePendingConfig(card, value.PendingConfig);
         }
 
         // Remove cards for characters that no longer exist
@@ -119,6 +123,10 @@ let socket;
                         <div class="stat-label">Drops</div>
                         <div class="stat-value drops">None</div>
                     </div>
+                    <div class="stat-item">
+                        <div class="stat-label">Kills</div>
+                        <div class="stat-value kills">0</div>
+                    </div>
                     <div class="stat-item">
                         <div class="stat-label">Chickens</div>
                         <div class="stat-value chickens">0</div>
@@ -133,6 +141,9 @@ let socket;
                     </div>
                 </div>
                 <div class="run-stats"></div>
+                <div class="crafting-stats"></div>
+                <div class="gambling-stats"></div>
+                <div class="merc-stats"></div>
             </div>
         `;
 
@@ -169,17 +180,22 @@ let socket;
                 } else { // Paused
                     action = 'togglePause';
                 }
-                fetch(`/${action}?characterName=${key}`)
-                    .then(response => response.json())
+                fetch(`/${action}?characterName=${key}`, { method: 'POST' })
+                    .then(async response => {
+                        if (!response.ok) {
+                            throw new Error(await response.text());
+                        }
+                        return response.json();
+                    })
                     .then(data => {
                         updateDashboard(data);
                     })
-                    .catch(error => console.error('Error:', error));
+                    .catch(error => alert(error.message));
             });
         }
         if (stopBtn) {
             stopBtn.addEventListener('click', function() {
-                fetch(`/stop?characterName=${key}`).then(() => fetchInitialData());
+                fetch(`/stop?characterName=${key}`, { method: 'POST' }).then(() => fetchInitialData());
             });
         }
     }
@@ -227,6 +243,9 @@ let socket;
 
         updateStats(card, key, value.Games, dropCount);
         updateRunStats(card, value.Games);
+        updateCraftingStats(card, value.Crafting);
+        updateGamblingStats(card, value.Gambling);
+        updateMercStats(card, value.Merc);
         
         if (statusDetails) {
             updateStartedTime(statusDetails, value.StartedAt);
@@ -239,7 +258,7 @@ let socket;
             statusIndicator.classList.add('in-game');
         } else if (status === "Starting") {
             statusIndicator.classList.add('paused');
-        } else if (status === "Paused") {
+        } else if (status === "Paused" || status === "Backing off" || status === "Stopping") {
             statusIndicator.classList.add('paused');
         } else {
             statusIndicator.classList.add('stopped');
@@ -280,8 +299,87 @@ let socket;
         runningForElement.textContent = `Running for: ${duration}`;
     }
 
+    function updateNextSchedule(card, next) {
+        const statusDetails = card.querySelector('.status-details');
+        let nextScheduleElement = statusDetails.querySelector('.next-schedule');
+        if (!next) {
+            if (nextScheduleElement) {
+                nextScheduleElement.remove();
+            }
+            return;
+        }
+
+        if (!nextScheduleElement) {
+            nextScheduleElement = document.createElement('div');
+            nextScheduleElement.className = 'next-schedule';
+            statusDetails.appendChild(nextScheduleElement);
+        }
+
+        const at = new Date(next.At);
+        const sameDay = at.toDateString() === new Date().toDateString();
+        const when = sameDay ? at.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' }) : at.toLocaleString([], { weekday: 'short', hour: '2-digit', minute: '2-digit' });
+        nextScheduleElement.textContent = `Scheduled ${next.Action}: ${when} (${next.Reason})`;
+    }
+
+    function updateConfigErrors(configErrors) {
+        const container = document.getElementById('config-errors');
+        if (!container) return;
+
+        container.innerHTML = '';
+        for (const [key, message] of Object.entries(configErrors || {})) {
+            const article = document.createElement('article');
+            article.className = 'config-error';
+
+            const title = document.createElement('p');
+            title.innerHTML = `<i class="bi bi-exclamation-triangle"></i> <a href="/supervisorSettings?supervisor=${encodeURIComponent(key)}"></a> can't be started, fix its config:`;
+            title.querySelector('a').textContent = key;
+
+            const details = document.createElement('pre');
+            details.textContent = message;
+
+            article.appendChild(title);
+            article.appendChild(details);
+            container.appendChild(article);
+        }
+    }
+
+    function updatePendingConfig(card, pending) {
+        const statusDetails = card.querySelector('.status-details');
+        let pendingElement = statusDetails.querySelector('.pending-config');
+        if (!pending || pending.length === 0) {
+            if (pendingElement) {
+                pendingElement.remove();
+            }
+            return;
+        }
+
+        if (!pendingElement) {
+            pendingElement = document.createElement('div');
+            pendingElement.className = 'pending-config';
+            statusDetails.appendChild(pendingElement);
+        }
+
+        const nextGame = pending.filter(c => c.Apply === 'next game').length;
+        const restart = pending.length - nextGame;
+        const parts = [];
+        if (nextGame > 0) {
+            parts.push(`${nextGame} next game`);
+        }
+        if (restart > 0) {
+            parts.push(`${restart} on restart`);
+        }
+        pendingElement.textContent = `Pending config changes: ${parts.join(', ')}`;
+        pendingElement.title = pending.map(c => `${c.Path} (${c.Apply})`).join('\n');
+    }
+
 function updateButtons(startPauseBtn, stopBtn, attachBtn, status) {
-    if (status === "Paused") {
+    startPauseBtn.style.display = 'inline-block';
+    if (status === "Backing off" || status === "Stopping") {
+        // No client to pause, stopping cancels the pending restart
+        startPauseBtn.style.display = 'none';
+        stopBtn.style.display = 'inline-block';
+        attachBtn.style.display = 'none';
+    } else if (status === "Paused") {
         startPauseBtn.innerHTML = '<i class="bi bi-play-fill btn-icon"></i>Resume';
         startPauseBtn.className = 'start-pause btn btn-resume';
         stopBtn.style.display = 'inline-block';
@@ -305,6 +403,8 @@ function updateButtons(startPauseBtn, stopBtn, attachBtn, status) {
         card.querySelector('.runs').textContent = stats.totalGames;
         card.querySelector('.drops').innerHTML = dropCount === undefined ? 'None' : 
             (dropCount === 0 ? 'None' : `<a href="/drops?supervisor=${key}">${dropCount}</a>`);
+        card.querySelector('.kills').innerHTML = stats.totalKills === 0 ? '0' :
+            `<a href="/attacks?supervisor=${key}">${stats.totalKills}</a>`;
         card.querySelector('.chickens').textContent = stats.totalChickens;
         card.querySelector('.deaths').textContent = stats.totalDeaths;
         card.querySelector('.errors').textContent = stats.totalErrors;
@@ -362,6 +462,115 @@ function updateButtons(startPauseBtn, stopBtn, attachBtn, status) {
         runStatsElement.appendChild(runStatsGrid);
     }   
 
+    function updateGamblingStats(card, gambling) {
+        const gamblingStatsElement = card.querySelector('.gambling-stats');
+        if (!gambling || gambling.Bought === 0) {
+            gamblingStatsElement.innerHTML = '';
+            return;
+        }
+
+        const goldPerKeeper = gambling.Kept > 0 ? Math.round(gambling.Spent / gambling.Kept).toLocaleString() : 'N/A';
+        gamblingStatsElement.innerHTML = `
+            <h3>Gambling Statistics</h3>
+            <div class="run-stats-grid">
+                <div class="run-stat">
+                    <div class="run-stat-content">
+                        <div class="run-stat-item" title="Gambled Items">
+                            <span class="stat-label">Gambled:</span> ${gambling.Bought}
+                        </div>
+                        <div class="run-stat-item" title="Gold Spent">
+                            <span class="stat-label">Spent:</span> ${gambling.Spent.toLocaleString()}
+                        </div>
+                        <div class="run-stat-item" title="Items matching pickit rules">
+                            <span class="stat-label">Kept:</span> ${gambling.Kept}
+                        </div>
+                        <div class="run-stat-item" title="Gold spent per kept item">
+                            <span class="stat-label">Gold per keeper:</span> ${goldPerKeeper}
+                        </div>
+                        <div class="run-stat-item" title="Rare Items">
+                            <span class="stat-label">Rares:</span> ${gambling.Rares}
+                        </div>
+                        <div class="run-stat-item" title="Unique Items">
+                            <span class="stat-label">Uniques:</span> ${gambling.Uniques}
+                        </div>
+                    </div>
+                </div>
+            </div>
+        `;
+    }
+
+    function updateMercStats(card, merc) {
+        const mercStatsElement = card.querySelector('.merc-stats');
+        if (!merc || (merc.Deaths === 0 && merc.Equipped === 0)) {
+            mercStatsElement.innerHTML = '';
+            return;
+        }
+
+        mercStatsElement.innerHTML = `
+            <h3>Mercenary Statistics</h3>
+            <div class="run-stats-grid">
+                <div class="run-stat">
+                    <div class="run-stat-content">
+                        <div class="run-stat-item" title="Mercenary Deaths">
+                            <span class="stat-label">Deaths:</span> ${merc.Deaths}
+                        </div>
+                        <div class="run-stat-item" title="Mercenary Revives">
+                            <span class="stat-label">Revives:</span> ${merc.Revives}
+                        </div>
+                        <div class="run-stat-item" title="New mercenaries hired">
+                            <span class="stat-label">Re-hires:</span> ${merc.Rehires}
+                        </div>
+                        <div class="run-stat-item" title="Gold spent reviving and hiring">
+                            <span class="stat-label">Spent:</span> ${merc.GoldSpent.toLocaleString()}
+                        </div>
+                        <div class="run-stat-item" title="Items equipped from the stash">
+                            <span class="stat-label">Equipped:</span> ${merc.Equipped}
+                        </div>
+                    </div>
+                </div>
+            </div>
+        `;
+    }
+
+    function updateCraftingStats(card, crafting) {
+        const craftingStatsElement = card.querySelector('.crafting-stats');
+        if (!crafting || Object.keys(crafting).length === 0) {
+            craftingStatsElement.innerHTML = '';
+            return;
+        }
+
+        craftingStatsElement.innerHTML = '<h3>Crafting Statistics</h3>';
+
+        const craftingStatsGrid = document.createElement('div');
+        craftingStatsGrid.className = 'run-stats-grid';
+
+        for (const [recipeName, stats] of Object.entries(crafting)) {
+            const keepRate = stats.Crafted > 0 ? ((stats.Kept / stats.Crafted) * 100).toFixed(1) : 0;
+            const recipeElement = document.createElement('div');
+            recipeElement.className = 'run-stat';
+            recipeElement.innerHTML = `
+                <h4>${recipeName}</h4>
+                <div class="run-stat-content">
+                    <div class="run-stat-item" title="Crafted Items">
+                        <span class="stat-label">Crafted:</span> ${stats.Crafted}
+                    </div>
+                    <div class="run-stat-item" title="Items matching pickit rules">
+                        <span class="stat-label">Kept:</span> ${stats.Kept}
+                    </div>
+                    <div class="run-stat-item" title="Items not matching pickit rules">
+                        <span class="stat-label">Sold:</span> ${stats.Sold}
+                    </div>
+                    <div class="run-stat-item" title="Keep Rate">
+                        <span class="stat-label">Keep rate:</span> ${keepRate}%
+                    </div>
+                </div>
+            `;
+            craftingStatsGrid.appendChild(recipeElement);
+        }
+
+        craftingStatsElement.appendChild(craftingStatsGrid);
+    }
+
 
     function calculateRunStats(games) {
         if (!games || games.length === 0) {
@@ -435,16 +644,19 @@ function updateButtons(startPauseBtn, stopBtn, attachBtn, status) {
 
     function calculateStats(games) {
         if (!games || games.length === 0) {
-            return { totalGames: 0, totalChickens: 0, totalDeaths: 0, totalErrors: 0 };
+            return { totalGames: 0, totalKills: 0, totalChickens: 0, totalDeaths: 0, totalErrors: 0 };
         }
 
         return games.reduce((acc, game) => {
             acc.totalGames++;
+            (game.Runs || []).forEach(run => {
+                Object.values(run.Attacks || {}).forEach(entry => acc.totalKills += entry.Kills);
+            });
             if (game.Reason === 'chicken') acc.totalChickens++;
             else if (game.Reason === 'death') acc.totalDeaths++;
             else if (game.Reason === 'error') acc.totalErrors++;
             return acc;
-        }, { totalGames: 0, totalChickens: 0, totalDeaths: 0, totalErrors: 0 });
+        }, { totalGames: 0, totalKills: 0, totalChickens: 0, totalDeaths: 0, totalErrors: 0 });
     } 
 
     function formatDuration(ms) {
@@ -630,10 +842,27 @@ function updateButtons(startPauseBtn, stopBtn, attachBtn, status) {
         try {
             const response = await fetch('/api/reload-config');
             if (!response.ok) {
-                throw new Error('Failed to reload config');
+                throw new Error(await response.text() || 'Failed to reload config');
             }
+
+            const reports = await response.json();
+            fetchInitialData();
+            if (reports.length === 0) {
+                alert('Config reloaded, no changes for the running characters.');
+                return;
+            }
+
+            const summary = reports.map(report => {
+                if (report.Error) {
+                    return `${report.Supervisor}: invalid config, the current one is kept\n  ${report.Error}`;
+                }
+                const lines = report.Changes.map(c => `  ${c.Path}: ${c.Old} -> ${c.New} (${c.Apply})`);
+                return `${report.Supervisor}:\n${lines.join('\n')}`;
+            });
+            alert(`Config reloaded, changes are applied now, on the next game or on restart:\n\n${summary.join('\n\n')}`);
         } catch (error) {
             console.error('Error reloading config:', error);
+            alert(`Error reloading config: ${error.message}`);
         } finally {
             // Re-enable button and stop rotation
             btn.disabled = false;
@@ -11,6 +11,7 @@ import (
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"slices"
 	"sort"
 	"strconv"
@@ -25,9 +26,15 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/difficulty"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/bot/lifecycle"
+	"github.com/hectorgimenez/koolo/internal/buff"
+	"github.com/hectorgimenez/koolo/internal/combat"
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/config/validation"
 	ctx "github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
+	"github.com/hectorgimenez/koolo/internal/schedule"
+	"github.com/hectorgimenez/koolo/internal/server/api"
 	"github.com/hectorgimenez/koolo/internal/utils"
 	"github.com/hectorgimenez/koolo/internal/utils/winproc"
 	"github.com/lxn/win"
@@ -38,6 +45,7 @@ type HttpServer struct {
 	logger    *slog.Logger
 	server    *http.Server
 	manager   *bot.SupervisorManager
+	scheduler *bot.Scheduler
 	templates *template.Template
 	wsServer  *WebSocketServer
 }
@@ -176,7 +184,7 @@ func (s *HttpServer) BroadcastStatus() {
 	}
 }
 
-func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, error) {
+func New(logger *slog.Logger, manager *bot.SupervisorManager, scheduler *bot.Scheduler) (*HttpServer, error) {
 	var templates *template.Template
 	helperFuncs := template.FuncMap{
 		"isInSlice": func(slice []stat.Resist, value string) bool {
@@ -198,6 +206,14 @@ func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, erro
 		"qualityClass": qualityClass,
 		"statIDToText": statIDToText,
 		"contains":     containss,
+		"join":         strings.Join,
+		// secretRef returns the secret config values that can be shown, only references like ${KOOLO_PASS}
+		"secretRef": func(value string) string {
+			if config.IsSecretReference(value) {
+				return value
+			}
+			return ""
+		},
 		"seq": func(start, end int) []int {
 			var result []int
 			for i := start; i <= end; i++ {
@@ -214,6 +230,7 @@ func New(logger *slog.Logger, manager *bot.SupervisorManager) (*HttpServer, erro
 	return &HttpServer{
 		logger:    logger,
 		manager:   manager,
+		scheduler: scheduler,
 		templates: templates,
 	}, nil
 }
@@ -258,8 +275,11 @@ func (s *HttpServer) attachProcess(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Call manager.Start with the correct arguments, including the HWND
-	go s.manager.Start(characterName, true, uint32(pid), uint32(hwnd))
+	// Call manager.Start with the correct arguments, including the HWND, the supervisor is built in the background
+	if err := s.manager.Start(characterName, true, uint32(pid), uint32(hwnd)); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]bool{"success": true})
@@ -386,6 +406,7 @@ func (s *HttpServer) initialData(w http.ResponseWriter, r *http.Request) {
 func (s *HttpServer) getStatusData() IndexData {
 	status := make(map[string]bot.Stats)
 	drops := make(map[string]int)
+	schedules := make(map[string]schedule.Transition)
 
 	for _, supervisorName := range s.manager.AvailableSupervisors() {
 		status[supervisorName] = s.manager.Status(supervisorName)
@@ -394,12 +415,24 @@ func (s *HttpServer) getStatusData() IndexData {
 		} else {
 			drops[supervisorName] = 0
 		}
+		if next, found := s.scheduler.Next(supervisorName); found {
+			schedules[supervisorName] = next
+		}
+	}
+
+	configErrors := make(map[string]string)
+	for name, invalid := range config.InvalidCharacters() {
+		if name != "template" {
+			configErrors[name] = invalid.Err.Error()
+		}
 	}
 
 	return IndexData{
-		Version:   config.Version,
-		Status:    status,
-		DropCount: drops,
+		Version:      config.Version,
+		Status:       status,
+		DropCount:    drops,
+		Schedule:     schedules,
+		ConfigErrors: configErrors,
 	}
 }
 
@@ -411,17 +444,22 @@ func (s *HttpServer) Listen(port int) error {
 	http.HandleFunc("/", s.getRoot)
 	http.HandleFunc("/config", s.config)
 	http.HandleFunc("/supervisorSettings", s.characterSettings)
+	http.HandleFunc("/profiles", s.profiles)
+	http.HandleFunc("/secrets", s.secrets)
+	http.HandleFunc("/api/config/reset", s.resetConfigValue)
 	http.HandleFunc("/start", s.startSupervisor)
 	http.HandleFunc("/stop", s.stopSupervisor)
 	http.HandleFunc("/togglePause", s.togglePause)
 	http.HandleFunc("/debug", s.debugHandler)
 	http.HandleFunc("/debug-data", s.debugData)
 	http.HandleFunc("/drops", s.drops)
+	http.HandleFunc("/attacks", s.attacks)
 	http.HandleFunc("/process-list", s.getProcessList)
 	http.HandleFunc("/attach-process", s.attachProcess)
 	http.HandleFunc("/ws", s.wsServer.HandleWebSocket)    // Web socket
 	http.HandleFunc("/initial-data", s.initialData)       // Web socket data
 	http.HandleFunc("/api/reload-config", s.reloadConfig) // New handler
+	http.HandleFunc("/api/stats", s.stats)
 
 	assets, _ := fs.Sub(assetsFS, "assets")
 	http.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.FS(assets))))
@@ -438,14 +476,41 @@ func (s *HttpServer) Listen(port int) error {
 }
 
 func (s *HttpServer) reloadConfig(w http.ResponseWriter, r *http.Request) {
-	result := s.manager.ReloadConfig()
-	if result != nil {
-		http.Error(w, result.Error(), http.StatusInternalServerError)
+	reports, err := s.manager.ReloadConfig()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	for _, report := range reports {
+		for _, c := range report.Changes {
+			s.logger.Info("Config changed", slog.String("supervisor", report.Supervisor), slog.String("field", c.Path), slog.String("from", c.Old), slog.String("to", c.New), slog.String("applied", c.Apply.String()))
+		}
+	}
 	s.logger.Info("Config reloaded")
-	w.WriteHeader(http.StatusOK)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reports)
+}
+
+// stats returns the totals of every supervisor, used by the koolo stats command
+func (s *HttpServer) stats(w http.ResponseWriter, r *http.Request) {
+	summaries := make(map[string]api.Summary)
+	for _, supervisorName := range s.manager.AvailableSupervisors() {
+		stats := s.manager.GetSupervisorStats(supervisorName)
+		summaries[supervisorName] = api.Summary{
+			Status:    stats.SupervisorStatus,
+			StartedAt: stats.StartedAt,
+			Games:     stats.TotalGames(),
+			Drops:     len(stats.Drops),
+			Deaths:    stats.TotalDeaths(),
+			Chickens:  stats.TotalChickens(),
+			Errors:    stats.TotalErrors(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaries)
 }
 
 func (s *HttpServer) Stop() error {
@@ -478,6 +543,7 @@ func (s *HttpServer) debugData(w http.ResponseWriter, r *http.Request) {
 
 	type DebugData struct {
 		DebugData map[ctx.Priority]*ctx.Debug
+		Buffs     []buff.Entry
 		GameData  *game.Data
 	}
 
@@ -485,6 +551,7 @@ func (s *HttpServer) debugData(w http.ResponseWriter, r *http.Request) {
 
 	debugData := DebugData{
 		DebugData: context.ContextDebug,
+		Buffs:     context.Buffs.Timeline(time.Now()),
 		GameData:  context.Data,
 	}
 
@@ -502,13 +569,22 @@ func (s *HttpServer) debugHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *HttpServer) startSupervisor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
 	supervisorList := s.manager.AvailableSupervisors()
 	Supervisor := r.URL.Query().Get("characterName")
 
 	// Get the current auth method for the supervisor we wanna start
-	supCfg, currFound := config.Characters[Supervisor]
+	supCfg, currFound := config.Characters()[Supervisor]
 	if !currFound {
-		// There's no config for the current supervisor. THIS SHOULDN'T HAPPEN
+		if invalid, found := config.InvalidCharacters()[Supervisor]; found {
+			http.Error(w, invalid.Err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		http.Error(w, "Character "+Supervisor+" not found", http.StatusNotFound)
 		return
 	}
 
@@ -524,25 +600,49 @@ func (s *HttpServer) startSupervisor(w http.ResponseWriter, r *http.Request) {
 
 			// Prevent launching if we're using token auth & another client is starting (no matter what auth method)
 			if supCfg.AuthMethod == "TokenAuth" {
+				http.Error(w, "Wait until "+sup+" has started, "+Supervisor+" uses token auth", http.StatusConflict)
 				return
 			}
 
 			// Prevent launching if another client that is using token auth is starting
-			sCfg, found := config.Characters[sup]
+			sCfg, found := config.Characters()[sup]
 			if found {
 				if sCfg.AuthMethod == "TokenAuth" {
+					http.Error(w, "Wait until "+sup+" has started, it uses token auth", http.StatusConflict)
 					return
 				}
 			}
 		}
 	}
 
-	s.manager.Start(Supervisor, false)
+	// Manual starts enable again the supervisors disabled after too many crashes
+	s.manager.Enable(Supervisor)
+
+	// The supervisor shows as starting while the game is launched, the response waits for it so the dashboard and
+	// the CLI get the errors
+	if err := s.manager.StartAndWait(Supervisor, false); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, lifecycle.ErrAlreadyRunning) || errors.Is(err, lifecycle.ErrDisabled) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
 	s.initialData(w, r)
 }
 
 func (s *HttpServer) stopSupervisor(w http.ResponseWriter, r *http.Request) {
-	s.manager.Stop(r.URL.Query().Get("characterName"))
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	supervisor := r.URL.Query().Get("characterName")
+	if _, found := config.Characters()[supervisor]; !found {
+		http.Error(w, "Character "+supervisor+" not found", http.StatusNotFound)
+		return
+	}
+	s.manager.Stop(supervisor)
 	s.initialData(w, r)
 }
 
@@ -579,7 +679,7 @@ func (s *HttpServer) index(w http.ResponseWriter) {
 
 func (s *HttpServer) drops(w http.ResponseWriter, r *http.Request) {
 	sup := r.URL.Query().Get("supervisor")
-	cfg, found := config.Characters[sup]
+	cfg, found := config.Characters()[sup]
 	if !found {
 		http.Error(w, "Can't fetch drop data because the configuration "+sup+" wasn't found", http.StatusNotFound)
 		return
@@ -600,6 +700,96 @@ func (s *HttpServer) drops(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *HttpServer) attacks(w http.ResponseWriter, r *http.Request) {
+	sup := r.URL.Query().Get("supervisor")
+	cfg, found := config.Characters()[sup]
+	if !found {
+		http.Error(w, "Can't fetch attack data because the configuration "+sup+" wasn't found", http.StatusNotFound)
+		return
+	}
+
+	runs := s.manager.GetSupervisorStats(sup).Attacks()
+	total := make(combat.Summary)
+	attackData := AttackData{Character: cfg.CharacterName}
+	for name, summary := range runs {
+		total.Merge(summary)
+		attackData.Runs = append(attackData.Runs, AttackRunData{Name: name, Entries: summary.Entries()})
+	}
+	sort.Slice(attackData.Runs, func(i, j int) bool {
+		return attackData.Runs[i].Name < attackData.Runs[j].Name
+	})
+	attackData.Total = total.Entries()
+
+	s.templates.ExecuteTemplate(w, "attacks.gohtml", attackData)
+}
+
+func (s *HttpServer) profiles(w http.ResponseWriter, r *http.Request) {
+	data := ProfilesData{Name: r.URL.Query().Get("name")}
+	if r.Method == http.MethodPost {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		data.Name, data.Content = strings.TrimSpace(r.Form.Get("name")), r.Form.Get("content")
+		if plaintext, err := config.SaveProfile(data.Name, data.Content); err != nil {
+			data.ErrorMessage = err.Error()
+		} else if len(plaintext) > 0 {
+			s.logger.Warn("Secrets saved in plaintext", slog.String("profile", data.Name), slog.Any("secrets", plaintext))
+			data.ErrorMessage = plaintextWarning(plaintext)
+			data.Content, _ = config.ReadProfile(data.Name)
+		} else {
+			http.Redirect(w, r, "/profiles?name="+url.QueryEscape(data.Name), http.StatusSeeOther)
+			return
+		}
+	} else if data.Name != "" {
+		content, err := config.ReadProfile(data.Name)
+		if err != nil {
+			data.ErrorMessage = err.Error()
+		}
+		data.Content = content
+	}
+
+	profiles, err := config.Profiles()
+	if err != nil {
+		data.ErrorMessage = err.Error()
+	}
+	data.UsedBy = make(map[string][]string)
+	for _, p := range profiles {
+		data.UsedBy[p] = make([]string, 0)
+	}
+	for name, cfg := range config.Characters() {
+		for _, p := range cfg.Profiles {
+			data.UsedBy[p] = append(data.UsedBy[p], name)
+		}
+	}
+	for _, used := range data.UsedBy {
+		sort.Strings(used)
+	}
+
+	s.templates.ExecuteTemplate(w, "profiles.gohtml", data)
+}
+
+func (s *HttpServer) resetConfigValue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	supervisor := r.URL.Query().Get("supervisor")
+	if _, found := config.Characters()[supervisor]; !found {
+		http.Error(w, "Character "+supervisor+" not found", http.StatusNotFound)
+		return
+	}
+
+	if err := config.ResetValue(supervisor, r.URL.Query().Get("path")); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func validateSchedulerData(cfg *config.CharacterCfg) error {
 	for day := 0; day < 7; day++ {
 
@@ -633,7 +823,7 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		err := r.ParseForm()
 		if err != nil {
-			s.templates.ExecuteTemplate(w, "config.gohtml", ConfigData{KooloCfg: config.Koolo, ErrorMessage: "Error parsing form"})
+			s.renderConfig(w, config.Koolo, "Error parsing form")
 			return
 		}
 
@@ -654,6 +844,7 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 		newConfig.Discord.EnableRunFinishMessages = r.Form.Has("enable_run_finish_messages")
 		newConfig.Discord.EnableDiscordChickenMessages = r.Form.Has("enable_discord_chicken_messages")
 		newConfig.Discord.EnableDiscordErrorMessages = r.Form.Has("enable_discord_error_messages")
+		newConfig.Discord.EnableDiscordMercMessages = r.Form.Has("enable_discord_merc_messages")
 
 		// Discord admins who can use bot commands
 		discordAdmins := r.Form.Get("discord_admins")
@@ -664,21 +855,37 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 			return -1
 		}, discordAdmins)
 		newConfig.Discord.BotAdmins = strings.Split(cleanedAdmins, ",")
-		newConfig.Discord.Token = r.Form.Get("discord_token")
+		plaintext := make([]string, 0)
+		if newConfig.Discord.Token, err = secretFromForm(r, "discord_token", config.Koolo.Discord.Token, "koolo/discord.token", &plaintext); err != nil {
+			s.renderConfig(w, &newConfig, err.Error())
+			return
+		}
 		newConfig.Discord.ChannelID = r.Form.Get("discord_channel_id")
 		// Telegram
 		newConfig.Telegram.Enabled = r.Form.Get("telegram_enabled") == "true"
-		newConfig.Telegram.Token = r.Form.Get("telegram_token")
+		if newConfig.Telegram.Token, err = secretFromForm(r, "telegram_token", config.Koolo.Telegram.Token, "koolo/telegram.token", &plaintext); err != nil {
+			s.renderConfig(w, &newConfig, err.Error())
+			return
+		}
 		telegramChatId, err := strconv.ParseInt(r.Form.Get("telegram_chat_id"), 10, 64)
 		if err != nil {
-			s.templates.ExecuteTemplate(w, "config.gohtml", ConfigData{KooloCfg: &newConfig, ErrorMessage: "Invalid Telegram Chat ID"})
+			s.renderConfig(w, &newConfig, "Invalid Telegram Chat ID")
 			return
 		}
 		newConfig.Telegram.ChatID = telegramChatId
+		// Fleet
+		newConfig.Fleet.MaxConcurrent, _ = strconv.Atoi(r.Form.Get("fleet_max_concurrent"))
+		newConfig.Fleet.RotateAfter, _ = strconv.Atoi(r.Form.Get("fleet_rotate_after"))
+		newConfig.Fleet.StartDelay, _ = strconv.Atoi(r.Form.Get("fleet_start_delay"))
 
 		err = config.ValidateAndSaveConfig(newConfig)
 		if err != nil {
-			s.templates.ExecuteTemplate(w, "config.gohtml", ConfigData{KooloCfg: &newConfig, ErrorMessage: err.Error()})
+			s.renderConfig(w, &newConfig, err.Error())
+			return
+		}
+		if len(plaintext) > 0 {
+			s.logger.Warn("Secrets saved in plaintext", slog.Any("secrets", plaintext))
+			s.renderConfig(w, config.Koolo, plaintextWarning(plaintext))
 			return
 		}
 
@@ -686,7 +893,82 @@ func (s *HttpServer) config(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.templates.ExecuteTemplate(w, "config.gohtml", ConfigData{KooloCfg: config.Koolo, ErrorMessage: ""})
+	s.renderConfig(w, config.Koolo, "")
+}
+
+func (s *HttpServer) renderConfig(w http.ResponseWriter, cfg *config.KooloCfg, message string) {
+	data := ConfigData{KooloCfg: cfg, ErrorMessage: message, Vault: VaultData{Plaintext: config.PlaintextSecrets()}}
+	if config.Vault != nil {
+		data.Vault.Path = config.Vault.Path()
+		data.Vault.Exists = config.Vault.Exists()
+		data.Vault.Locked = config.Vault.Locked()
+	}
+
+	s.templates.ExecuteTemplate(w, "config.gohtml", data)
+}
+
+// secrets creates, unlocks or locks the vault and moves the plaintext secrets of the config files into it
+func (s *HttpServer) secrets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Redirect(w, r, "/config", http.StatusSeeOther)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.renderConfig(w, config.Koolo, "Error parsing form")
+		return
+	}
+
+	var err error
+	passphrase := r.Form.Get("passphrase")
+	switch r.Form.Get("action") {
+	case "create":
+		if passphrase != r.Form.Get("confirm") {
+			err = errors.New("the passphrases don't match")
+		} else {
+			err = config.CreateVault(passphrase)
+		}
+	case "unlock":
+		err = config.Vault.Unlock([]byte(passphrase))
+	case "lock":
+		config.Vault.Lock()
+	case "move":
+		var moved int
+		moved, err = config.MoveSecretsToVault()
+		s.logger.Info("Secrets moved to the vault", slog.Int("count", moved))
+	default:
+		err = fmt.Errorf("unknown action %q", r.Form.Get("action"))
+	}
+	if err != nil {
+		s.renderConfig(w, config.Koolo, err.Error())
+		return
+	}
+
+	http.Redirect(w, r, "/config", http.StatusSeeOther)
+}
+
+// secretFromForm returns the value to save for a secret field, the forms never show the stored secrets so an empty
+// field keeps the current value unless its clear box ({field}_clear) is checked. The name is added to plaintext when
+// the secret is saved as it is because there is no vault.
+func secretFromForm(r *http.Request, field, current, name string, plaintext *[]string) (string, error) {
+	if r.Form.Has(field + "_clear") {
+		return "", nil
+	}
+	submitted := r.Form.Get(field)
+	if submitted == "" {
+		return current, nil
+	}
+
+	stored, isPlaintext, err := config.StoreSecret(name, submitted)
+	if isPlaintext {
+		*plaintext = append(*plaintext, name)
+	}
+
+	return stored, err
+}
+
+// plaintextWarning is shown after saving secrets without a vault, they are written as they are in the config files
+func plaintextWarning(plaintext []string) string {
+	return fmt.Sprintf("Settings saved, but the secrets %s are stored in plaintext because there is no secrets vault. Create one in the Koolo settings or use a reference like ${ENV_VAR}.", strings.Join(plaintext, ", "))
 }
 
 func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
@@ -702,7 +984,14 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		}
 
 		supervisorName := r.Form.Get("name")
-		cfg, found := config.Characters[supervisorName]
+		cfg, found := config.Characters()[supervisorName]
+		if invalid, isInvalid := config.InvalidCharacters()[supervisorName]; !found && isInvalid {
+			// Saving the fixed values of an invalid config, the template ones are used when it can't be read
+			cfg, found = invalid.Config, true
+			if cfg == nil {
+				cfg = config.Characters()["template"]
+			}
+		}
 		if !found {
 			err = config.CreateFromTemplate(supervisorName)
 			if err != nil {
@@ -713,7 +1002,7 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 
 				return
 			}
-			cfg = config.Characters["template"]
+			cfg = config.Characters()["template"]
 		}
 
 		cfg.MaxGameLength, _ = strconv.Atoi(r.Form.Get("maxGameLength"))
@@ -723,13 +1012,26 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.ClassicMode = r.Form.Has("classic_mode")
 		cfg.CloseMiniPanel = r.Form.Has("close_mini_panel")
 		cfg.HidePortraits = r.Form.Has("hide_portraits")
+		cfg.Profiles = make([]string, 0)
+		for _, p := range strings.Split(r.Form.Get("profiles"), ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				cfg.Profiles = append(cfg.Profiles, p)
+			}
+		}
 
 		// Bnet config
 		cfg.Username = r.Form.Get("username")
-		cfg.Password = r.Form.Get("password")
 		cfg.Realm = r.Form.Get("realm")
 		cfg.AuthMethod = r.Form.Get("authmethod")
-		cfg.AuthToken = r.Form.Get("AuthToken")
+		plaintext := make([]string, 0)
+		if cfg.Password, err = secretFromForm(r, "password", cfg.Password, supervisorName+"/password", &plaintext); err != nil {
+			s.renderCharacterSettings(w, supervisorName, cfg, err)
+			return
+		}
+		if cfg.AuthToken, err = secretFromForm(r, "AuthToken", cfg.AuthToken, supervisorName+"/authToken", &plaintext); err != nil {
+			s.renderCharacterSettings(w, supervisorName, cfg, err)
+			return
+		}
 
 		// Scheduler config
 		cfg.Scheduler.Enabled = r.Form.Has("schedulerEnabled")
@@ -742,6 +1044,11 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 			cfg.Scheduler.Days[day].DayOfWeek = day
 			cfg.Scheduler.Days[day].TimeRanges = make([]config.TimeRange, 0)
 
+			for len(cfg.Scheduler.DailyBudget) <= day {
+				cfg.Scheduler.DailyBudget = append(cfg.Scheduler.DailyBudget, 0)
+			}
+			cfg.Scheduler.DailyBudget[day], _ = strconv.Atoi(r.Form.Get(fmt.Sprintf("scheduler[%d][budget]", day)))
+
 			for i := 0; i < len(starts); i++ {
 				start, err := time.Parse("15:04", starts[i])
 				if err != nil {
@@ -770,6 +1077,13 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		cfg.Scheduler.Priority, _ = strconv.Atoi(r.Form.Get("schedulerPriority"))
+		cfg.Scheduler.Jitter, _ = strconv.Atoi(r.Form.Get("schedulerJitter"))
+		cfg.Scheduler.Breaks.EveryMin, _ = strconv.Atoi(r.Form.Get("schedulerBreakEveryMin"))
+		cfg.Scheduler.Breaks.EveryMax, _ = strconv.Atoi(r.Form.Get("schedulerBreakEveryMax"))
+		cfg.Scheduler.Breaks.LengthMin, _ = strconv.Atoi(r.Form.Get("schedulerBreakLengthMin"))
+		cfg.Scheduler.Breaks.LengthMax, _ = strconv.Atoi(r.Form.Get("schedulerBreakLengthMax"))
+
 		// Validate scheduler data
 		err := validateSchedulerData(cfg)
 		if err != nil {
@@ -786,6 +1100,10 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.Health.RejuvPotionAtLife, _ = strconv.Atoi(r.Form.Get("rejuvPotionAtLife"))
 		cfg.Health.RejuvPotionAtMana, _ = strconv.Atoi(r.Form.Get("rejuvPotionAtMana"))
 		cfg.Health.ChickenAt, _ = strconv.Atoi(r.Form.Get("chickenAt"))
+		cfg.Health.DamageRate.Enabled = r.Form.Has("damageRateEnabled")
+		cfg.Health.DamageRate.Window, _ = strconv.Atoi(r.Form.Get("damageRateWindow"))
+		cfg.Health.DamageRate.ChickenHorizon, _ = strconv.Atoi(r.Form.Get("damageRateChickenHorizon"))
+		cfg.Health.DamageRate.RejuvHorizon, _ = strconv.Atoi(r.Form.Get("damageRateRejuvHorizon"))
 		cfg.Character.UseMerc = r.Form.Has("useMerc")
 		cfg.Health.MercHealingPotionAt, _ = strconv.Atoi(r.Form.Get("mercHealingPotionAt"))
 		cfg.Health.MercRejuvPotionAt, _ = strconv.Atoi(r.Form.Get("mercRejuvPotionAt"))
@@ -829,6 +1147,11 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		// Custom build options
+		if cfg.Character.Class == "custom" {
+			cfg.Character.BuildFile = r.Form.Get("characterBuildFile")
+		}
+
 		// Mosaic specific options
 		if cfg.Character.Class == "mosaic" {
 			cfg.Character.MosaicSin.UseTigerStrike = r.Form.Has("mosaicUseTigerStrike")
@@ -869,6 +1192,11 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 
 		cfg.Game.Cows.OpenChests = r.Form.Has("gameCowsOpenChests")
 
+		// Shopping
+		cfg.Game.Shopping.Vendors = r.Form["gameShoppingVendors[]"]
+		cfg.Game.Shopping.GoldFloor, _ = strconv.Atoi(r.Form.Get("gameShoppingGoldFloor"))
+		cfg.Game.Shopping.Refreshes, _ = strconv.Atoi(r.Form.Get("gameShoppingRefreshes"))
+
 		cfg.Game.Pit.MoveThroughBlackMarsh = r.Form.Has("gamePitMoveThroughBlackMarsh")
 		cfg.Game.Pit.OpenChests = r.Form.Has("gamePitOpenChests")
 		cfg.Game.Pit.FocusOnElitePacks = r.Form.Has("gamePitFocusOnElitePacks")
@@ -961,6 +1289,10 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 
 		// Gambling
 		cfg.Gambling.Enabled = r.Form.Has("gamblingEnabled")
+		cfg.Gambling.StartGold, _ = strconv.Atoi(r.Form.Get("gamblingStartGold"))
+		cfg.Gambling.GoldFloor, _ = strconv.Atoi(r.Form.Get("gamblingGoldFloor"))
+		cfg.Gambling.Budget, _ = strconv.Atoi(r.Form.Get("gamblingBudget"))
+		cfg.Gambling.TargetHits, _ = strconv.Atoi(r.Form.Get("gamblingTargetHits"))
 
 		// Cube Recipes
 		cfg.CubeRecipes.Enabled = r.Form.Has("enableCubeRecipes")
@@ -968,6 +1300,7 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.CubeRecipes.EnabledRecipes = enabledRecipes
 		cfg.CubeRecipes.SkipPerfectAmethysts = r.Form.Has("skipPerfectAmethysts")
 		cfg.CubeRecipes.SkipPerfectRubies = r.Form.Has("skipPerfectRubies")
+		cfg.CubeRecipes.GoldFloor, _ = strconv.Atoi(r.Form.Get("cubingGoldFloor"))
 		// Companion
 
 		// Companion config
@@ -982,17 +1315,41 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		cfg.BackToTown.MercDied = r.Form.Has("mercDied")
 		cfg.BackToTown.EquipmentBroken = r.Form.Has("equipmentBroken")
 
-		config.SaveSupervisorConfig(supervisorName, cfg)
+		if err = config.SaveSupervisorConfig(supervisorName, cfg); err != nil {
+			// Show the submitted values along with the invalid fields to fix them
+			s.renderCharacterSettings(w, supervisorName, cfg, err)
+			return
+		}
+		if len(plaintext) > 0 {
+			s.logger.Warn("Secrets saved in plaintext", slog.String("supervisor", supervisorName), slog.Any("secrets", plaintext))
+			if saved, found := config.Characters()[supervisorName]; found {
+				cfg = saved
+			}
+			s.renderCharacterSettings(w, supervisorName, cfg, errors.New(plaintextWarning(plaintext)))
+			return
+		}
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 
 	supervisor := r.URL.Query().Get("supervisor")
-	cfg := config.Characters["template"]
+	cfg := config.Characters()["template"]
 	if supervisor != "" {
-		cfg = config.Characters[supervisor]
+		if invalid, found := config.InvalidCharacters()[supervisor]; found {
+			// Invalid configs are shown with their errors to fix them, the template is used when it can't be read
+			if invalid.Config != nil {
+				cfg = invalid.Config
+			}
+			s.renderCharacterSettings(w, supervisor, cfg, invalid.Err)
+			return
+		}
+		cfg = config.Characters()[supervisor]
 	}
 
+	s.renderCharacterSettings(w, supervisor, cfg, nil)
+}
+
+func (s *HttpServer) renderCharacterSettings(w http.ResponseWriter, supervisor string, cfg *config.CharacterCfg, err error) {
 	enabledRuns := make([]string, 0)
 	// Let's iterate cfg.Game.Runs to preserve current order
 	for _, run := range cfg.Game.Runs {
@@ -1019,10 +1376,13 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 			cfg.Scheduler.Days[i] = config.Day{DayOfWeek: i}
 		}
 	}
+	for len(cfg.Scheduler.DailyBudget) < 7 {
+		cfg.Scheduler.DailyBudget = append(cfg.Scheduler.DailyBudget, 0)
+	}
 
 	dayNames := []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
 
-	s.templates.ExecuteTemplate(w, "character_settings.gohtml", CharacterSettings{
+	settings := CharacterSettings{
 		Supervisor:   supervisor,
 		Config:       cfg,
 		DayNames:     dayNames,
@@ -1030,5 +1390,18 @@ func (s *HttpServer) characterSettings(w http.ResponseWriter, r *http.Request) {
 		DisabledRuns: disabledRuns,
 		AvailableTZs: availableTZs,
 		RecipeList:   config.AvailableRecipes,
-	})
+	}
+	// Only the errors of the shown config are listed by field, other characters may fail when reloading
+	var fieldErrors validation.Errors
+	if errors.As(err, &fieldErrors) {
+		settings.ErrorMessage = "Invalid settings, nothing was saved"
+		if _, submitted := err.(validation.Errors); !submitted {
+			settings.ErrorMessage = "Invalid settings, the character can't be started until they are fixed"
+		}
+		settings.FieldErrors = fieldErrors
+	} else if err != nil {
+		settings.ErrorMessage = err.Error()
+	}
+
+	s.templates.ExecuteTemplate(w, "character_settings.gohtml", settings)
 }
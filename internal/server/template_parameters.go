@@ -3,7 +3,10 @@ package server
 import (
 	"github.com/hectorgimenez/d2go/pkg/data"
 	"github.com/hectorgimenez/koolo/internal/bot"
+	"github.com/hectorgimenez/koolo/internal/combat"
 	"github.com/hectorgimenez/koolo/internal/config"
+	"github.com/hectorgimenez/koolo/internal/config/validation"
+	"github.com/hectorgimenez/koolo/internal/schedule"
 )
 
 type IndexData struct {
@@ -11,6 +14,9 @@ type IndexData struct {
 	Version      string
 	Status       map[string]bot.Stats
 	DropCount    map[string]int
+	Schedule     map[string]schedule.Transition
+	// ConfigErrors are the characters that can't be started because their config failed to load
+	ConfigErrors map[string]string
 }
 
 type DropData struct {
@@ -19,8 +25,20 @@ type DropData struct {
 	Drops         []data.Drop
 }
 
+type AttackData struct {
+	Character string
+	Total     []combat.Entry
+	Runs      []AttackRunData
+}
+
+type AttackRunData struct {
+	Name    string
+	Entries []combat.Entry
+}
+
 type CharacterSettings struct {
 	ErrorMessage string
+	FieldErrors  validation.Errors
 	Supervisor   string
 	Config       *config.CharacterCfg
 	DayNames     []string
@@ -30,9 +48,26 @@ type CharacterSettings struct {
 	RecipeList   []string
 }
 
+type ProfilesData struct {
+	ErrorMessage string
+	Name         string
+	Content      string
+	// UsedBy are the characters inheriting directly from each profile
+	UsedBy map[string][]string
+}
+
 type ConfigData struct {
 	ErrorMessage string
 	*config.KooloCfg
+	Vault VaultData
+}
+
+type VaultData struct {
+	Path   string
+	Exists bool
+	Locked bool
+	// Plaintext are the secret config values not moved to the vault or an environment variable yet
+	Plaintext []string
 }
 
 type AutoSettings struct {
@@ -0,0 +1,125 @@
+package target
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+	"github.com/hectorgimenez/d2go/pkg/utils"
+)
+
+// Order defines which monster is attacked first
+type Order string
+
+const (
+	// OrderNone keeps the order returned by the game
+	OrderNone        Order = ""
+	OrderClosest     Order = "closest"
+	OrderElitesFirst Order = "elites_first"
+	OrderLowestHP    Order = "lowest_hp"
+)
+
+// Policy selects the next monster to attack when clearing an area
+type Policy struct {
+	Order Order `yaml:"order"`
+	// RaisersFirst kills the monsters able to resurrect or spawn other monsters (Fallen Shamans, Greater Mummies)
+	// before anything else
+	RaisersFirst bool `yaml:"raisersFirst"`
+	// ElitesOnly ignores the monsters not belonging to an elite pack
+	ElitesOnly bool `yaml:"elitesOnly"`
+	// SkipImmunities ignores the monsters immune to any of these resists
+	SkipImmunities []stat.Resist `yaml:"skipImmunities"`
+}
+
+// Config contains the default policy and the policies overridden per run name
+type Config struct {
+	Default Policy            `yaml:"default"`
+	Runs    map[string]Policy `yaml:"runs"`
+}
+
+// For returns the policy used by the given run
+func (c Config) For(run string) Policy {
+	if p, found := c.Runs[run]; found {
+		return p
+	}
+
+	return c.Default
+}
+
+// IsRaiser returns true if the monster can resurrect or spawn other monsters
+func IsRaiser(m data.Monster) bool {
+	if m.IsMonsterRaiser() {
+		return true
+	}
+
+	switch m.Name {
+	case npc.Unraveler, npc.Unraveler2, npc.HoradrimAncient, npc.HoradrimAncient2, npc.HoradrimAncient3, npc.HollowOne, npc.Guardian, npc.BaalSubjectMummy:
+		return true
+	}
+
+	return false
+}
+
+// Filter returns the monster filter applying ElitesOnly and SkipImmunities
+func (p Policy) Filter() data.MonsterFilter {
+	return func(monsters data.Monsters) []data.Monster {
+		filtered := make([]data.Monster, 0, len(monsters))
+		for _, m := range monsters {
+			if p.ElitesOnly && !m.IsElite() {
+				continue
+			}
+			if slices.ContainsFunc(p.SkipImmunities, m.IsImmune) {
+				continue
+			}
+			filtered = append(filtered, m)
+		}
+
+		return filtered
+	}
+}
+
+// Sort returns the monsters sorted by priority, from is the position used to calculate distances
+func (p Policy) Sort(monsters []data.Monster, from data.Position) []data.Monster {
+	sorted := slices.Clone(monsters)
+	distance := func(m data.Monster) int {
+		return utils.DistanceFromPoint(from, m.Position)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if p.RaisersFirst && IsRaiser(a) != IsRaiser(b) {
+			return IsRaiser(a)
+		}
+
+		switch p.Order {
+		case OrderClosest:
+			return distance(a) < distance(b)
+		case OrderElitesFirst:
+			if a.IsElite() != b.IsElite() {
+				return a.IsElite()
+			}
+			return distance(a) < distance(b)
+		case OrderLowestHP:
+			if a.Stats[stat.Life] != b.Stats[stat.Life] {
+				return a.Stats[stat.Life] < b.Stats[stat.Life]
+			}
+			return distance(a) < distance(b)
+		}
+
+		return false
+	})
+
+	return sorted
+}
+
+// Select returns the monster to attack first
+func (p Policy) Select(monsters []data.Monster, from data.Position) (data.Monster, bool) {
+	candidates := p.Sort(p.Filter()(monsters), from)
+	if len(candidates) == 0 {
+		return data.Monster{}, false
+	}
+
+	return candidates[0], true
+}
@@ -0,0 +1,69 @@
+package target
+
+import (
+	"testing"
+
+	"github.com/hectorgimenez/d2go/pkg/data"
+	"github.com/hectorgimenez/d2go/pkg/data/npc"
+	"github.com/hectorgimenez/d2go/pkg/data/stat"
+)
+
+func monster(id data.UnitID, name npc.ID, t data.MonsterType, x, life int, stats ...stat.ID) data.Monster {
+	s := map[stat.ID]int{stat.Life: life}
+	for _, st := range stats {
+		s[st] = 100
+	}
+
+	return data.Monster{UnitID: id, Name: name, Type: t, Position: data.Position{X: x, Y: 0}, Stats: s}
+}
+
+func TestSelect(t *testing.T) {
+	monsters := []data.Monster{
+		monster(1, npc.Fallen, data.MonsterTypeNone, 20, 50),
+		monster(2, npc.Fallen, data.MonsterTypeChampion, 30, 300, stat.ColdResist),
+		monster(3, npc.FallenShaman, data.MonsterTypeNone, 40, 80),
+		monster(4, npc.Fallen, data.MonsterTypeNone, 10, 100),
+		monster(5, npc.Unraveler, data.MonsterTypeNone, 50, 500),
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		expected data.UnitID
+		found    bool
+	}{
+		{name: "game order", policy: Policy{}, expected: 1, found: true},
+		{name: "closest", policy: Policy{Order: OrderClosest}, expected: 4, found: true},
+		{name: "elites first", policy: Policy{Order: OrderElitesFirst}, expected: 2, found: true},
+		{name: "lowest hp", policy: Policy{Order: OrderLowestHP}, expected: 1, found: true},
+		{name: "raisers first", policy: Policy{Order: OrderClosest, RaisersFirst: true}, expected: 3, found: true},
+		{name: "skip immunities", policy: Policy{Order: OrderElitesFirst, SkipImmunities: []stat.Resist{stat.ColdImmune}}, expected: 4, found: true},
+		{name: "elites only", policy: Policy{ElitesOnly: true}, expected: 2, found: true},
+		{name: "nothing left", policy: Policy{ElitesOnly: true, SkipImmunities: []stat.Resist{stat.ColdImmune}}, found: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, found := tc.policy.Select(monsters, data.Position{X: 0, Y: 0})
+			if found != tc.found {
+				t.Fatalf("expected found %t, got %t", tc.found, found)
+			}
+			if found && m.UnitID != tc.expected {
+				t.Errorf("expected %d, got %d", tc.expected, m.UnitID)
+			}
+		})
+	}
+}
+
+func TestIsRaiser(t *testing.T) {
+	if !IsRaiser(data.Monster{Name: npc.FallenShaman}) || !IsRaiser(data.Monster{Name: npc.HoradrimAncient}) || IsRaiser(data.Monster{Name: npc.Fallen}) {
+		t.Error("unexpected raiser detection")
+	}
+}
+
+func TestConfigFor(t *testing.T) {
+	cfg := Config{Default: Policy{Order: OrderClosest}, Runs: map[string]Policy{"pit": {Order: OrderElitesFirst}}}
+	if cfg.For("pit").Order != OrderElitesFirst || cfg.For("cows").Order != OrderClosest {
+		t.Error("unexpected policy per run")
+	}
+}
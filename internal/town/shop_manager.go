@@ -8,6 +8,7 @@ import (
 	"github.com/hectorgimenez/d2go/pkg/data/item"
 	"github.com/hectorgimenez/d2go/pkg/data/stat"
 	"github.com/hectorgimenez/d2go/pkg/nip"
+	"github.com/hectorgimenez/koolo/internal/belt"
 	"github.com/hectorgimenez/koolo/internal/context"
 	"github.com/hectorgimenez/koolo/internal/game"
 	"github.com/hectorgimenez/koolo/internal/ui"
@@ -16,26 +17,29 @@ import (
 func BuyConsumables(forceRefill bool) {
 	ctx := context.Get()
 
-	missingHealingPots := ctx.BeltManager.GetMissingCount(data.HealingPotion)
-	missingManaPots := ctx.BeltManager.GetMissingCount(data.ManaPotion)
+	missingHealingPots := ctx.BeltManager.GetBuyCount(data.HealingPotion)
+	missingManaPots := ctx.BeltManager.GetBuyCount(data.ManaPotion)
 
 	ctx.Logger.Debug(fmt.Sprintf("Buying: %d Healing potions and %d Mana potions", missingHealingPots, missingManaPots))
 
-	// We traverse the items in reverse order because vendor has the best potions at the end
-	pot, found := findFirstMatch("superhealingpotion", "greaterhealingpotion", "healingpotion", "lighthealingpotion", "minorhealingpotion")
-	if found && missingHealingPots > 0 {
-		BuyItem(pot, missingHealingPots)
-		missingHealingPots = 0
+	available := make([]item.Name, 0)
+	for _, itm := range ctx.Data.Inventory.ByLocation(item.LocationVendor) {
+		available = append(available, itm.Name)
 	}
 
-	pot, found = findFirstMatch("supermanapotion", "greatermanapotion", "manapotion", "lightmanapotion", "minormanapotion")
-	// In Normal greater potions are expensive as we are low level, let's keep with cheap ones
-	if ctx.CharacterCfg.Game.Difficulty == "normal" {
-		pot, found = findFirstMatch("manapotion", "lightmanapotion", "minormanapotion")
+	if missingHealingPots > 0 {
+		if name, found := belt.BestAffordable(data.HealingPotion, ctx.CharacterCfg.Game.Difficulty, available, missingHealingPots, ctx.Data.PlayerUnit.TotalPlayerGold()); found {
+			pot, _ := ctx.Data.Inventory.Find(name, item.LocationVendor)
+			BuyItem(pot, missingHealingPots)
+		}
 	}
-	if found && missingManaPots > 0 {
-		BuyItem(pot, missingManaPots)
-		missingManaPots = 0
+
+	if missingManaPots > 0 {
+		ctx.RefreshGameData()
+		if name, found := belt.BestAffordable(data.ManaPotion, ctx.CharacterCfg.Game.Difficulty, available, missingManaPots, ctx.Data.PlayerUnit.TotalPlayerGold()); found {
+			pot, _ := ctx.Data.Inventory.Find(name, item.LocationVendor)
+			BuyItem(pot, missingManaPots)
+		}
 	}
 
 	if ShouldBuyTPs() || forceRefill {
@@ -77,17 +81,6 @@ func BuyConsumables(forceRefill bool) {
 	}
 }
 
-func findFirstMatch(itemNames ...string) (data.Item, bool) {
-	ctx := context.Get()
-	for _, name := range itemNames {
-		if itm, found := ctx.Data.Inventory.Find(item.Name(name), item.LocationVendor); found {
-			return itm, true
-		}
-	}
-
-	return data.Item{}, false
-}
-
 func ShouldBuyTPs() bool {
 	portalTome, found := context.Get().Data.Inventory.Find(item.TomeOfTownPortal, item.LocationInventory)
 	if !found {
@@ -181,6 +174,10 @@ func ItemsToBeSold() (items []data.Item) {
 			if _, result := ctx.Data.CharacterCfg.Runtime.Rules.EvaluateAll(itm); result == nip.RuleResultFullMatch && !itm.IsPotion() {
 				continue
 			}
+			// Same for items bought by the shopping run
+			if _, result := ctx.Data.CharacterCfg.Runtime.ShoppingRules.EvaluateAll(itm); result == nip.RuleResultFullMatch {
+				continue
+			}
 			items = append(items, itm)
 		}
 	}